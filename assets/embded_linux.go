@@ -18,3 +18,12 @@ func GetTrayIcon() []byte {
 func GetTemplateTrayIcon() []byte {
 	return nil
 }
+
+// GetTrayIconPng returns the tray icon in PNG format to draw icon variants on
+func GetTrayIconPng() []byte {
+	data, err := IconFS.ReadFile("generated/tray_icon.png")
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
@@ -23,3 +23,12 @@ func GetTemplateTrayIcon() []byte {
 	}
 	return data
 }
+
+// GetTrayIconPng returns the tray icon in PNG format to draw icon variants on
+func GetTrayIconPng() []byte {
+	data, err := IconFS.ReadFile("generated/tray_icon.png")
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
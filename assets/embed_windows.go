@@ -5,6 +5,7 @@ package assets
 import "embed"
 
 //go:embed generated/tray_icon.ico
+//go:embed generated/tray_icon.png
 var IconFS embed.FS
 
 func GetTrayIcon() []byte {
@@ -18,3 +19,12 @@ func GetTrayIcon() []byte {
 func GetTemplateTrayIcon() []byte {
 	return nil
 }
+
+// GetTrayIconPng returns the tray icon in PNG format to draw icon variants on
+func GetTrayIconPng() []byte {
+	data, err := IconFS.ReadFile("generated/tray_icon.png")
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
@@ -3,11 +3,26 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/cardinalby/vlc-sync-play/internal/cli"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == cli.CtlCommand {
+		if err := cli.RunCtl(context.Background(), os.Args[2:], os.Stdout); err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == cli.ReplayCommand {
+		if err := cli.RunReplay(os.Args[2:], os.Stdout); err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
 	if err := cli.RunCliApp(context.Background()); err != nil {
 		fmt.Printf(err.Error())
 	}
@@ -23,6 +23,15 @@ func runTrayAgentApp() error {
 	if err != nil {
 		return fmt.Errorf("error parsing command line args: %w", err)
 	}
+	if !cmdLineArgs.NewSession {
+		lock, isForwarded, err := app.LockOrForward(context.Background(), cmdLineArgs.FilePaths)
+		if err != nil || isForwarded {
+			return err
+		}
+		defer func() {
+			_ = lock.Release()
+		}()
+	}
 	var logger logging.Logger
 	if cmdLineArgs.Debug {
 		logger = logging.NewLogger(os.Stdout)
@@ -8,6 +8,9 @@ require (
 	fyne.io/systray v1.10.1-0.20240111184411-11c585fff98d
 	github.com/cardinalby/go-struct-flags v1.1.0
 	github.com/gammazero/deque v0.2.1
+	github.com/gdamore/tcell/v2 v2.7.4
+	github.com/godbus/dbus/v5 v5.1.0
+	github.com/gorilla/websocket v1.5.0
 	github.com/kirsle/configdir v0.0.0-20170128060238-e45d2f54772f
 	github.com/rivo/tview v0.0.0-20240225120200-5605142ca62e
 	github.com/stretchr/testify v1.9.0
@@ -19,8 +22,6 @@ require (
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/gdamore/encoding v1.0.0 // indirect
-	github.com/gdamore/tcell/v2 v2.7.4 // indirect
-	github.com/godbus/dbus/v5 v5.1.0 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
 	github.com/mattn/go-runewidth v0.0.15 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
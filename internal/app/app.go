@@ -6,8 +6,11 @@ import (
 	"fmt"
 
 	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
+	typeutil "github.com/cardinalby/vlc-sync-play/pkg/util/type"
 	"github.com/cardinalby/vlc-sync-play/pkg/vlc/instance"
 	"github.com/cardinalby/vlc-sync-play/pkg/vlc/instance/vlc_path"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/recording"
 	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
 	"golang.org/x/sync/errgroup"
 )
@@ -16,9 +19,14 @@ const Name = "vlc-sync-play"
 
 var ErrSettingsPatchIsInvalid = errors.New("settings patch is invalid")
 
+// Service runs alongside the syncer until ctx is done. Ctx is cancelled when the syncer finishes
+type Service func(ctx context.Context, settings *Settings, playersSyncer *syncer.Syncer) error
+
 type App struct {
 	logger          logging.Logger
 	settingsStorage *SettingsStorage
+	history         *History
+	services        []Service
 }
 
 func NewApp(
@@ -33,7 +41,27 @@ func (a *App) Init(settingsPatch SettingsPatch) (settings *Settings, err error)
 	if a.settingsStorage, err = a.createSettingsStorage(settingsPatch); err != nil {
 		return nil, err
 	}
-	return a.settingsStorage.GetSettings(), nil
+	a.history = NewHistory(a.logger.WithPrefix("history"))
+	if err := a.history.Load(); err != nil {
+		a.logger.Err("error loading history: %s", err.Error())
+	}
+	settings = a.settingsStorage.GetSettings()
+	if settings.Resume {
+		if _, err := a.history.GetLast(); err != nil {
+			return nil, fmt.Errorf("can't resume: %w", err)
+		}
+	}
+	return settings, nil
+}
+
+// GetHistory returns recently opened files. Available after Init
+func (a *App) GetHistory() *History {
+	return a.history
+}
+
+// AddService adds a service to run with the syncer. Should be called before Start
+func (a *App) AddService(service Service) {
+	a.services = append(a.services, service)
 }
 
 func (a *App) Start(ctx context.Context) (err error) {
@@ -42,24 +70,50 @@ func (a *App) Start(ctx context.Context) (err error) {
 	}
 	settings := a.settingsStorage.GetSettings()
 
-	instanceLauncher := instance.NewLauncher(settings.VlcPath, settings.ApiProtocol, a.logger)
+	instanceLauncher := instance.NewLauncher(
+		[]instance.Backend{
+			instance.NewVlcBackend(settings.VlcPath, settings.ApiProtocol),
+			instance.NewMpvBackend(settings.MpvPath),
+		},
+		a.logger,
+	)
 
 	var filePath string
-	if len(settings.FilePaths) > 0 {
+	var resumeEntry typeutil.Optional[HistoryEntry]
+	if settings.Resume {
+		// players are started without a file, the session file is opened once the first one is launched
+		if entry, err := a.history.GetLast(); err == nil {
+			resumeEntry.Set(entry)
+		}
+	} else if len(settings.FilePaths) > 0 {
 		filePath = settings.FilePaths[0]
 	}
 
 	playersSyncer := syncer.NewSyncer(
 		settings,
 		instanceLauncher,
+		timeutil.RealClock,
 		a.logger,
 	)
+	if settings.RecordPath != "" {
+		recorder, err := recording.CreateFileRecorder(settings.RecordPath, a.logger.WithPrefix("recording"))
+		if err != nil {
+			return fmt.Errorf("error creating the recording: %w", err)
+		}
+		defer func() {
+			_ = recorder.Close()
+		}()
+		playersSyncer.SetRecorder(recorder)
+		defer playersSyncer.SubscribeEvents(recorder.RecordEvent).Unsubscribe()
+	}
 
 	settingsSyncCtx, settingsSyncCtxCancel := context.WithCancel(ctx)
 	errGroup, ctx := errgroup.WithContext(ctx)
+	servicesCtx, servicesCtxCancel := context.WithCancel(ctx)
 
 	errGroup.Go(func() error {
 		defer settingsSyncCtxCancel()
+		defer servicesCtxCancel()
 		err := playersSyncer.Start(ctx, filePath)
 		if errors.Is(err, ctx.Err()) || errors.Is(err, syncer.ErrAllInstancesFinished) {
 			return nil
@@ -69,6 +123,36 @@ func (a *App) Start(ctx context.Context) (err error) {
 	errGroup.Go(func() error {
 		return a.settingsStorage.StartSyncing(settingsSyncCtx)
 	})
+	errGroup.Go(func() error {
+		return a.history.Run(servicesCtx, settings, playersSyncer)
+	})
+	if resumeEntry.HasValue {
+		errGroup.Go(func() error {
+			return restoreSessionAtStart(servicesCtx, playersSyncer, resumeEntry.Value.Session)
+		})
+	}
+	errGroup.Go(func() error {
+		return runControlSocket(servicesCtx, settings, playersSyncer, a.logger.WithPrefix("ctl"))
+	})
+	errGroup.Go(func() error {
+		return runMpris(servicesCtx, playersSyncer, a.logger.WithPrefix("mpris"))
+	})
+	if settings.Peer.IsEnabled() {
+		errGroup.Go(func() error {
+			return runPeerNode(servicesCtx, settings.Peer, playersSyncer, a.logger)
+		})
+	}
+	if settings.HttpApi.Address != "" {
+		errGroup.Go(func() error {
+			return NewHttpApi(settings, playersSyncer, a.logger).Run(servicesCtx, settings.HttpApi)
+		})
+	}
+	for _, service := range a.services {
+		service := service
+		errGroup.Go(func() error {
+			return service(servicesCtx, settings, playersSyncer)
+		})
+	}
 	err = errGroup.Wait()
 	if err != nil {
 		a.logger.Err("syncer error: %s", err.Error())
@@ -76,6 +160,30 @@ func (a *App) Start(ctx context.Context) (err error) {
 	return err
 }
 
+// restoreSessionAtStart restores the session once the first player is launched
+func restoreSessionAtStart(ctx context.Context, playersSyncer *syncer.Syncer, session syncer.Session) error {
+	launched := make(chan struct{}, 1)
+	defer playersSyncer.SubscribeEvents(func(event syncer.Event) {
+		if event.Type == syncer.EventTypeInstanceLaunched {
+			select {
+			case launched <- struct{}{}:
+			default:
+			}
+		}
+	}).Unsubscribe()
+
+	for {
+		if err := playersSyncer.RestoreSession(ctx, session); !errors.Is(err, syncer.ErrNoPlayers) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-launched:
+		}
+	}
+}
+
 func (a *App) createSettingsStorage(settingsPatch SettingsPatch) (*SettingsStorage, error) {
 	settings := NewSettings()
 	settings.SetDefaults()
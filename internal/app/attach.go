@@ -0,0 +1,23 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/instance"
+)
+
+// ParseAttachTargets parses players to attach to in "password@host:port;host:port;mpv:/path/to/socket" format
+func ParseAttachTargets(str string) ([]instance.AttachTarget, error) {
+	var res []instance.AttachTarget
+	for _, targetStr := range strings.Split(str, instancesSeparator) {
+		if targetStr = strings.TrimSpace(targetStr); targetStr == "" {
+			continue
+		}
+		target, err := instance.ParseAttachTarget(targetStr)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, target)
+	}
+	return res, nil
+}
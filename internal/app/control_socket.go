@@ -0,0 +1,244 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
+	"github.com/kirsle/configdir"
+)
+
+const (
+	controlSocketFileName = "ctl.sock"
+	controlConnTimeout    = 10 * time.Second
+)
+
+// Control socket commands
+const (
+	ControlCmdPause     = "pause"
+	ControlCmdResume    = "resume"
+	ControlCmdSeek      = "seek"
+	ControlCmdOpen      = "open"
+	ControlCmdInstances = "instances"
+	ControlCmdStatus    = "status"
+)
+
+var ErrAppIsNotRunning = errors.New("app is not running")
+
+// ControlRequest is sent to the control socket of the running app
+type ControlRequest struct {
+	Command string `json:"command"`
+	// Arg is time for "seek" (e.g. "1:23:45", "-10s"), path for "open", number for "instances"
+	Arg string `json:"arg,omitempty"`
+}
+
+type ControlResponse struct {
+	Error string `json:"error,omitempty"`
+	// Result is the list of players for "status" command
+	Result json.RawMessage `json:"result,omitempty"`
+}
+
+// GetControlSocketPath returns the path of the per-user Unix domain socket the running app listens on
+func GetControlSocketPath() string {
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		return filepath.Join(runtimeDir, Name+".sock")
+	}
+	return filepath.Join(configdir.LocalConfig(Name), controlSocketFileName)
+}
+
+// SendControlRequest sends the request to the running app
+func SendControlRequest(ctx context.Context, request ControlRequest) (ControlResponse, error) {
+	var response ControlResponse
+	dialer := net.Dialer{Timeout: controlConnTimeout}
+	conn, err := dialer.DialContext(ctx, "unix", GetControlSocketPath())
+	if err != nil {
+		return response, fmt.Errorf("%w: %s", ErrAppIsNotRunning, err.Error())
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	_ = conn.SetDeadline(time.Now().Add(controlConnTimeout))
+	if err := json.NewEncoder(conn).Encode(request); err != nil {
+		return response, err
+	}
+	if err := json.NewDecoder(conn).Decode(&response); err != nil {
+		return response, fmt.Errorf("error reading response: %w", err)
+	}
+	return response, nil
+}
+
+type controlSocket struct {
+	settings *Settings
+	syncer   *syncer.Syncer
+	logger   logging.Logger
+}
+
+// runControlSocket executes commands received on the control socket until ctx is done
+func runControlSocket(ctx context.Context, settings *Settings, playersSyncer *syncer.Syncer, logger logging.Logger) error {
+	listener, err := listenControlSocket(GetControlSocketPath())
+	if err != nil {
+		logger.Err("control socket is disabled: %s", err.Error())
+		return nil
+	}
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+
+	cs := &controlSocket{
+		settings: settings,
+		syncer:   playersSyncer,
+		logger:   logger,
+	}
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go cs.serve(ctx, conn)
+	}
+}
+
+func listenControlSocket(socketPath string) (net.Listener, error) {
+	if conn, err := net.DialTimeout("unix", socketPath, controlConnTimeout); err == nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("%s is used by another running app", socketPath)
+	}
+	// left by the crashed app
+	_ = os.Remove(socketPath)
+	if err := configdir.MakePath(filepath.Dir(socketPath)); err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+func (cs *controlSocket) serve(ctx context.Context, conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+	_ = conn.SetDeadline(time.Now().Add(controlConnTimeout))
+
+	var request ControlRequest
+	if err := json.NewDecoder(conn).Decode(&request); err != nil {
+		cs.logger.Err("error reading control request: %s", err.Error())
+		return
+	}
+	cs.logger.Info("control request: %s %s", request.Command, request.Arg)
+	var response ControlResponse
+	result, err := cs.execute(ctx, request)
+	if err != nil {
+		response.Error = err.Error()
+	} else if result != nil {
+		if response.Result, err = json.Marshal(result); err != nil {
+			response.Error = err.Error()
+		}
+	}
+	if err := json.NewEncoder(conn).Encode(response); err != nil {
+		cs.logger.Err("error writing control response: %s", err.Error())
+	}
+}
+
+func (cs *controlSocket) execute(ctx context.Context, request ControlRequest) (result any, err error) {
+	switch request.Command {
+	case ControlCmdPause, ControlCmdResume:
+		commands := extended.CmdGroup{}
+		if request.Command == ControlCmdPause {
+			commands.State.Set(basic.PlaybackStatePaused)
+		} else {
+			commands.State.Set(basic.PlaybackStatePlaying)
+		}
+		cs.syncer.SendCommands(ctx, commands)
+		return nil, nil
+	case ControlCmdSeek:
+		pbTime, isRelative, err := ParseSeekTime(request.Arg)
+		if err != nil {
+			return nil, err
+		}
+		if isRelative {
+			return nil, cs.syncer.SeekBy(ctx, pbTime)
+		}
+		return nil, cs.syncer.SeekTo(ctx, pbTime)
+	case ControlCmdOpen:
+		if request.Arg == "" {
+			return nil, errors.New("file path is required")
+		}
+		return nil, cs.syncer.OpenFile(ctx, request.Arg)
+	case ControlCmdInstances:
+		return nil, cs.setInstancesNumber(request.Arg)
+	case ControlCmdStatus:
+		return toHttpApiPlayers(cs.syncer.GetPlayers()), nil
+	}
+	return nil, fmt.Errorf("unknown command '%s'", request.Command)
+}
+
+// setInstancesNumber launches new players or stops the players in the last slots
+func (cs *controlSocket) setInstancesNumber(arg string) error {
+	instancesNumber, err := strconv.Atoi(arg)
+	if err != nil {
+		return fmt.Errorf("invalid instances number: %w", err)
+	}
+	if instancesNumber < MinInstancesNumber || instancesNumber > MaxInstancesNumber {
+		return fmt.Errorf("instances number should be in [%d, %d]", MinInstancesNumber, MaxInstancesNumber)
+	}
+	cs.settings.InstancesNumber.SetValue(instancesNumber)
+	players := cs.syncer.GetPlayers()
+	for i := instancesNumber; i < len(players); i++ {
+		if err := cs.syncer.StopPlayer(players[i].ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseSeekTime parses absolute time in "[[h:]m:]s" format or relative time with sign: "+10s", "-1m30s", "-90"
+func ParseSeekTime(str string) (pbTime time.Duration, isRelative bool, err error) {
+	str = strings.TrimSpace(str)
+	sign := time.Duration(1)
+	if strings.HasPrefix(str, "+") || strings.HasPrefix(str, "-") {
+		isRelative = true
+		if str[0] == '-' {
+			sign = -1
+		}
+		str = str[1:]
+	}
+	if str == "" {
+		return 0, false, errors.New("time is required")
+	}
+	if duration, err := time.ParseDuration(str); err == nil && !strings.Contains(str, ":") {
+		return sign * duration, isRelative, nil
+	}
+	parts := strings.Split(str, ":")
+	if len(parts) > 3 {
+		return 0, false, fmt.Errorf("invalid time '%s'", str)
+	}
+	for _, part := range parts {
+		value, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid time '%s'", str)
+		}
+		pbTime = pbTime*60 + time.Duration(value)*time.Second
+	}
+	return sign * pbTime, isRelative, nil
+}
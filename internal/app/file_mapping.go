@@ -0,0 +1,125 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/filemap"
+	"github.com/cardinalby/vlc-sync-play/pkg/util/arr"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
+	"golang.org/x/exp/slices"
+)
+
+// ParseFileSuffixes parses per-instance sibling file suffixes in "eng;rus" format
+func ParseFileSuffixes(str string) []string {
+	return arr.Map(strings.Split(str, instancesSeparator), strings.TrimSpace)
+}
+
+// FormatFileSuffixes formats per-instance file suffixes in the format accepted by ParseFileSuffixes
+func FormatFileSuffixes(instancesSettings []syncer.InstanceSettings) string {
+	return strings.Join(
+		arr.Map(instancesSettings, func(instanceSettings syncer.InstanceSettings) string {
+			return instanceSettings.FileSuffix
+		}),
+		instancesSeparator+" ",
+	)
+}
+
+// SetFileSuffixes sets file suffixes for each slot from the list, clearing the other slots
+func (s *Settings) SetFileSuffixes(suffixes []string) {
+	instancesSettings := slices.Clone(s.InstancesSettings.GetValue())
+	for len(instancesSettings) < len(suffixes) {
+		instancesSettings = append(instancesSettings, syncer.InstanceSettings{})
+	}
+	for slot := range instancesSettings {
+		if slot < len(suffixes) {
+			instancesSettings[slot].FileSuffix = suffixes[slot]
+		} else {
+			instancesSettings[slot].FileSuffix = ""
+		}
+	}
+	s.InstancesSettings.SetValue(instancesSettings)
+}
+
+// ParseTimeTransforms parses per-instance time transforms in "0;-5000*1.0427" format:
+// an offset in ms optionally followed by "*" and a scale
+func ParseTimeTransforms(str string) ([]filemap.TimeTransform, error) {
+	var res []filemap.TimeTransform
+	for _, instanceStr := range strings.Split(str, instancesSeparator) {
+		instanceStr = strings.TrimSpace(instanceStr)
+		if instanceStr == "" {
+			res = append(res, filemap.TimeTransform{})
+			continue
+		}
+		offsetStr, scaleStr, hasScale := strings.Cut(instanceStr, "*")
+		var transform filemap.TimeTransform
+		if offsetStr = strings.TrimSpace(offsetStr); offsetStr != "" {
+			offsetMs, err := strconv.ParseInt(offsetStr, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid time offset '%s': %w", offsetStr, err)
+			}
+			transform.Offset = time.Duration(offsetMs) * time.Millisecond
+		}
+		if hasScale {
+			scale, err := strconv.ParseFloat(strings.TrimSpace(scaleStr), 64)
+			if err != nil || scale <= 0 {
+				return nil, fmt.Errorf("invalid time scale '%s'", scaleStr)
+			}
+			transform.Scale = scale
+		}
+		res = append(res, transform)
+	}
+	return res, nil
+}
+
+// FormatTimeTransforms formats per-instance time transforms in the format accepted by ParseTimeTransforms
+func FormatTimeTransforms(instancesSettings []syncer.InstanceSettings) string {
+	return strings.Join(
+		arr.Map(instancesSettings, func(instanceSettings syncer.InstanceSettings) string {
+			transform := instanceSettings.TimeTransform
+			if transform.IsIdentity() {
+				return ""
+			}
+			str := strconv.FormatInt(transform.Offset.Milliseconds(), 10)
+			if transform.Scale != 0 && transform.Scale != 1 {
+				str += "*" + strconv.FormatFloat(transform.Scale, 'f', -1, 64)
+			}
+			return str
+		}),
+		instancesSeparator+" ",
+	)
+}
+
+// SetTimeTransforms sets time transforms for each slot from the list, clearing the other slots
+func (s *Settings) SetTimeTransforms(transforms []filemap.TimeTransform) {
+	instancesSettings := slices.Clone(s.InstancesSettings.GetValue())
+	for len(instancesSettings) < len(transforms) {
+		instancesSettings = append(instancesSettings, syncer.InstanceSettings{})
+	}
+	for slot := range instancesSettings {
+		if slot < len(transforms) {
+			instancesSettings[slot].TimeTransform = transforms[slot]
+		} else {
+			instancesSettings[slot].TimeTransform = filemap.TimeTransform{}
+		}
+	}
+	s.InstancesSettings.SetValue(instancesSettings)
+}
+
+// getCmdLineFileSet returns a set of files passed in the command line to open in the corresponding slots
+func (s *Settings) getCmdLineFileSet() (filemap.FileSet, bool) {
+	if len(s.FilePaths) < 2 {
+		return filemap.FileSet{}, false
+	}
+	return filemap.FileSet{
+		Files: arr.Map(s.FilePaths, func(path string) filemap.File {
+			if absPath, err := filepath.Abs(path); err == nil {
+				path = absPath
+			}
+			return filemap.File{Path: path}
+		}),
+	}, true
+}
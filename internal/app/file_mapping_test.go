@@ -0,0 +1,49 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/filemap"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimeTransforms(t *testing.T) {
+	t.Parallel()
+
+	transforms, err := ParseTimeTransforms("0; -5000*1.0427 ;; *2; 300")
+	require.NoError(t, err)
+	require.Equal(t, []filemap.TimeTransform{
+		{},
+		{Offset: -5 * time.Second, Scale: 1.0427},
+		{},
+		{Scale: 2},
+		{Offset: 300 * time.Millisecond},
+	}, transforms)
+
+	for _, str := range []string{"1.5", "abc", "100*", "100*0", "100*-1", "0;100*x"} {
+		_, err := ParseTimeTransforms(str)
+		require.Error(t, err, str)
+	}
+}
+
+func TestFormatTimeTransforms(t *testing.T) {
+	t.Parallel()
+
+	str := FormatTimeTransforms([]syncer.InstanceSettings{
+		{},
+		{TimeTransform: filemap.TimeTransform{Offset: -5 * time.Second, Scale: 1.0427}},
+		{TimeTransform: filemap.TimeTransform{Offset: 300 * time.Millisecond}},
+	})
+	require.Equal(t, "; -5000*1.0427; 300", str)
+	transforms, err := ParseTimeTransforms(str)
+	require.NoError(t, err)
+	require.Equal(t, filemap.TimeTransform{Offset: -5 * time.Second, Scale: 1.0427}, transforms[1])
+}
+
+func TestParseFileSuffixes(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []string{"eng", "", "rus"}, ParseFileSuffixes(" eng;; rus "))
+}
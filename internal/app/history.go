@@ -0,0 +1,218 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	urlutil "github.com/cardinalby/vlc-sync-play/pkg/url"
+	"github.com/cardinalby/vlc-sync-play/pkg/util/arr"
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/util/rx"
+	typeutil "github.com/cardinalby/vlc-sync-play/pkg/util/type"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
+	"github.com/kirsle/configdir"
+)
+
+const (
+	historyFileName = "history.json"
+	// HistoryMaxEntries is the number of recent files kept in the history
+	HistoryMaxEntries = 10
+	// historyRecordInterval is how often the playback time of the opened file is recorded besides syncer events
+	historyRecordInterval = 5 * time.Second
+)
+
+var ErrHistoryIsEmpty = errors.New("history is empty")
+
+// HistoryEntry is a session of a recently opened file
+type HistoryEntry struct {
+	Session   syncer.Session
+	UpdatedAt time.Time
+}
+
+// GetTitle returns the file name of the session or its URI if it's not a local file
+func (e HistoryEntry) GetTitle() string {
+	if path, ok := urlutil.ToFilePath(e.Session.FileURI); ok {
+		return filepath.Base(path)
+	}
+	return e.Session.FileURI
+}
+
+type jsonHistoryEntry struct {
+	FileURI   string              `json:"file-uri"`
+	FileSlot  int                 `json:"file-slot,omitempty"`
+	PbTimeMs  int64               `json:"pb-time-ms"`
+	Players   []jsonPlayerSession `json:"players,omitempty"`
+	UpdatedAt time.Time           `json:"updated-at"`
+}
+
+type jsonPlayerSession struct {
+	Slot          int    `json:"slot"`
+	AudioTrack    *int   `json:"audio-track,omitempty"`
+	SubtitleTrack *int   `json:"subtitle-track,omitempty"`
+	SubtitleFile  string `json:"subtitle-file,omitempty"`
+	AudioDelayMs  int64  `json:"audio-delay-ms,omitempty"`
+}
+
+func toJsonHistoryEntry(entry HistoryEntry) jsonHistoryEntry {
+	return jsonHistoryEntry{
+		FileURI:  entry.Session.FileURI,
+		FileSlot: entry.Session.FileSlot,
+		PbTimeMs: entry.Session.PbTime.Milliseconds(),
+		Players: arr.Map(entry.Session.Players, func(playerSession syncer.PlayerSession) jsonPlayerSession {
+			return jsonPlayerSession{
+				Slot:          playerSession.Slot,
+				AudioTrack:    playerSession.AudioTrack.Ptr(),
+				SubtitleTrack: playerSession.SubtitleTrack.Ptr(),
+				SubtitleFile:  playerSession.SubtitleFile,
+				AudioDelayMs:  playerSession.AudioDelay.Milliseconds(),
+			}
+		}),
+		UpdatedAt: entry.UpdatedAt,
+	}
+}
+
+func fromJsonHistoryEntry(entry jsonHistoryEntry) HistoryEntry {
+	return HistoryEntry{
+		Session: syncer.Session{
+			FileURI:  entry.FileURI,
+			FileSlot: entry.FileSlot,
+			PbTime:   time.Duration(entry.PbTimeMs) * time.Millisecond,
+			Players: arr.Map(entry.Players, func(playerSession jsonPlayerSession) syncer.PlayerSession {
+				res := syncer.PlayerSession{
+					Slot:         playerSession.Slot,
+					SubtitleFile: playerSession.SubtitleFile,
+					AudioDelay:   time.Duration(playerSession.AudioDelayMs) * time.Millisecond,
+				}
+				if playerSession.AudioTrack != nil {
+					res.AudioTrack.Set(*playerSession.AudioTrack)
+				}
+				if playerSession.SubtitleTrack != nil {
+					res.SubtitleTrack.Set(*playerSession.SubtitleTrack)
+				}
+				return res
+			}),
+		},
+		UpdatedAt: entry.UpdatedAt,
+	}
+}
+
+// History stores sessions of recently opened files next to the settings file
+type History struct {
+	mu       sync.Mutex
+	filePath string
+	// Entries are ordered from the most recent one
+	Entries rx.Value[[]HistoryEntry]
+	logger  logging.Logger
+}
+
+func NewHistory(logger logging.Logger) *History {
+	return &History{
+		filePath: filepath.Join(configdir.LocalConfig(Name), historyFileName),
+		Entries:  rx.NewValue[[]HistoryEntry](nil),
+		logger:   logger,
+	}
+}
+
+func (h *History) Load() (err error) {
+	fh, err := os.Open(h.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, fh.Close())
+	}()
+
+	var jsonEntries []jsonHistoryEntry
+	if err := json.NewDecoder(fh).Decode(&jsonEntries); err != nil {
+		return err
+	}
+	h.Entries.SetValue(arr.Map(jsonEntries, fromJsonHistoryEntry))
+	return nil
+}
+
+// GetLast returns the most recent entry
+func (h *History) GetLast() (HistoryEntry, error) {
+	entries := h.Entries.GetValue()
+	if len(entries) == 0 {
+		return HistoryEntry{}, ErrHistoryIsEmpty
+	}
+	return entries[0], nil
+}
+
+// Record puts the session to the top of the history replacing the entry with the same file
+func (h *History) Record(session syncer.Session) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entries := []HistoryEntry{{Session: session, UpdatedAt: time.Now()}}
+	for _, entry := range h.Entries.GetValue() {
+		if len(entries) == HistoryMaxEntries {
+			break
+		}
+		if !urlutil.EqualIgnoreSchema(entry.Session.FileURI, session.FileURI) {
+			entries = append(entries, entry)
+		}
+	}
+	h.Entries.SetValue(entries)
+	return h.save(entries)
+}
+
+// Run records the session of the opened file until ctx is done
+func (h *History) Run(ctx context.Context, _ *Settings, playersSyncer *syncer.Syncer) error {
+	changed := make(chan struct{}, 1)
+	defer playersSyncer.SubscribeEvents(func(event syncer.Event) {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}).Unsubscribe()
+
+	ticker := time.NewTicker(historyRecordInterval)
+	defer ticker.Stop()
+
+	var lastRecorded typeutil.Optional[syncer.Session]
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		case <-ticker.C:
+		}
+		session, ok := playersSyncer.GetSession()
+		if !ok {
+			continue
+		}
+		session.PbTime = session.PbTime.Truncate(time.Second)
+		if lastRecorded.HasValue && reflect.DeepEqual(lastRecorded.Value, session) {
+			continue
+		}
+		if err := h.Record(session); err != nil {
+			h.logger.Err("error saving history: %s", err.Error())
+		}
+		lastRecorded.Set(session)
+	}
+}
+
+func (h *History) save(entries []HistoryEntry) (err error) {
+	if err := configdir.MakePath(filepath.Dir(h.filePath)); err != nil {
+		return fmt.Errorf("error creating config dir: %w", err)
+	}
+	f, err := os.OpenFile(h.filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening file: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, f.Close())
+	}()
+	return json.NewEncoder(f).Encode(arr.Map(entries, toJsonHistoryEntry))
+}
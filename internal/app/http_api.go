@@ -0,0 +1,492 @@
+package app
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	httpApiShutdownTimeout     = 3 * time.Second
+	httpApiEventsQueueSize     = 64
+	httpApiEventsWriteTimeout  = 5 * time.Second
+	httpApiMaxRequestBodySize  = 64 * 1024
+	httpApiPlayersPathPrefix   = "/api/players/"
+	httpApiVolumePathSuffix    = "/volume"
+	httpApiMaxVolume           = 2
+	httpApiTokenQueryParameter = "token"
+	httpApiContentType         = "application/json"
+)
+
+var errHttpApiContentType = fmt.Errorf("request body should be %s", httpApiContentType)
+
+// HttpApiSettings configure the HTTP API controlling the app
+type HttpApiSettings struct {
+	// Address to listen on, e.g. "127.0.0.1:7767". Empty value disables the API
+	Address string
+	// Token is an optional secret expected in "Authorization: Bearer <token>" header or in "token" query parameter
+	Token string
+}
+
+// Validate requires a token if the API is available from other machines
+func (s HttpApiSettings) Validate() error {
+	if s.Address == "" || s.Token != "" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(s.Address)
+	if err != nil {
+		return fmt.Errorf("invalid HTTP API address: %w", err)
+	}
+	if !isLoopbackHost(host) {
+		return errors.New("HTTP API token is required to listen on a non-loopback address")
+	}
+	return nil
+}
+
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// WebUiSettings configure the web remote UI
+type WebUiSettings struct {
+	// Address to listen on. Default address is used if empty
+	Address string
+}
+
+// HttpApi exposes players, settings and commands of the app over HTTP and streams syncer events over WebSocket
+type HttpApi struct {
+	settings *Settings
+	syncer   *syncer.Syncer
+	upgrader websocket.Upgrader
+	logger   logging.Logger
+}
+
+func NewHttpApi(settings *Settings, playersSyncer *syncer.Syncer, logger logging.Logger) *HttpApi {
+	return &HttpApi{
+		settings: settings,
+		syncer:   playersSyncer,
+		logger:   logger,
+	}
+}
+
+// Handler returns the handler serving API requests under "/api/" path. Requests should be authorized
+// by the caller
+func (a *HttpApi) Handler(ctx context.Context) http.Handler {
+	return withSameOrigin(a.handler(ctx))
+}
+
+func (a *HttpApi) handler(ctx context.Context) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/players", a.handlePlayers)
+	mux.HandleFunc(httpApiPlayersPathPrefix, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, httpApiVolumePathSuffix) {
+			a.handlePlayerVolume(ctx, w, r)
+		} else {
+			a.handlePlayer(w, r)
+		}
+	})
+	mux.HandleFunc("/api/settings", a.handleSettings)
+	mux.HandleFunc("/api/leader", a.handleLeader)
+	a.handlePlaybackCommands(ctx, mux)
+	mux.HandleFunc("/api/commands/open", func(w http.ResponseWriter, r *http.Request) {
+		a.handleOpenCommand(ctx, w, r)
+	})
+	mux.HandleFunc("/api/events", func(w http.ResponseWriter, r *http.Request) {
+		a.handleEvents(ctx, w, r)
+	})
+	return mux
+}
+
+// RemoteHandler returns the handler of the API subset used by the web remote: players status, volume,
+// playback commands, the leader and events. Players, files and other settings can't be changed with it
+func (a *HttpApi) RemoteHandler(ctx context.Context) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/players", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeHttpApiMethodNotAllowed(w)
+			return
+		}
+		a.handlePlayers(w, r)
+	})
+	mux.HandleFunc(httpApiPlayersPathPrefix, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, httpApiVolumePathSuffix) {
+			http.NotFound(w, r)
+			return
+		}
+		a.handlePlayerVolume(ctx, w, r)
+	})
+	mux.HandleFunc("/api/leader", a.handleLeader)
+	a.handlePlaybackCommands(ctx, mux)
+	mux.HandleFunc("/api/events", func(w http.ResponseWriter, r *http.Request) {
+		a.handleEvents(ctx, w, r)
+	})
+	return withSameOrigin(mux)
+}
+
+func (a *HttpApi) handlePlaybackCommands(ctx context.Context, mux *http.ServeMux) {
+	mux.HandleFunc("/api/commands/pause", func(w http.ResponseWriter, r *http.Request) {
+		a.handleStateCommand(ctx, w, r, basic.PlaybackStatePaused)
+	})
+	mux.HandleFunc("/api/commands/resume", func(w http.ResponseWriter, r *http.Request) {
+		a.handleStateCommand(ctx, w, r, basic.PlaybackStatePlaying)
+	})
+	mux.HandleFunc("/api/commands/seek", func(w http.ResponseWriter, r *http.Request) {
+		a.handleSeekCommand(ctx, w, r)
+	})
+}
+
+// Run serves the API until ctx is done
+func (a *HttpApi) Run(ctx context.Context, apiSettings HttpApiSettings) error {
+	return serveHttp(ctx, apiSettings.Address, withTokenAuth(apiSettings.Token, a.Handler(ctx)), a.logger)
+}
+
+func serveHttp(ctx context.Context, address string, handler http.Handler, logger logging.Logger) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), httpApiShutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	logger.Info("Serving HTTP on %s", listener.Addr())
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// withSameOrigin rejects requests made by pages of other sites. Requests of non-browser clients don't have
+// the checked headers
+func withSameOrigin(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if site := r.Header.Get("Sec-Fetch-Site"); site != "" && site != "same-origin" && site != "none" {
+			writeHttpApiError(w, http.StatusForbidden, errors.New("cross-site request"))
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if originURL, err := url.Parse(origin); err != nil || !strings.EqualFold(originURL.Host, r.Host) {
+				writeHttpApiError(w, http.StatusForbidden, errors.New("cross-origin request"))
+				return
+			}
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// withTokenAuth requires the token in requests. Without a token the API listens on a loopback address only
+// and requests for other hosts are rejected: a page of a site resolving to a loopback address (DNS rebinding)
+// would be same-origin for withSameOrigin
+func withTokenAuth(token string, handler http.Handler) http.Handler {
+	if token == "" {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host, _, err := net.SplitHostPort(r.Host)
+			if err != nil {
+				host = strings.TrimSuffix(strings.TrimPrefix(r.Host, "["), "]")
+			}
+			if !isLoopbackHost(host) {
+				writeHttpApiError(w, http.StatusForbidden, errors.New("unexpected host"))
+				return
+			}
+			handler.ServeHTTP(w, r)
+		})
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqToken := r.URL.Query().Get(httpApiTokenQueryParameter)
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			reqToken = bearer
+		}
+		if subtle.ConstantTimeCompare([]byte(reqToken), []byte(token)) != 1 {
+			writeHttpApiError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func (a *HttpApi) handlePlayers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeHttpApiJson(w, http.StatusOK, toHttpApiPlayers(a.syncer.GetPlayers()))
+	case http.MethodPost:
+		if err := AddInstance(a.settings); err != nil {
+			writeHttpApiError(w, http.StatusConflict, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		writeHttpApiMethodNotAllowed(w)
+	}
+}
+
+func (a *HttpApi) handlePlayer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeHttpApiMethodNotAllowed(w)
+		return
+	}
+	id, err := parseHttpApiPlayerID(r.URL.Path, "")
+	if err != nil {
+		writeHttpApiError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := StopInstance(a.settings, a.syncer, id); err != nil {
+		if errors.Is(err, syncer.ErrPlayerNotFound) {
+			writeHttpApiError(w, http.StatusNotFound, err)
+		} else {
+			writeHttpApiError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *HttpApi) handlePlayerVolume(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeHttpApiMethodNotAllowed(w)
+		return
+	}
+	id, err := parseHttpApiPlayerID(r.URL.Path, httpApiVolumePathSuffix)
+	if err != nil {
+		writeHttpApiError(w, http.StatusBadRequest, err)
+		return
+	}
+	var req httpApiVolumeRequest
+	if err := decodeHttpApiRequest(r, &req); err != nil {
+		writeHttpApiDecodeError(w, err)
+		return
+	}
+	if req.Volume < 0 || req.Volume > httpApiMaxVolume {
+		writeHttpApiError(w, http.StatusBadRequest, fmt.Errorf("volume should be in [0, %d]", httpApiMaxVolume))
+		return
+	}
+	if err := a.syncer.SetPlayerVolume(ctx, id, req.Volume); err != nil {
+		if errors.Is(err, syncer.ErrPlayerNotFound) {
+			writeHttpApiError(w, http.StatusNotFound, err)
+		} else {
+			writeHttpApiError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *HttpApi) handleSettings(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeHttpApiJson(w, http.StatusOK, toHttpApiSettings(a.settings))
+	case http.MethodPatch:
+		var patch httpApiSettings
+		if err := decodeHttpApiRequest(r, &patch); err != nil {
+			writeHttpApiDecodeError(w, err)
+			return
+		}
+		jsonPatch := patch.toJsonSettings()
+		if err := a.validateSettingsPatch(&jsonPatch); err != nil {
+			writeHttpApiError(w, http.StatusBadRequest, err)
+			return
+		}
+		// only rx.Value settings are patched, they are saved by SettingsStorage subscriptions
+		jsonPatch.applyToAppSettings(a.settings)
+		writeHttpApiJson(w, http.StatusOK, toHttpApiSettings(a.settings))
+	default:
+		writeHttpApiMethodNotAllowed(w)
+	}
+}
+
+func (a *HttpApi) handleLeader(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req httpApiLeader
+		if err := decodeHttpApiRequest(r, &req); err != nil {
+			writeHttpApiDecodeError(w, err)
+			return
+		}
+		a.settings.LeaderID.SetValue(req.LeaderID)
+	default:
+		writeHttpApiMethodNotAllowed(w)
+		return
+	}
+	writeHttpApiJson(w, http.StatusOK, httpApiLeader{LeaderID: a.settings.LeaderID.GetValue()})
+}
+
+// validateSettingsPatch applies the current settings and the patch to a copy of settings and validates it
+func (a *HttpApi) validateSettingsPatch(patch *jsonSettings) error {
+	current := jsonSettings{}
+	current.setFromAppSettings(a.settings)
+	patched := NewSettings()
+	patched.SetDefaults()
+	current.applyToAppSettings(patched)
+	patch.applyToAppSettings(patched)
+	return patched.Validate()
+}
+
+func (a *HttpApi) handleStateCommand(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	playbackState basic.PlaybackState,
+) {
+	if r.Method != http.MethodPost {
+		writeHttpApiMethodNotAllowed(w)
+		return
+	}
+	commands := extended.CmdGroup{}
+	commands.State.Set(playbackState)
+	a.syncer.SendCommands(ctx, commands)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *HttpApi) handleSeekCommand(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeHttpApiMethodNotAllowed(w)
+		return
+	}
+	var req httpApiSeekRequest
+	if err := decodeHttpApiRequest(r, &req); err != nil {
+		writeHttpApiDecodeError(w, err)
+		return
+	}
+	if err := a.syncer.SeekTo(ctx, time.Duration(req.TimeMs)*time.Millisecond); err != nil {
+		writeHttpApiError(w, http.StatusConflict, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *HttpApi) handleOpenCommand(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeHttpApiMethodNotAllowed(w)
+		return
+	}
+	var req httpApiOpenRequest
+	if err := decodeHttpApiRequest(r, &req); err != nil {
+		writeHttpApiDecodeError(w, err)
+		return
+	}
+	if req.File == "" {
+		writeHttpApiError(w, http.StatusBadRequest, errors.New("file is required"))
+		return
+	}
+	if err := a.syncer.OpenFile(ctx, req.File); err != nil {
+		writeHttpApiError(w, http.StatusConflict, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleEvents streams syncer events as JSON messages over WebSocket
+func (a *HttpApi) handleEvents(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	wsConn, err := a.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = wsConn.Close()
+	}()
+
+	events := make(chan syncer.Event, httpApiEventsQueueSize)
+	defer a.syncer.SubscribeEvents(func(event syncer.Event) {
+		select {
+		case events <- event:
+		default:
+			a.logger.Err("Events queue of %s is full, dropping %s event", r.RemoteAddr, event.Type)
+		}
+	}).Unsubscribe()
+
+	closed := make(chan struct{})
+	go func() {
+		// read until the client closes the connection
+		defer close(closed)
+		for {
+			if _, _, err := wsConn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-closed:
+			return
+		case event := <-events:
+			_ = wsConn.SetWriteDeadline(time.Now().Add(httpApiEventsWriteTimeout))
+			if err := wsConn.WriteJSON(toHttpApiEvent(event)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// parseHttpApiPlayerID parses "/api/players/{id}<suffix>" path
+func parseHttpApiPlayerID(path string, suffix string) (uint, error) {
+	idStr := strings.TrimSuffix(strings.TrimPrefix(path, httpApiPlayersPathPrefix), suffix)
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid player id: %w", err)
+	}
+	return uint(id), nil
+}
+
+func decodeHttpApiRequest(r *http.Request, dst any) error {
+	// browsers send cross-site requests with other content types without preflight
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil ||
+		mediaType != httpApiContentType {
+		return errHttpApiContentType
+	}
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, httpApiMaxRequestBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+func writeHttpApiJson(w http.ResponseWriter, statusCode int, value any) {
+	w.Header().Set("Content-Type", httpApiContentType)
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeHttpApiDecodeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errHttpApiContentType) {
+		writeHttpApiError(w, http.StatusUnsupportedMediaType, err)
+	} else {
+		writeHttpApiError(w, http.StatusBadRequest, err)
+	}
+}
+
+func writeHttpApiError(w http.ResponseWriter, statusCode int, err error) {
+	writeHttpApiJson(w, statusCode, httpApiError{Error: err.Error()})
+}
+
+func writeHttpApiMethodNotAllowed(w http.ResponseWriter) {
+	writeHttpApiError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+}
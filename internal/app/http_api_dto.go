@@ -0,0 +1,232 @@
+package app
+
+import (
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/util/arr"
+	typeutil "github.com/cardinalby/vlc-sync-play/pkg/util/type"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/state"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
+)
+
+type httpApiStatus struct {
+	FileURI      string              `json:"file-uri"`
+	State        basic.PlaybackState `json:"state"`
+	Position     float64             `json:"position"`
+	TimeMs       int64               `json:"time-ms"`
+	LengthMs     int64               `json:"length-ms"`
+	Rate         float64             `json:"rate"`
+	AudioDelayMs int64               `json:"audio-delay-ms"`
+	Volume       float64             `json:"volume"`
+	Moment       time.Time           `json:"moment"`
+}
+
+type httpApiUpdate struct {
+	// Changed are names of changed properties: "file", "position", "state", "rate"
+	Changed   []string      `json:"changed"`
+	IsNatural bool          `json:"natural"`
+	Status    httpApiStatus `json:"status"`
+}
+
+type httpApiPlayer struct {
+	ID         uint           `json:"id"`
+	Slot       int            `json:"slot"`
+	PID        int            `json:"pid,omitempty"`
+	IsAttached bool           `json:"attached"`
+	IsLeader   bool           `json:"leader"`
+	Status     *httpApiStatus `json:"status,omitempty"`
+	LastUpdate *httpApiUpdate `json:"last-update,omitempty"`
+	// LeaderOffsetMs is how much the player is ahead of the leader
+	LeaderOffsetMs *int64 `json:"leader-offset-ms,omitempty"`
+	ResponseTimeMs *int64 `json:"response-time-ms,omitempty"`
+}
+
+type httpApiCommands struct {
+	OpenFile *string `json:"open-file,omitempty"`
+	// Position is the expected position of the seek command at the moment of the event
+	Position *float64            `json:"position,omitempty"`
+	Rate     *float64            `json:"rate,omitempty"`
+	State    basic.PlaybackState `json:"state,omitempty"`
+}
+
+type httpApiEvent struct {
+	Type     syncer.EventType `json:"type"`
+	Time     time.Time        `json:"time"`
+	PlayerID uint             `json:"player-id"`
+	Update   *httpApiUpdate   `json:"update,omitempty"`
+	Commands *httpApiCommands `json:"commands,omitempty"`
+	// Origin is "player", "external" or "peer" for synced events
+	Origin syncer.CommandsOrigin `json:"origin,omitempty"`
+	Reason string                `json:"reason,omitempty"`
+}
+
+// httpApiSettings are the settings that can be changed while the app is running (rx.Value ones).
+// Others are applied at start only and contain secrets
+type httpApiSettings struct {
+	InstancesNumber   *int                   `json:"instances,omitempty"`
+	NoVideo           *bool                  `json:"no-video,omitempty"`
+	PollingIntervalMs *int64                 `json:"interval,omitempty"`
+	ClickPause        *bool                  `json:"click-pause,omitempty"`
+	ReSeekSrc         *bool                  `json:"re-seek-src,omitempty"`
+	DriftCorrection   *bool                  `json:"drift-correction,omitempty"`
+	DriftSeekThreshMs *int64                 `json:"drift-seek-threshold,omitempty"`
+	PositionEstimator *string                `json:"position-estimator,omitempty"`
+	LeaderID          *uint                  `json:"leader,omitempty"`
+	InstancesSettings []jsonInstanceSettings `json:"instances-settings,omitempty"`
+	FileSets          [][]jsonFile           `json:"file-sets,omitempty"`
+}
+
+func toHttpApiSettings(settings *Settings) httpApiSettings {
+	s := jsonSettings{}
+	s.setFromAppSettings(settings)
+	return httpApiSettings{
+		InstancesNumber:   s.InstancesNumber,
+		NoVideo:           s.NoVideo,
+		PollingIntervalMs: s.PollingIntervalMs,
+		ClickPause:        s.ClickPause,
+		ReSeekSrc:         s.ReSeekSrc,
+		DriftCorrection:   s.DriftCorrection,
+		DriftSeekThreshMs: s.DriftSeekThreshMs,
+		PositionEstimator: s.PositionEstimator,
+		LeaderID:          s.LeaderID,
+		InstancesSettings: s.InstancesSettings,
+		FileSets:          s.FileSets,
+	}
+}
+
+func (s *httpApiSettings) toJsonSettings() jsonSettings {
+	return jsonSettings{
+		InstancesNumber:   s.InstancesNumber,
+		NoVideo:           s.NoVideo,
+		PollingIntervalMs: s.PollingIntervalMs,
+		ClickPause:        s.ClickPause,
+		ReSeekSrc:         s.ReSeekSrc,
+		DriftCorrection:   s.DriftCorrection,
+		DriftSeekThreshMs: s.DriftSeekThreshMs,
+		PositionEstimator: s.PositionEstimator,
+		LeaderID:          s.LeaderID,
+		InstancesSettings: s.InstancesSettings,
+		FileSets:          s.FileSets,
+	}
+}
+
+type httpApiLeader struct {
+	// LeaderID is 0 if there is no leader
+	LeaderID uint `json:"leader"`
+}
+
+type httpApiSeekRequest struct {
+	TimeMs int64 `json:"time-ms"`
+}
+
+type httpApiVolumeRequest struct {
+	// Volume is 1 for 100%
+	Volume float64 `json:"volume"`
+}
+
+type httpApiOpenRequest struct {
+	File string `json:"file"`
+}
+
+type httpApiError struct {
+	Error string `json:"error"`
+}
+
+func toHttpApiStatus(status basic.StatusEx) *httpApiStatus {
+	return &httpApiStatus{
+		FileURI:      status.FileURI,
+		State:        status.State,
+		Position:     status.Position,
+		TimeMs:       status.GetPbTime().Milliseconds(),
+		LengthMs:     status.GetLength().Milliseconds(),
+		Rate:         status.Rate,
+		AudioDelayMs: status.AudioDelay.Milliseconds(),
+		Volume:       status.Volume,
+		Moment:       status.Moment.Center(),
+	}
+}
+
+func toHttpApiUpdate(update state.Update) *httpApiUpdate {
+	res := &httpApiUpdate{
+		Changed:   []string{},
+		IsNatural: update.IsNatural,
+		Status:    *toHttpApiStatus(update.Status),
+	}
+	props := update.ChangedProps
+	for _, prop := range []struct {
+		name      string
+		isChanged bool
+	}{
+		{"file", props.HasFileURI()},
+		{"position", props.HasPosition()},
+		{"state", props.HasState()},
+		{"rate", props.HasRate()},
+	} {
+		if prop.isChanged {
+			res.Changed = append(res.Changed, prop.name)
+		}
+	}
+	return res
+}
+
+func toHttpApiPlayers(players []syncer.PlayerInfo) []httpApiPlayer {
+	return arr.Map(players, func(info syncer.PlayerInfo) httpApiPlayer {
+		res := httpApiPlayer{
+			ID:         info.ID,
+			Slot:       info.Slot,
+			PID:        info.PID,
+			IsAttached: info.IsAttached,
+			IsLeader:   info.IsLeader,
+		}
+		if info.Status.HasValue {
+			res.Status = toHttpApiStatus(info.Status.Value)
+		}
+		if info.LastUpdate.HasValue {
+			res.LastUpdate = toHttpApiUpdate(info.LastUpdate.Value)
+		}
+		if info.LeaderOffset.HasValue {
+			res.LeaderOffsetMs = typeutil.Ptr(info.LeaderOffset.Value.Milliseconds())
+		}
+		if info.ResponseTime.HasValue {
+			res.ResponseTimeMs = typeutil.Ptr(info.ResponseTime.Value.Milliseconds())
+		}
+		return res
+	})
+}
+
+func toHttpApiCommands(commands extended.CmdGroup, at time.Time) *httpApiCommands {
+	res := &httpApiCommands{}
+	if commands.OpenFile.HasValue {
+		res.OpenFile = &commands.OpenFile.Value
+	}
+	if commands.Seek.HasValue {
+		position := commands.Seek.Value(at)
+		res.Position = &position
+	}
+	if commands.Rate.HasValue {
+		res.Rate = &commands.Rate.Value
+	}
+	if commands.State.HasValue {
+		res.State = commands.State.Value
+	}
+	return res
+}
+
+func toHttpApiEvent(event syncer.Event) httpApiEvent {
+	res := httpApiEvent{
+		Type:     event.Type,
+		Time:     event.Time,
+		PlayerID: event.PlayerID,
+		Reason:   event.Reason,
+	}
+	if event.Update.HasValue {
+		res.Update = toHttpApiUpdate(event.Update.Value)
+	}
+	if event.Type == syncer.EventTypeSynced {
+		res.Commands = toHttpApiCommands(event.Commands, event.Time)
+		res.Origin = event.Origin
+	}
+	return res
+}
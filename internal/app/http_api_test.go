@@ -0,0 +1,40 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpApiTokenAuth(t *testing.T) {
+	t.Parallel()
+
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	serve := func(token, host, authorization string) int {
+		r := httptest.NewRequest(http.MethodGet, "/api/players", nil)
+		r.Host = host
+		if authorization != "" {
+			r.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		withTokenAuth(token, okHandler).ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// without a token only loopback hosts are accepted
+	for _, host := range []string{"localhost:7767", "LOCALHOST", "127.0.0.1:7767", "[::1]:7767", "[::1]"} {
+		require.Equal(t, http.StatusOK, serve("", host, ""), host)
+	}
+	for _, host := range []string{"attacker.example:7767", "attacker.example", "192.168.1.10:7767", ""} {
+		require.Equal(t, http.StatusForbidden, serve("", host, ""), host)
+	}
+
+	// with a token any host is accepted if the token is correct
+	require.Equal(t, http.StatusOK, serve("secret", "192.168.1.10:7767", "Bearer secret"))
+	require.Equal(t, http.StatusUnauthorized, serve("secret", "192.168.1.10:7767", "Bearer wrong"))
+	require.Equal(t, http.StatusUnauthorized, serve("secret", "localhost:7767", ""))
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
+	"github.com/kirsle/configdir"
+)
+
+const (
+	instanceLockFileName = "instance.lock"
+	// forwardTimeout is how long to wait for the control socket of the just started app
+	forwardTimeout       = 5 * time.Second
+	forwardRetryInterval = 200 * time.Millisecond
+)
+
+var errLockIsTaken = errors.New("lock is taken")
+
+var ErrAppIsAlreadyRunning = errors.New("app is already running, use --new-session flag to start another one")
+
+var ErrCantForwardFileSet = errors.New(
+	"running app can't open a set of files, use --new-session flag to start another one",
+)
+
+// InstanceLock is held by the running app for its lifetime
+type InstanceLock struct {
+	file *os.File
+}
+
+// AcquireInstanceLock returns false if another app holds the lock
+func AcquireInstanceLock() (lock *InstanceLock, ok bool, err error) {
+	lockDir := configdir.LocalConfig(Name)
+	if err := configdir.MakePath(lockDir); err != nil {
+		return nil, false, err
+	}
+	file, err := os.OpenFile(filepath.Join(lockDir, instanceLockFileName), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := lockFile(file); err != nil {
+		_ = file.Close()
+		if errors.Is(err, errLockIsTaken) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return &InstanceLock{file: file}, true, nil
+}
+
+func (l *InstanceLock) Release() error {
+	return l.file.Close()
+}
+
+// LockOrForward acquires the instance lock. If another app is running, the file from filePaths is opened
+// by it and isForwarded is true. Returns ErrAppIsAlreadyRunning if there is no file to forward and
+// ErrCantForwardFileSet if there are several files (they are opened in the corresponding slots by a new app only)
+func LockOrForward(ctx context.Context, filePaths []string) (lock *InstanceLock, isForwarded bool, err error) {
+	lock, ok, err := AcquireInstanceLock()
+	if err != nil || ok {
+		return lock, false, err
+	}
+	if len(filePaths) == 0 {
+		return nil, false, ErrAppIsAlreadyRunning
+	}
+	if err := ForwardToRunningApp(ctx, filePaths); err != nil {
+		return nil, false, err
+	}
+	return nil, true, nil
+}
+
+// ForwardToRunningApp makes the running app open the file in its players
+func ForwardToRunningApp(ctx context.Context, filePaths []string) error {
+	if len(filePaths) == 0 {
+		return nil
+	}
+	if len(filePaths) > 1 {
+		return ErrCantForwardFileSet
+	}
+	absPath, err := filepath.Abs(filePaths[0])
+	if err != nil {
+		return err
+	}
+	request := ControlRequest{
+		Command: ControlCmdOpen,
+		Arg:     absPath,
+	}
+	ctx, cancel := context.WithTimeout(ctx, forwardTimeout)
+	defer cancel()
+	for {
+		response, err := SendControlRequest(ctx, request)
+		if err == nil {
+			if response.Error != "" {
+				return fmt.Errorf("running app can't open the file: %s", response.Error)
+			}
+			return nil
+		}
+		// the running app has been started just now and doesn't listen yet
+		if !errors.Is(err, ErrAppIsNotRunning) || timeutil.SleepCtx(ctx, timeutil.RealClock, forwardRetryInterval) != nil {
+			return err
+		}
+	}
+}
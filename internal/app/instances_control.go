@@ -0,0 +1,31 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
+)
+
+var ErrMaxInstancesNumber = fmt.Errorf("max instances number is %d", MaxInstancesNumber)
+
+// AddInstance increases the instances number, the syncer launches a new player
+func AddInstance(settings *Settings) error {
+	instancesNumber := settings.InstancesNumber.GetValue()
+	if instancesNumber >= MaxInstancesNumber {
+		return ErrMaxInstancesNumber
+	}
+	settings.InstancesNumber.SetValue(instancesNumber + 1)
+	return nil
+}
+
+// StopInstance stops the player and decreases the instances number to prevent launching a replacement
+// when the next file is opened
+func StopInstance(settings *Settings, playersSyncer *syncer.Syncer, id uint) error {
+	if err := playersSyncer.StopPlayer(id); err != nil {
+		return err
+	}
+	if instancesNumber := settings.InstancesNumber.GetValue(); instancesNumber > MinInstancesNumber {
+		settings.InstancesNumber.SetValue(instancesNumber - 1)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/instance"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
+	"golang.org/x/exp/slices"
+)
+
+const instancesSeparator = ";"
+const languagesSeparator = ","
+const subtitlesOff = "off"
+
+var subtitlesFileExtensions = []string{".srt", ".ass", ".ssa", ".sub", ".vtt"}
+
+// UpdateInstanceSettings modifies the settings of the instance slot, adding missing slots if needed
+func (s *Settings) UpdateInstanceSettings(slot int, update func(instanceSettings *syncer.InstanceSettings)) {
+	instancesSettings := slices.Clone(s.InstancesSettings.GetValue())
+	for len(instancesSettings) <= slot {
+		instancesSettings = append(instancesSettings, syncer.InstanceSettings{})
+	}
+	update(&instancesSettings[slot])
+	s.InstancesSettings.SetValue(instancesSettings)
+}
+
+// AudioDelayCalibrationStep is a step of audio delay adjustment in the UI
+const AudioDelayCalibrationStep = 10 * time.Millisecond
+
+// GetAudioDelay returns the audio delay of the instance slot
+func (s *Settings) GetAudioDelay(slot int) time.Duration {
+	return syncer.GetInstanceSettings(s.InstancesSettings.GetValue(), slot).AudioDelay
+}
+
+// SetAudioDelay sets the audio delay of the instance slot. It's applied to the running player immediately
+func (s *Settings) SetAudioDelay(slot int, delay time.Duration) {
+	s.UpdateInstanceSettings(slot, func(instanceSettings *syncer.InstanceSettings) {
+		instanceSettings.AudioDelay = delay
+	})
+}
+
+// ParseSubtitles parses per-instance subtitles choices in "eng,rus;off;/path/to/file.srt" format:
+// instances are separated by ";", each of them is "off", a subtitles file path or a list of languages
+func ParseSubtitles(str string) []syncer.Subtitles {
+	var res []syncer.Subtitles
+	for _, instanceStr := range strings.Split(str, instancesSeparator) {
+		instanceStr = strings.TrimSpace(instanceStr)
+		switch {
+		case strings.EqualFold(instanceStr, subtitlesOff):
+			res = append(res, syncer.Subtitles{Off: true})
+		case slices.Contains(subtitlesFileExtensions, strings.ToLower(filepath.Ext(instanceStr))):
+			res = append(res, syncer.Subtitles{FilePath: instanceStr})
+		default:
+			res = append(res, syncer.Subtitles{Languages: parseLanguages(instanceStr)})
+		}
+	}
+	return res
+}
+
+// FormatSubtitles formats per-instance subtitles in the format accepted by ParseSubtitles
+func FormatSubtitles(instancesSettings []syncer.InstanceSettings) string {
+	instanceStrings := make([]string, 0, len(instancesSettings))
+	for _, instanceSettings := range instancesSettings {
+		subtitles := instanceSettings.Subtitles
+		switch {
+		case subtitles.Off:
+			instanceStrings = append(instanceStrings, subtitlesOff)
+		case subtitles.FilePath != "":
+			instanceStrings = append(instanceStrings, subtitles.FilePath)
+		default:
+			instanceStrings = append(instanceStrings, strings.Join(subtitles.Languages, languagesSeparator))
+		}
+	}
+	return strings.Join(instanceStrings, instancesSeparator+" ")
+}
+
+// SetSubtitles sets subtitles for each slot from the list, clearing the other slots
+func (s *Settings) SetSubtitles(subtitles []syncer.Subtitles) {
+	instancesSettings := slices.Clone(s.InstancesSettings.GetValue())
+	for len(instancesSettings) < len(subtitles) {
+		instancesSettings = append(instancesSettings, syncer.InstanceSettings{})
+	}
+	for slot := range instancesSettings {
+		if slot < len(subtitles) {
+			instancesSettings[slot].Subtitles = subtitles[slot]
+		} else {
+			instancesSettings[slot].Subtitles = syncer.Subtitles{}
+		}
+	}
+	s.InstancesSettings.SetValue(instancesSettings)
+}
+
+// ParseAudioLanguages parses per-instance languages lists in "eng;rus,ukr" format:
+// instances are separated by ";", languages of an instance are separated by ","
+func ParseAudioLanguages(str string) [][]string {
+	var res [][]string
+	for _, instanceStr := range strings.Split(str, instancesSeparator) {
+		res = append(res, parseLanguages(instanceStr))
+	}
+	return res
+}
+
+func parseLanguages(str string) []string {
+	var languages []string
+	for _, language := range strings.Split(str, languagesSeparator) {
+		if language = strings.TrimSpace(language); language != "" {
+			languages = append(languages, language)
+		}
+	}
+	return languages
+}
+
+// FormatAudioLanguages formats per-instance languages in the format accepted by ParseAudioLanguages
+func FormatAudioLanguages(instancesSettings []syncer.InstanceSettings) string {
+	instanceStrings := make([]string, 0, len(instancesSettings))
+	for _, instanceSettings := range instancesSettings {
+		instanceStrings = append(instanceStrings, strings.Join(instanceSettings.AudioLanguages, languagesSeparator))
+	}
+	return strings.Join(instanceStrings, instancesSeparator+" ")
+}
+
+// SetAudioLanguages sets audio languages for each slot from the list, clearing the other slots
+func (s *Settings) SetAudioLanguages(audioLanguages [][]string) {
+	instancesSettings := slices.Clone(s.InstancesSettings.GetValue())
+	for len(instancesSettings) < len(audioLanguages) {
+		instancesSettings = append(instancesSettings, syncer.InstanceSettings{})
+	}
+	for slot := range instancesSettings {
+		if slot < len(audioLanguages) {
+			instancesSettings[slot].AudioLanguages = audioLanguages[slot]
+		} else {
+			instancesSettings[slot].AudioLanguages = nil
+		}
+	}
+	s.InstancesSettings.SetValue(instancesSettings)
+}
+
+// ParsePlayers parses per-instance player types in "vlc;mpv" format. Empty item means VLC
+func ParsePlayers(str string) ([]instance.PlayerType, error) {
+	var res []instance.PlayerType
+	for _, instanceStr := range strings.Split(str, instancesSeparator) {
+		playerType, err := instance.ParsePlayerType(instanceStr)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, playerType)
+	}
+	return res, nil
+}
+
+// FormatPlayers formats per-instance player types in the format accepted by ParsePlayers
+func FormatPlayers(instancesSettings []syncer.InstanceSettings) string {
+	instanceStrings := make([]string, 0, len(instancesSettings))
+	for _, instanceSettings := range instancesSettings {
+		instanceStrings = append(instanceStrings, string(instanceSettings.Player.OrDefault()))
+	}
+	return strings.Join(instanceStrings, instancesSeparator+" ")
+}
+
+// SetPlayers sets player types for each slot from the list, resetting the other slots to VLC
+func (s *Settings) SetPlayers(players []instance.PlayerType) {
+	instancesSettings := slices.Clone(s.InstancesSettings.GetValue())
+	for len(instancesSettings) < len(players) {
+		instancesSettings = append(instancesSettings, syncer.InstanceSettings{})
+	}
+	for slot := range instancesSettings {
+		if slot < len(players) {
+			instancesSettings[slot].Player = players[slot]
+		} else {
+			instancesSettings[slot].Player = ""
+		}
+	}
+	s.InstancesSettings.SetValue(instancesSettings)
+}
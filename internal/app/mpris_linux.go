@@ -0,0 +1,58 @@
+//go:build linux
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/mpris"
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
+	"github.com/godbus/dbus/v5"
+)
+
+// mprisRefreshInterval is how often MPRIS properties are checked besides syncer events
+const mprisRefreshInterval = time.Second
+
+// runMpris publishes players as a single MPRIS player on the session bus so that media keys
+// control all of them. Unavailable session bus is not an error
+func runMpris(ctx context.Context, playersSyncer *syncer.Syncer, logger logging.Logger) error {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		logger.Err("MPRIS is disabled, can't connect to session bus: %s", err.Error())
+		return nil
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	player, err := mpris.Export(ctx, conn, playersSyncer, logger)
+	if err != nil {
+		logger.Err("MPRIS is disabled: %s", err.Error())
+		return nil
+	}
+	defer func() {
+		_ = player.Close()
+	}()
+
+	refresh := make(chan struct{}, 1)
+	defer playersSyncer.SubscribeEvents(func(event syncer.Event) {
+		select {
+		case refresh <- struct{}{}:
+		default:
+		}
+	}).Unsubscribe()
+
+	ticker := time.NewTicker(mprisRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-refresh:
+		case <-ticker.C:
+		}
+		player.Refresh()
+	}
+}
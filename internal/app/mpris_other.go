@@ -0,0 +1,15 @@
+//go:build !linux
+
+package app
+
+import (
+	"context"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
+)
+
+// runMpris does nothing, MPRIS is available only on Linux
+func runMpris(context.Context, *syncer.Syncer, logging.Logger) error {
+	return nil
+}
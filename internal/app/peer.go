@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/peer"
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
+	"golang.org/x/sync/errgroup"
+)
+
+const peerReconnectInterval = 3 * time.Second
+
+// PeerSettings configure syncing with vlc-sync-play running on other machines
+type PeerSettings struct {
+	// ListenAddress to accept connections of other peers on, e.g. ":7766"
+	ListenAddress string
+	// ConnectAddress of the listening peer, e.g. "192.168.1.10:7766"
+	ConnectAddress string
+	// Secret should be the same for all peers
+	Secret string
+}
+
+func (s PeerSettings) IsEnabled() bool {
+	return s.ListenAddress != "" || s.ConnectAddress != ""
+}
+
+func (s PeerSettings) Validate() error {
+	if s.IsEnabled() && s.Secret == "" {
+		return fmt.Errorf("%w to listen for or connect to peers", peer.ErrEmptySecret)
+	}
+	return nil
+}
+
+// runPeerNode sends sync commands of local players to remote peers and applies commands received from them
+func runPeerNode(
+	ctx context.Context,
+	settings PeerSettings,
+	playersSyncer *syncer.Syncer,
+	logger logging.Logger,
+) error {
+	node := peer.NewNode(settings.Secret, func(commands extended.CmdGroup) {
+		playersSyncer.SendPeerCommands(ctx, commands)
+	}, logger)
+	defer playersSyncer.SubscribeEvents(func(event syncer.Event) {
+		// commands of peers are sent to all of them by the sender
+		if event.Type == syncer.EventTypeSynced && event.Origin != syncer.CommandsOriginPeer {
+			node.Broadcast(event.Commands)
+		}
+	}).Unsubscribe()
+
+	errGr, ctx := errgroup.WithContext(ctx)
+	if settings.ListenAddress != "" {
+		errGr.Go(func() error {
+			return node.Listen(ctx, settings.ListenAddress)
+		})
+	}
+	if settings.ConnectAddress != "" {
+		errGr.Go(func() error {
+			node.ConnectWithRetries(ctx, settings.ConnectAddress, peerReconnectInterval)
+			return nil
+		})
+	}
+	return errGr.Wait()
+}
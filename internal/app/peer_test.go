@@ -0,0 +1,17 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerSettingsValidate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, PeerSettings{}.Validate())
+	require.NoError(t, PeerSettings{ListenAddress: ":7766", Secret: "secret"}.Validate())
+	require.ErrorIs(t, PeerSettings{ListenAddress: ":7766"}.Validate(), peer.ErrEmptySecret)
+	require.ErrorIs(t, PeerSettings{ConnectAddress: "192.168.1.10:7766"}.Validate(), peer.ErrEmptySecret)
+}
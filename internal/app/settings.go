@@ -2,11 +2,23 @@ package app
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/cardinalby/vlc-sync-play/internal/app/static_features"
+	"github.com/cardinalby/vlc-sync-play/pkg/filemap"
 	"github.com/cardinalby/vlc-sync-play/pkg/util/rx"
 	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic/protocols"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/instance"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/state"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
+)
+
+const minDriftSeekThreshold = 100 * time.Millisecond
+
+const (
+	MinInstancesNumber = 2
+	MaxInstancesNumber = 4
 )
 
 type SettingsPatch interface {
@@ -14,24 +26,47 @@ type SettingsPatch interface {
 }
 
 type Settings struct {
-	ApiProtocol     protocols.ApiProtocol
-	VlcPath         string
-	FilePaths       []string
-	InstancesNumber rx.Value[int]
-	NoVideo         rx.Value[bool]
-	PollingInterval rx.Value[time.Duration]
-	ClickPause      rx.Value[bool]
-	ReSeekSrc       rx.Value[bool]
+	ApiProtocol protocols.ApiProtocol
+	VlcPath     string
+	// MpvPath is the path of mpv executable for slots with mpv player. Empty means mpv found in PATH
+	MpvPath   string
+	FilePaths []string
+	// Resume makes the app open the most recent file from the history instead of FilePaths at start
+	Resume bool
+	// RecordPath is the file to record the sync session to for debugging. Not recorded if empty
+	RecordPath string
+	// AttachTargets are players started by someone else to attach to at start
+	AttachTargets      []instance.AttachTarget
+	Peer               PeerSettings
+	HttpApi            HttpApiSettings
+	WebUi              WebUiSettings
+	InstancesNumber    rx.Value[int]
+	NoVideo            rx.Value[bool]
+	PollingInterval    rx.Value[time.Duration]
+	ClickPause         rx.Value[bool]
+	ReSeekSrc          rx.Value[bool]
+	DriftCorrection    rx.Value[bool]
+	DriftSeekThreshold rx.Value[time.Duration]
+	PositionEstimator  rx.Value[state.PositionEstimator]
+	LeaderID           rx.Value[uint]
+	InstancesSettings  rx.Value[[]syncer.InstanceSettings]
+	FileSets           rx.Value[[]filemap.FileSet]
 }
 
 func NewSettings() *Settings {
 	return &Settings{
-		ApiProtocol:     protocols.ApiProtocolHttpJson,
-		InstancesNumber: rx.NewValue[int](0),
-		NoVideo:         rx.NewValue[bool](false),
-		PollingInterval: rx.NewValue[time.Duration](0),
-		ClickPause:      rx.NewValue[bool](false),
-		ReSeekSrc:       rx.NewValue[bool](false),
+		ApiProtocol:        protocols.ApiProtocolHttpJson,
+		InstancesNumber:    rx.NewValue[int](0),
+		NoVideo:            rx.NewValue[bool](false),
+		PollingInterval:    rx.NewValue[time.Duration](0),
+		ClickPause:         rx.NewValue[bool](false),
+		ReSeekSrc:          rx.NewValue[bool](false),
+		DriftCorrection:    rx.NewValue[bool](false),
+		DriftSeekThreshold: rx.NewValue[time.Duration](0),
+		PositionEstimator:  rx.NewValue[state.PositionEstimator](state.PositionEstimatorRange),
+		LeaderID:           rx.NewValue[uint](instance.IDNone),
+		InstancesSettings:  rx.NewValue[[]syncer.InstanceSettings](nil),
+		FileSets:           rx.NewValue[[]filemap.FileSet](nil),
 	}
 }
 
@@ -42,6 +77,12 @@ func (s *Settings) SetDefaults() {
 	s.PollingInterval.SetValue(100 * time.Millisecond)
 	s.ClickPause.SetValue(static_features.ClickPause)
 	s.ReSeekSrc.SetValue(true)
+	s.DriftCorrection.SetValue(true)
+	s.DriftSeekThreshold.SetValue(time.Second)
+	s.PositionEstimator.SetValue(state.PositionEstimatorRange)
+	s.LeaderID.SetValue(instance.IDNone)
+	s.InstancesSettings.SetValue(nil)
+	s.FileSets.SetValue(nil)
 }
 
 func (s *Settings) GetPollingInterval() rx.Observable[time.Duration] {
@@ -64,19 +105,75 @@ func (s *Settings) GetReSeekSrc() rx.Observable[bool] {
 	return s.ReSeekSrc
 }
 
+func (s *Settings) GetDriftCorrection() rx.Observable[bool] {
+	return s.DriftCorrection
+}
+
+func (s *Settings) GetDriftSeekThreshold() rx.Observable[time.Duration] {
+	return s.DriftSeekThreshold
+}
+
+func (s *Settings) GetPositionEstimator() rx.Observable[state.PositionEstimator] {
+	return s.PositionEstimator
+}
+
+func (s *Settings) GetLeaderID() rx.Observable[uint] {
+	return s.LeaderID
+}
+
+func (s *Settings) GetInstancesSettings() rx.Observable[[]syncer.InstanceSettings] {
+	return s.InstancesSettings
+}
+
+// GetFileSets returns the file sets from the settings preceded by the files passed in the command line
+func (s *Settings) GetFileSets() rx.Observable[[]filemap.FileSet] {
+	return rx.Map[[]filemap.FileSet](s.FileSets, func(fileSets []filemap.FileSet) []filemap.FileSet {
+		if cmdLineFileSet, ok := s.getCmdLineFileSet(); ok {
+			return append([]filemap.FileSet{cmdLineFileSet}, fileSets...)
+		}
+		return fileSets
+	})
+}
+
+func (s *Settings) GetAttachTargets() []instance.AttachTarget {
+	return s.AttachTargets
+}
+
 func (s *Settings) Validate() error {
 	if err := s.ApiProtocol.Validate(); err != nil {
 		return err
 	}
-	if s.InstancesNumber.GetValue() < 2 {
-		return errors.New("instances number should be at least 2")
+	if s.InstancesNumber.GetValue() < MinInstancesNumber {
+		return fmt.Errorf("instances number should be at least %d", MinInstancesNumber)
 	}
 	if s.PollingInterval.GetValue() < 0 {
 		return errors.New("polling interval should be positive")
 	}
+	if s.DriftSeekThreshold.GetValue() < minDriftSeekThreshold {
+		return fmt.Errorf("drift seek threshold should be at least %s", minDriftSeekThreshold)
+	}
+	if err := s.HttpApi.Validate(); err != nil {
+		return err
+	}
+	if err := s.Peer.Validate(); err != nil {
+		return err
+	}
+	if err := s.PositionEstimator.GetValue().Validate(); err != nil {
+		return err
+	}
 	if //goland:noinspection GoBoolExpressions
 	s.ClickPause.GetValue() && !static_features.ClickPause {
 		return errors.New("click pause is not supported")
 	}
+	for _, instanceSettings := range s.InstancesSettings.GetValue() {
+		if _, err := instance.ParsePlayerType(string(instanceSettings.Player)); err != nil {
+			return err
+		}
+	}
+	for _, target := range s.AttachTargets {
+		if _, err := instance.ParsePlayerType(string(target.Player)); err != nil {
+			return err
+		}
+	}
 	return nil
 }
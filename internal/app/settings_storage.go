@@ -10,20 +10,157 @@ import (
 	"time"
 
 	"github.com/cardinalby/vlc-sync-play/internal/app/static_features"
+	"github.com/cardinalby/vlc-sync-play/pkg/filemap"
+	"github.com/cardinalby/vlc-sync-play/pkg/util/arr"
 	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
 	"github.com/cardinalby/vlc-sync-play/pkg/util/rx"
 	typeutil "github.com/cardinalby/vlc-sync-play/pkg/util/type"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic/httpjson"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic/protocols"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/instance"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/state"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
 	"github.com/kirsle/configdir"
 )
 
 const settingsFileName = "settings.json"
 
 type jsonSettings struct {
-	InstancesNumber   *int   `json:"instances,omitempty"`
-	NoVideo           *bool  `json:"no-video,omitempty"`
-	PollingIntervalMs *int64 `json:"interval,omitempty"`
-	ClickPause        *bool  `json:"click-pause,omitempty"`
-	ReSeekSrc         *bool  `json:"re-seek-src,omitempty"`
+	ApiProtocol       *string                `json:"api,omitempty"`
+	InstancesNumber   *int                   `json:"instances,omitempty"`
+	NoVideo           *bool                  `json:"no-video,omitempty"`
+	PollingIntervalMs *int64                 `json:"interval,omitempty"`
+	ClickPause        *bool                  `json:"click-pause,omitempty"`
+	ReSeekSrc         *bool                  `json:"re-seek-src,omitempty"`
+	DriftCorrection   *bool                  `json:"drift-correction,omitempty"`
+	DriftSeekThreshMs *int64                 `json:"drift-seek-threshold,omitempty"`
+	PositionEstimator *string                `json:"position-estimator,omitempty"`
+	LeaderID          *uint                  `json:"leader,omitempty"`
+	InstancesSettings []jsonInstanceSettings `json:"instances-settings,omitempty"`
+	FileSets          [][]jsonFile           `json:"file-sets,omitempty"`
+	Attach            []jsonConnection       `json:"attach,omitempty"`
+	Peer              *jsonPeer              `json:"peer,omitempty"`
+	HttpApi           *jsonHttpApi           `json:"http-api,omitempty"`
+	WebUi             *jsonWebUi             `json:"web-ui,omitempty"`
+}
+
+type jsonWebUi struct {
+	Address string `json:"address,omitempty"`
+}
+
+type jsonHttpApi struct {
+	Address string `json:"address,omitempty"`
+	Token   string `json:"token,omitempty"`
+}
+
+type jsonPeer struct {
+	Listen  string `json:"listen,omitempty"`
+	Connect string `json:"connect,omitempty"`
+	Secret  string `json:"secret,omitempty"`
+}
+
+type jsonConnection struct {
+	Player   string `json:"player,omitempty"`
+	Host     string `json:"host,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Socket is mpv IPC socket path or VLC RC interface socket path
+	Socket string `json:"socket,omitempty"`
+}
+
+type jsonInstanceSettings struct {
+	AudioLanguages []string       `json:"audio-langs,omitempty"`
+	Subtitles      *jsonSubtitles `json:"subs,omitempty"`
+	AudioDelayMs   int64          `json:"audio-delay-ms,omitempty"`
+	FileSuffix     string         `json:"file-suffix,omitempty"`
+	TimeOffsetMs   int64          `json:"time-offset-ms,omitempty"`
+	TimeScale      float64        `json:"time-scale,omitempty"`
+	Player         string         `json:"player,omitempty"`
+}
+
+type jsonFile struct {
+	Path         string  `json:"path"`
+	TimeOffsetMs int64   `json:"time-offset-ms,omitempty"`
+	TimeScale    float64 `json:"time-scale,omitempty"`
+}
+
+type jsonSubtitles struct {
+	Off       bool     `json:"off,omitempty"`
+	Languages []string `json:"langs,omitempty"`
+	FilePath  string   `json:"file,omitempty"`
+}
+
+func toJsonInstancesSettings(instancesSettings []syncer.InstanceSettings) []jsonInstanceSettings {
+	return arr.Map(instancesSettings, func(instanceSettings syncer.InstanceSettings) jsonInstanceSettings {
+		res := jsonInstanceSettings{
+			AudioLanguages: instanceSettings.AudioLanguages,
+			AudioDelayMs:   instanceSettings.AudioDelay.Milliseconds(),
+			FileSuffix:     instanceSettings.FileSuffix,
+			TimeOffsetMs:   instanceSettings.TimeTransform.Offset.Milliseconds(),
+			TimeScale:      instanceSettings.TimeTransform.Scale,
+			Player:         string(instanceSettings.Player),
+		}
+		if subtitles := instanceSettings.Subtitles; !subtitles.IsEmpty() {
+			res.Subtitles = &jsonSubtitles{
+				Off:       subtitles.Off,
+				Languages: subtitles.Languages,
+				FilePath:  subtitles.FilePath,
+			}
+		}
+		return res
+	})
+}
+
+func fromJsonInstancesSettings(instancesSettings []jsonInstanceSettings) []syncer.InstanceSettings {
+	return arr.Map(instancesSettings, func(instanceSettings jsonInstanceSettings) syncer.InstanceSettings {
+		res := syncer.InstanceSettings{
+			AudioLanguages: instanceSettings.AudioLanguages,
+			AudioDelay:     time.Duration(instanceSettings.AudioDelayMs) * time.Millisecond,
+			FileSuffix:     instanceSettings.FileSuffix,
+			TimeTransform: filemap.TimeTransform{
+				Offset: time.Duration(instanceSettings.TimeOffsetMs) * time.Millisecond,
+				Scale:  instanceSettings.TimeScale,
+			},
+			// validated in Settings.Validate
+			Player: instance.PlayerType(instanceSettings.Player),
+		}
+		if subtitles := instanceSettings.Subtitles; subtitles != nil {
+			res.Subtitles = syncer.Subtitles{
+				Off:       subtitles.Off,
+				Languages: subtitles.Languages,
+				FilePath:  subtitles.FilePath,
+			}
+		}
+		return res
+	})
+}
+
+func toJsonFileSets(fileSets []filemap.FileSet) [][]jsonFile {
+	return arr.Map(fileSets, func(fileSet filemap.FileSet) []jsonFile {
+		return arr.Map(fileSet.Files, func(file filemap.File) jsonFile {
+			return jsonFile{
+				Path:         file.Path,
+				TimeOffsetMs: file.Transform.Offset.Milliseconds(),
+				TimeScale:    file.Transform.Scale,
+			}
+		})
+	})
+}
+
+func fromJsonFileSets(fileSets [][]jsonFile) []filemap.FileSet {
+	return arr.Map(fileSets, func(files []jsonFile) filemap.FileSet {
+		return filemap.FileSet{
+			Files: arr.Map(files, func(file jsonFile) filemap.File {
+				return filemap.File{
+					Path: file.Path,
+					Transform: filemap.TimeTransform{
+						Offset: time.Duration(file.TimeOffsetMs) * time.Millisecond,
+						Scale:  file.TimeScale,
+					},
+				}
+			}),
+		}
+	})
 }
 
 func (s *jsonSettings) applyToAppSettings(settings *Settings) (updated bool) {
@@ -47,10 +184,76 @@ func (s *jsonSettings) applyToAppSettings(settings *Settings) (updated bool) {
 		settings.ReSeekSrc.SetValue(*s.ReSeekSrc)
 		updated = true
 	}
+	if s.DriftCorrection != nil {
+		settings.DriftCorrection.SetValue(*s.DriftCorrection)
+		updated = true
+	}
+	if s.DriftSeekThreshMs != nil {
+		settings.DriftSeekThreshold.SetValue(time.Duration(*s.DriftSeekThreshMs) * time.Millisecond)
+		updated = true
+	}
+	if s.PositionEstimator != nil {
+		// validated in Settings.Validate
+		settings.PositionEstimator.SetValue(state.PositionEstimator(*s.PositionEstimator))
+		updated = true
+	}
+	if s.LeaderID != nil {
+		settings.LeaderID.SetValue(*s.LeaderID)
+		updated = true
+	}
+	if s.ApiProtocol != nil {
+		// validated in Settings.Validate
+		settings.ApiProtocol = protocols.ApiProtocol(*s.ApiProtocol)
+		updated = true
+	}
+	if s.InstancesSettings != nil {
+		settings.InstancesSettings.SetValue(fromJsonInstancesSettings(s.InstancesSettings))
+		updated = true
+	}
+	if s.FileSets != nil {
+		settings.FileSets.SetValue(fromJsonFileSets(s.FileSets))
+		updated = true
+	}
+	if s.Attach != nil {
+		settings.AttachTargets = arr.Map(s.Attach, func(connection jsonConnection) instance.AttachTarget {
+			return instance.AttachTarget{
+				Player: instance.PlayerType(connection.Player),
+				Vlc: httpjson.ConnectionInfo{
+					Host:     connection.Host,
+					Port:     connection.Port,
+					Password: connection.Password,
+				},
+				IpcSocket: connection.Socket,
+			}
+		})
+		updated = true
+	}
+	if s.Peer != nil {
+		settings.Peer = PeerSettings{
+			ListenAddress:  s.Peer.Listen,
+			ConnectAddress: s.Peer.Connect,
+			Secret:         s.Peer.Secret,
+		}
+		updated = true
+	}
+	if s.HttpApi != nil {
+		settings.HttpApi = HttpApiSettings{
+			Address: s.HttpApi.Address,
+			Token:   s.HttpApi.Token,
+		}
+		updated = true
+	}
+	if s.WebUi != nil {
+		settings.WebUi = WebUiSettings{
+			Address: s.WebUi.Address,
+		}
+		updated = true
+	}
 	return updated
 }
 
 func (s *jsonSettings) setFromAppSettings(settings *Settings) {
+	s.ApiProtocol = typeutil.Ptr(string(settings.ApiProtocol))
 	s.InstancesNumber = typeutil.Ptr(settings.InstancesNumber.GetValue())
 	s.NoVideo = typeutil.Ptr(settings.NoVideo.GetValue())
 	s.PollingIntervalMs = typeutil.Ptr(settings.PollingInterval.GetValue().Milliseconds())
@@ -58,6 +261,39 @@ func (s *jsonSettings) setFromAppSettings(settings *Settings) {
 		s.ClickPause = typeutil.Ptr(settings.ClickPause.GetValue())
 	}
 	s.ReSeekSrc = typeutil.Ptr(settings.ReSeekSrc.GetValue())
+	s.DriftCorrection = typeutil.Ptr(settings.DriftCorrection.GetValue())
+	s.DriftSeekThreshMs = typeutil.Ptr(settings.DriftSeekThreshold.GetValue().Milliseconds())
+	s.PositionEstimator = typeutil.Ptr(string(settings.PositionEstimator.GetValue()))
+	s.LeaderID = typeutil.Ptr(settings.LeaderID.GetValue())
+	s.InstancesSettings = toJsonInstancesSettings(settings.InstancesSettings.GetValue())
+	s.FileSets = toJsonFileSets(settings.FileSets.GetValue())
+	s.Attach = arr.Map(settings.AttachTargets, func(target instance.AttachTarget) jsonConnection {
+		return jsonConnection{
+			Player:   string(target.Player),
+			Host:     target.Vlc.Host,
+			Port:     target.Vlc.Port,
+			Password: target.Vlc.Password,
+			Socket:   target.IpcSocket,
+		}
+	})
+	if settings.Peer != (PeerSettings{}) {
+		s.Peer = &jsonPeer{
+			Listen:  settings.Peer.ListenAddress,
+			Connect: settings.Peer.ConnectAddress,
+			Secret:  settings.Peer.Secret,
+		}
+	}
+	if settings.HttpApi != (HttpApiSettings{}) {
+		s.HttpApi = &jsonHttpApi{
+			Address: settings.HttpApi.Address,
+			Token:   settings.HttpApi.Token,
+		}
+	}
+	if settings.WebUi != (WebUiSettings{}) {
+		s.WebUi = &jsonWebUi{
+			Address: settings.WebUi.Address,
+		}
+	}
 }
 
 type SettingsStorage struct {
@@ -129,6 +365,30 @@ func (s *SettingsStorage) StartSyncing(ctx context.Context) error {
 		s.jsonSettings.ReSeekSrc = &v
 		s.saveJsonSettingsWithErrChan(syncErrCh)
 	}))
+	observers = append(observers, s.settings.DriftCorrection.Subscribe(func(v bool) {
+		s.jsonSettings.DriftCorrection = &v
+		s.saveJsonSettingsWithErrChan(syncErrCh)
+	}))
+	observers = append(observers, s.settings.DriftSeekThreshold.Subscribe(func(v time.Duration) {
+		s.jsonSettings.DriftSeekThreshMs = typeutil.Ptr(v.Milliseconds())
+		s.saveJsonSettingsWithErrChan(syncErrCh)
+	}))
+	observers = append(observers, s.settings.PositionEstimator.Subscribe(func(v state.PositionEstimator) {
+		s.jsonSettings.PositionEstimator = typeutil.Ptr(string(v))
+		s.saveJsonSettingsWithErrChan(syncErrCh)
+	}))
+	observers = append(observers, s.settings.LeaderID.Subscribe(func(v uint) {
+		s.jsonSettings.LeaderID = &v
+		s.saveJsonSettingsWithErrChan(syncErrCh)
+	}))
+	observers = append(observers, s.settings.InstancesSettings.Subscribe(func(v []syncer.InstanceSettings) {
+		s.jsonSettings.InstancesSettings = toJsonInstancesSettings(v)
+		s.saveJsonSettingsWithErrChan(syncErrCh)
+	}))
+	observers = append(observers, s.settings.FileSets.Subscribe(func(v []filemap.FileSet) {
+		s.jsonSettings.FileSets = toJsonFileSets(v)
+		s.saveJsonSettingsWithErrChan(syncErrCh)
+	}))
 
 	select {
 	case <-ctx.Done():
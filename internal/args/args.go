@@ -9,16 +9,40 @@ import (
 	"github.com/cardinalby/vlc-sync-play/internal/app/static_features"
 
 	"github.com/cardinalby/vlc-sync-play/internal/app"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic/protocols"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/state"
 	"golang.org/x/exp/slices"
 )
 
 type CmdLineArgs struct {
 	VlcPath           *string  `flag:"vlc" flagUsage:"Path to VLC executable"`
+	ApiProtocol       *string  `flag:"api" flagUsage:"VLC API protocol: http-json, lua-push or rc"`
+	MpvPath           *string  `flag:"mpv" flagUsage:"Path to mpv executable"`
+	Players           *string  `flag:"players" flagUsage:"Player per instance: vlc or mpv, e.g. \"vlc;mpv\""`
 	InstancesNumber   *int     `flag:"instances" flagUsage:"Number of VLC instances"`
 	PollingIntervalMs *int64   `flag:"interval" flagUsage:"Polling interval ms"`
 	ClickPause        *bool    `flag:"click-pause" flagUsage:"Click to pause/resume playback"`
 	NoVideo           *bool    `flag:"no-video" flagUsage:"Start additional instances without video"`
 	ReSeekSrc         *bool    `flag:"re-seek-src" flagUsage:"Re-seek source player for precise sync"`
+	DriftCorrection   *bool    `flag:"drift-correction" flagUsage:"Correct small drift by changing playback rate"`
+	DriftSeekThreshMs *int64   `flag:"drift-seek-threshold" flagUsage:"Drift ms to correct by seeking"`
+	PositionEstimator *string  `flag:"position-estimator" flagUsage:"Player position estimator: range or kalman"`
+	LeaderID          *uint    `flag:"leader" flagUsage:"ID of the only player allowed to control others, 0 to disable"`
+	AudioLanguages    *string  `flag:"audio-langs" flagUsage:"Preferred audio languages per instance, e.g. \"eng;rus,ukr\""`
+	Subtitles         *string  `flag:"subs" flagUsage:"Subtitles per instance: languages, file path or off, e.g. \"eng;off\""`
+	FileSuffixes      *string  `flag:"file-suffixes" flagUsage:"Open sibling files with suffixes per instance, e.g. \"eng;rus\""`
+	TimeTransforms    *string  `flag:"time-transforms" flagUsage:"Sibling files time offset ms and scale per instance, e.g. \"0;-5000*1.0427\""`
+	Attach            *string  `flag:"attach" flagUsage:"Attach to running VLC instances, e.g. \"password@192.168.1.10:8080;localhost:8081\""`
+	PeerListen        *string  `flag:"peer-listen" flagUsage:"Address to accept other vlc-sync-play peers on, e.g. \":7766\""`
+	PeerConnect       *string  `flag:"peer-connect" flagUsage:"Address of the listening vlc-sync-play peer to join"`
+	PeerSecret        *string  `flag:"peer-secret" flagUsage:"Secret shared by vlc-sync-play peers"`
+	HttpApiAddress    *string  `flag:"http-api-addr" flagUsage:"Address of HTTP control API, e.g. \"127.0.0.1:7767\""`
+	HttpApiToken      *string  `flag:"http-api-token" flagUsage:"Token required by HTTP control API"`
+	WebUiAddress      *string  `flag:"web-ui-addr" flagUsage:"Address of the web remote UI, e.g. \":7768\""`
+	WebUi             bool     `flag:"web-ui" flagUsage:"Run without TUI, control players from the web remote UI"`
+	NewSession        bool     `flag:"new-session" flagUsage:"Start even if another app is running instead of opening the file in it"`
+	Resume            bool     `flag:"resume" flagUsage:"Reopen the most recent file at the saved position with the saved tracks"`
+	RecordPath        *string  `flag:"record" flagUsage:"Record the sync session to the file to replay it with \"replay\" command"`
 	Debug             bool     `flag:"debug" flagUsage:"Debug mode"`
 	FilePaths         []string `flagArgs:"true"`
 }
@@ -28,6 +52,14 @@ func (args CmdLineArgs) ApplyToSettings(s *app.Settings) (updated bool) {
 		s.VlcPath = *args.VlcPath
 		updated = true
 	}
+	if args.ApiProtocol != nil {
+		s.ApiProtocol = protocols.ApiProtocol(*args.ApiProtocol)
+		updated = true
+	}
+	if args.MpvPath != nil {
+		s.MpvPath = *args.MpvPath
+		updated = true
+	}
 	if args.InstancesNumber != nil {
 		s.InstancesNumber.SetValue(*args.InstancesNumber)
 		updated = true
@@ -48,6 +80,83 @@ func (args CmdLineArgs) ApplyToSettings(s *app.Settings) (updated bool) {
 		s.ReSeekSrc.SetValue(*args.ReSeekSrc)
 		updated = true
 	}
+	if args.DriftCorrection != nil {
+		s.DriftCorrection.SetValue(*args.DriftCorrection)
+		updated = true
+	}
+	if args.DriftSeekThreshMs != nil {
+		s.DriftSeekThreshold.SetValue(time.Duration(*args.DriftSeekThreshMs) * time.Millisecond)
+		updated = true
+	}
+	if args.PositionEstimator != nil {
+		s.PositionEstimator.SetValue(state.PositionEstimator(*args.PositionEstimator))
+		updated = true
+	}
+	if args.LeaderID != nil {
+		s.LeaderID.SetValue(*args.LeaderID)
+		updated = true
+	}
+	if args.AudioLanguages != nil {
+		s.SetAudioLanguages(app.ParseAudioLanguages(*args.AudioLanguages))
+		updated = true
+	}
+	if args.Subtitles != nil {
+		s.SetSubtitles(app.ParseSubtitles(*args.Subtitles))
+		updated = true
+	}
+	if args.FileSuffixes != nil {
+		s.SetFileSuffixes(app.ParseFileSuffixes(*args.FileSuffixes))
+		updated = true
+	}
+	if args.Players != nil {
+		// validated in ParseCmdLineArgs
+		players, _ := app.ParsePlayers(*args.Players)
+		s.SetPlayers(players)
+		updated = true
+	}
+	if args.TimeTransforms != nil {
+		// validated in ParseCmdLineArgs
+		transforms, _ := app.ParseTimeTransforms(*args.TimeTransforms)
+		s.SetTimeTransforms(transforms)
+		updated = true
+	}
+	if args.Attach != nil {
+		// validated in ParseCmdLineArgs
+		s.AttachTargets, _ = app.ParseAttachTargets(*args.Attach)
+		updated = true
+	}
+	if args.PeerListen != nil {
+		s.Peer.ListenAddress = *args.PeerListen
+		updated = true
+	}
+	if args.PeerConnect != nil {
+		s.Peer.ConnectAddress = *args.PeerConnect
+		updated = true
+	}
+	if args.PeerSecret != nil {
+		s.Peer.Secret = *args.PeerSecret
+		updated = true
+	}
+	if args.HttpApiAddress != nil {
+		s.HttpApi.Address = *args.HttpApiAddress
+		updated = true
+	}
+	if args.HttpApiToken != nil {
+		s.HttpApi.Token = *args.HttpApiToken
+		updated = true
+	}
+	if args.WebUiAddress != nil {
+		s.WebUi.Address = *args.WebUiAddress
+		updated = true
+	}
+	if args.Resume {
+		s.Resume = true
+		updated = true
+	}
+	if args.RecordPath != nil {
+		s.RecordPath = *args.RecordPath
+		updated = true
+	}
 	if !slices.Equal(s.FilePaths, args.FilePaths) {
 		s.FilePaths = args.FilePaths
 		updated = true
@@ -69,6 +178,33 @@ func ParseCmdLineArgs() (a CmdLineArgs, err error) {
 	if err = flagSet.StructVar(&args, ignoredFields...); err != nil {
 		return args, err
 	}
-	err = flagSet.Parse(os.Args[1:])
-	return args, err
+	if err = flagSet.Parse(os.Args[1:]); err != nil {
+		return args, err
+	}
+	if args.TimeTransforms != nil {
+		if _, err = app.ParseTimeTransforms(*args.TimeTransforms); err != nil {
+			return args, err
+		}
+	}
+	if args.ApiProtocol != nil {
+		if err = protocols.ApiProtocol(*args.ApiProtocol).Validate(); err != nil {
+			return args, err
+		}
+	}
+	if args.PositionEstimator != nil {
+		if err = state.PositionEstimator(*args.PositionEstimator).Validate(); err != nil {
+			return args, err
+		}
+	}
+	if args.Players != nil {
+		if _, err = app.ParsePlayers(*args.Players); err != nil {
+			return args, err
+		}
+	}
+	if args.Attach != nil {
+		if _, err = app.ParseAttachTargets(*args.Attach); err != nil {
+			return args, err
+		}
+	}
+	return args, nil
 }
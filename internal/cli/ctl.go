@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/cardinalby/vlc-sync-play/internal/app"
+)
+
+// CtlCommand is the subcommand sending commands to the running app
+const CtlCommand = "ctl"
+
+const ctlUsage = `Usage: vlc-sync-play ctl <command>
+Commands:
+  pause
+  resume
+  seek <time>         absolute "1:23:45" or relative "-10s", "+1m30s"
+  open <path>
+  instances <number>
+  status              print players as JSON`
+
+// RunCtl sends the command from args to the running app
+func RunCtl(ctx context.Context, args []string, out io.Writer) error {
+	request, err := parseCtlArgs(args)
+	if err != nil {
+		return fmt.Errorf("%s\n%s", err.Error(), ctlUsage)
+	}
+	response, err := app.SendControlRequest(ctx, request)
+	if err != nil {
+		return err
+	}
+	if response.Error != "" {
+		return errors.New(response.Error)
+	}
+	if len(response.Result) > 0 {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, response.Result, "", "  "); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, indented.String())
+		return err
+	}
+	return nil
+}
+
+func parseCtlArgs(args []string) (app.ControlRequest, error) {
+	if len(args) == 0 {
+		return app.ControlRequest{}, errors.New("command is required")
+	}
+	request := app.ControlRequest{Command: args[0]}
+	switch request.Command {
+	case app.ControlCmdPause, app.ControlCmdResume, app.ControlCmdStatus:
+		if len(args) != 1 {
+			return request, fmt.Errorf("%s command has no arguments", request.Command)
+		}
+	case app.ControlCmdSeek, app.ControlCmdOpen, app.ControlCmdInstances:
+		if len(args) != 2 {
+			return request, fmt.Errorf("%s command requires one argument", request.Command)
+		}
+		request.Arg = args[1]
+	default:
+		return request, fmt.Errorf("unknown command '%s'", request.Command)
+	}
+	if request.Command == app.ControlCmdOpen && !strings.Contains(request.Arg, "://") {
+		// the running app has another working dir
+		absPath, err := filepath.Abs(request.Arg)
+		if err != nil {
+			return request, err
+		}
+		request.Arg = absPath
+	}
+	return request, nil
+}
@@ -4,21 +4,27 @@ import (
 	"context"
 
 	"github.com/cardinalby/vlc-sync-play/internal/app"
+	"github.com/cardinalby/vlc-sync-play/internal/cli/interactive/dashboard"
 	"github.com/cardinalby/vlc-sync-play/internal/cli/interactive/settings"
 	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
 	"github.com/rivo/tview"
 	"golang.org/x/sync/errgroup"
 )
 
+const settingsFormWidth = 50
+
 type App struct {
-	app      *app.App
-	tviewApp *tview.Application
+	app       *app.App
+	tviewApp  *tview.Application
+	dashboard *dashboard.Dashboard
 }
 
 func NewApp(logger logging.Logger) *App {
+	tviewApp := tview.NewApplication().EnableMouse(true)
 	return &App{
-		app:      app.NewApp(logger),
-		tviewApp: tview.NewApplication().EnableMouse(true),
+		app:       app.NewApp(logger),
+		tviewApp:  tviewApp,
+		dashboard: dashboard.NewDashboard(tviewApp),
 	}
 }
 
@@ -27,7 +33,19 @@ func (a *App) Init(settingsPatch app.SettingsPatch) error {
 	if err != nil {
 		return err
 	}
-	a.tviewApp.SetRoot(settings.BuildRoot(appSettings), false)
+	a.app.AddService(a.dashboard.Run)
+	settingsForm := settings.BuildRoot(appSettings)
+	// Escape switches focus between the settings and the players controlled by keys
+	settingsForm.SetCancelFunc(func() {
+		a.tviewApp.SetFocus(a.dashboard.GetPrimitive())
+	})
+	a.dashboard.SetDoneFunc(func() {
+		a.tviewApp.SetFocus(settingsForm)
+	})
+	root := tview.NewFlex().
+		AddItem(settingsForm, settingsFormWidth, 0, true).
+		AddItem(a.dashboard.GetPrimitive(), 0, 1, false)
+	a.tviewApp.SetRoot(root, true)
 	return nil
 }
 
@@ -0,0 +1,105 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/internal/app"
+	mathutil "github.com/cardinalby/vlc-sync-play/pkg/util/math"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	shortSeekStep = 10 * time.Second
+	longSeekStep  = 60 * time.Second
+	rateStep      = 0.1
+	minRate       = 0.25
+	maxRate       = 4.0
+)
+
+const controlsHelp = "j/k: select, space: pause/resume, ←/→: ∓10s, ↓/↑: ∓60s, [/]: rate, n: add, x: close, r: resync from selected"
+
+// controls apply key bindings to the whole group of players
+type controls struct {
+	ctx      context.Context
+	settings *app.Settings
+	syncer   *syncer.Syncer
+}
+
+// getAction returns the action bound to the key or nil
+func (c *controls) getAction(event *tcell.EventKey, selectedID uint) func() error {
+	switch event.Key() {
+	case tcell.KeyLeft:
+		return c.seekBy(-shortSeekStep)
+	case tcell.KeyRight:
+		return c.seekBy(shortSeekStep)
+	case tcell.KeyDown:
+		return c.seekBy(-longSeekStep)
+	case tcell.KeyUp:
+		return c.seekBy(longSeekStep)
+	case tcell.KeyRune:
+	default:
+		return nil
+	}
+	switch event.Rune() {
+	case ' ':
+		return c.togglePause
+	case '[':
+		return c.changeRate(-rateStep)
+	case ']':
+		return c.changeRate(rateStep)
+	case 'n':
+		return func() error {
+			return app.AddInstance(c.settings)
+		}
+	case 'x':
+		return func() error {
+			return app.StopInstance(c.settings, c.syncer, selectedID)
+		}
+	case 'r':
+		return func() error {
+			return c.syncer.ResyncFrom(c.ctx, selectedID)
+		}
+	}
+	return nil
+}
+
+func (c *controls) seekBy(offset time.Duration) func() error {
+	return func() error {
+		return c.syncer.SeekBy(c.ctx, offset)
+	}
+}
+
+func (c *controls) togglePause() error {
+	status, ok := c.syncer.GetLeaderStatus()
+	if !ok || status.State == basic.PlaybackStateStopped {
+		return syncer.ErrNoFileOpened
+	}
+	commands := extended.CmdGroup{}
+	if status.State == basic.PlaybackStatePlaying {
+		commands.State.Set(basic.PlaybackStatePaused)
+	} else {
+		commands.State.Set(basic.PlaybackStatePlaying)
+	}
+	c.syncer.SendCommands(c.ctx, commands)
+	return nil
+}
+
+func (c *controls) changeRate(delta float64) func() error {
+	return func() error {
+		status, ok := c.syncer.GetLeaderStatus()
+		if !ok {
+			return errors.New("no players")
+		}
+		rate := math.Round((status.Rate+delta)/rateStep) * rateStep
+		commands := extended.CmdGroup{}
+		commands.Rate.Set(mathutil.Clamp(rate, minRate, maxRate))
+		c.syncer.SendCommands(c.ctx, commands)
+		return nil
+	}
+}
@@ -0,0 +1,225 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/internal/app"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// refreshInterval of redrawing the table between syncer events to keep playback times up to date
+const refreshInterval = time.Second
+
+var columns = []string{"ID", "PID", "State", "Rate", "Time", "Offset", "RTT", "Last update"}
+
+// Dashboard is a table with a row per player. It is redrawn on syncer events and periodically.
+// Key bindings control all players while the table is focused
+type Dashboard struct {
+	table    *tview.Table
+	tviewApp *tview.Application
+
+	mu sync.Mutex
+	// lastUpdates are descriptions of the last update of each player
+	lastUpdates map[uint]string
+	// rowIDs are IDs of the players in the table rows
+	rowIDs   []uint
+	controls *controls
+	lastErr  error
+}
+
+func NewDashboard(tviewApp *tview.Application) *Dashboard {
+	table := tview.NewTable().
+		SetFixed(1, 0).
+		SetSelectable(true, false)
+	table.SetBorder(true).
+		SetTitleAlign(tview.AlignLeft)
+
+	d := &Dashboard{
+		table:       table,
+		tviewApp:    tviewApp,
+		lastUpdates: make(map[uint]string),
+	}
+	table.SetInputCapture(d.onKey)
+	d.render(nil)
+	d.renderTitle()
+	return d
+}
+
+// SetDoneFunc sets the handler of Escape and Tab keys
+func (d *Dashboard) SetDoneFunc(handler func()) {
+	d.table.SetDoneFunc(func(key tcell.Key) {
+		handler()
+	})
+}
+
+func (d *Dashboard) GetPrimitive() tview.Primitive {
+	return d.table
+}
+
+// Run redraws the table on the syncer events and periodically, handles key bindings until ctx is done
+func (d *Dashboard) Run(ctx context.Context, settings *app.Settings, playersSyncer *syncer.Syncer) error {
+	d.mu.Lock()
+	d.controls = &controls{
+		ctx:      ctx,
+		settings: settings,
+		syncer:   playersSyncer,
+	}
+	d.mu.Unlock()
+
+	changed := make(chan struct{}, 1)
+	defer playersSyncer.SubscribeEvents(func(event syncer.Event) {
+		d.onEvent(event)
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}).Unsubscribe()
+
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		case <-ticker.C:
+		}
+		players := playersSyncer.GetPlayers()
+		d.tviewApp.QueueUpdateDraw(func() {
+			d.render(players)
+		})
+	}
+}
+
+func (d *Dashboard) onKey(event *tcell.EventKey) *tcell.EventKey {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.controls == nil {
+		return event
+	}
+	var selectedID uint
+	if row, _ := d.table.GetSelection(); row > 0 && row <= len(d.rowIDs) {
+		selectedID = d.rowIDs[row-1]
+	}
+	action := d.controls.getAction(event, selectedID)
+	if action == nil {
+		return event
+	}
+	go func() {
+		err := action()
+		d.tviewApp.QueueUpdateDraw(func() {
+			d.mu.Lock()
+			d.lastErr = err
+			d.mu.Unlock()
+			d.renderTitle()
+		})
+	}()
+	return nil
+}
+
+func (d *Dashboard) onEvent(event syncer.Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch event.Type {
+	case syncer.EventTypeUpdateAccepted:
+		d.lastUpdates[event.PlayerID] = "caused sync"
+	case syncer.EventTypeUpdateSkipped:
+		d.lastUpdates[event.PlayerID] = "skipped: " + event.Reason
+	case syncer.EventTypeInstanceFinished:
+		delete(d.lastUpdates, event.PlayerID)
+	}
+}
+
+func (d *Dashboard) render(players []syncer.PlayerInfo) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var selectedID uint
+	if row, _ := d.table.GetSelection(); row > 0 && row <= len(d.rowIDs) {
+		selectedID = d.rowIDs[row-1]
+	}
+	d.table.Clear()
+	d.rowIDs = d.rowIDs[:0]
+	isSelected := false
+	for col, title := range columns {
+		d.table.SetCell(0, col, tview.NewTableCell(title).
+			SetTextColor(tcell.ColorYellow).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+	for i, info := range players {
+		row := i + 1
+		d.rowIDs = append(d.rowIDs, info.ID)
+		if info.ID == selectedID {
+			d.table.Select(row, 0)
+			isSelected = true
+		}
+		cells := d.getRowCells(info)
+		for col, text := range cells {
+			cell := tview.NewTableCell(text)
+			if info.IsLeader {
+				cell.SetTextColor(tcell.ColorGreen)
+			}
+			d.table.SetCell(row, col, cell)
+		}
+	}
+	if len(players) > 0 && !isSelected {
+		d.table.Select(1, 0)
+	}
+}
+
+func (d *Dashboard) renderTitle() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.lastErr != nil {
+		d.table.SetTitle("Players: " + d.lastErr.Error())
+	} else {
+		d.table.SetTitle("Players (" + controlsHelp + ")")
+	}
+}
+
+func (d *Dashboard) getRowCells(info syncer.PlayerInfo) []string {
+	id := strconv.FormatUint(uint64(info.ID), 10)
+	if info.IsLeader {
+		id += " (leader)"
+	}
+	pid := "attached"
+	if !info.IsAttached {
+		pid = strconv.Itoa(info.PID)
+	}
+	state, rate, pbTime := "-", "-", "-"
+	if info.Status.HasValue {
+		status := info.Status.Value
+		state = string(status.State)
+		rate = strconv.FormatFloat(status.Rate, 'f', 2, 64)
+		pbTime = fmt.Sprintf("%s / %s", formatPbTime(status.GetPbTime()), formatPbTime(status.GetLength()))
+	}
+	offset := "-"
+	if info.LeaderOffset.HasValue {
+		offset = fmt.Sprintf("%+d ms", info.LeaderOffset.Value.Milliseconds())
+	}
+	respTime := "-"
+	if info.ResponseTime.HasValue {
+		respTime = fmt.Sprintf("%d ms", info.ResponseTime.Value.Milliseconds())
+	}
+	lastUpdate, ok := d.lastUpdates[info.ID]
+	if !ok {
+		lastUpdate = "-"
+	}
+	return []string{id, pid, state, rate, pbTime, offset, respTime, lastUpdate}
+}
+
+func formatPbTime(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
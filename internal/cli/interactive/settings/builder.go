@@ -10,16 +10,26 @@ import (
 
 	"github.com/cardinalby/vlc-sync-play/internal/app"
 	"github.com/cardinalby/vlc-sync-play/pkg/util/arr"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/instance"
+	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
-func BuildRoot(settings *app.Settings) tview.Primitive {
+func BuildRoot(settings *app.Settings) *tview.Form {
 	form := tview.NewForm()
 
 	addVlcInstances(form, settings)
 	addNoVideo(form, settings)
 	addReSeekSrc(form, settings)
 	addPollingInterval(form, settings)
+	addDriftCorrection(form, settings)
+	addDriftSeekThreshold(form, settings)
+	addLeader(form, settings)
+	addAudioLanguages(form, settings)
+	addSubtitles(form, settings)
+	addFileSuffixes(form, settings)
+	addTimeTransforms(form, settings)
+	addAudioDelay(form, settings)
 	if static_features.ClickPause {
 		addClickPause(form, settings)
 	}
@@ -27,7 +37,7 @@ func BuildRoot(settings *app.Settings) tview.Primitive {
 	form.SetBorder(true).
 		SetTitle("Settings").
 		SetTitleAlign(tview.AlignLeft).
-		SetRect(0, 0, 50, 13)
+		SetRect(0, 0, 50, 33)
 
 	return form
 }
@@ -67,6 +77,157 @@ func addPollingInterval(form *tview.Form, settings *app.Settings) {
 		})
 }
 
+func addDriftSeekThreshold(form *tview.Form, settings *app.Settings) {
+	options, initIndex := prepareOptions([]time.Duration{
+		300 * time.Millisecond,
+		500 * time.Millisecond,
+		time.Second,
+		2 * time.Second,
+	}, settings.DriftSeekThreshold.GetValue())
+	strOptions := arr.Map(options, func(option time.Duration) string { return option.String() })
+
+	label := "Drift seek threshold"
+
+	form.AddDropDown(
+		label,
+		strOptions,
+		initIndex,
+		func(_ string, optionIndex int) {
+			settings.DriftSeekThreshold.SetValue(options[optionIndex])
+		})
+}
+
+func addDriftCorrection(form *tview.Form, settings *app.Settings) {
+	label := "Correct drift by changing playback rate"
+
+	form.AddCheckbox(label, settings.DriftCorrection.GetValue(), func(checked bool) {
+		settings.DriftCorrection.SetValue(checked)
+	})
+}
+
+func addLeader(form *tview.Form, settings *app.Settings) {
+	options, initIndex := prepareOptions([]uint{instance.IDNone, 1, 2, 3, 4}, settings.LeaderID.GetValue())
+	strOptions := arr.Map(options, func(option uint) string {
+		if option == instance.IDNone {
+			return "None"
+		}
+		return "Player " + strconv.FormatUint(uint64(option), 10)
+	})
+
+	label := "Leader"
+
+	form.AddDropDown(
+		label,
+		strOptions,
+		initIndex,
+		func(_ string, optionIndex int) {
+			settings.LeaderID.SetValue(options[optionIndex])
+		})
+}
+
+func addAudioLanguages(form *tview.Form, settings *app.Settings) {
+	addInputField(
+		form,
+		"Audio languages",
+		func() string {
+			return app.FormatAudioLanguages(settings.InstancesSettings.GetValue())
+		},
+		func(text string) {
+			settings.SetAudioLanguages(app.ParseAudioLanguages(text))
+		})
+}
+
+func addSubtitles(form *tview.Form, settings *app.Settings) {
+	addInputField(
+		form,
+		"Subtitles",
+		func() string {
+			return app.FormatSubtitles(settings.InstancesSettings.GetValue())
+		},
+		func(text string) {
+			settings.SetSubtitles(app.ParseSubtitles(text))
+		})
+}
+
+func addFileSuffixes(form *tview.Form, settings *app.Settings) {
+	addInputField(
+		form,
+		"File suffixes",
+		func() string {
+			return app.FormatFileSuffixes(settings.InstancesSettings.GetValue())
+		},
+		func(text string) {
+			settings.SetFileSuffixes(app.ParseFileSuffixes(text))
+		})
+}
+
+func addTimeTransforms(form *tview.Form, settings *app.Settings) {
+	addInputField(
+		form,
+		"Time transforms",
+		func() string {
+			return app.FormatTimeTransforms(settings.InstancesSettings.GetValue())
+		},
+		func(text string) {
+			if transforms, err := app.ParseTimeTransforms(text); err == nil {
+				settings.SetTimeTransforms(transforms)
+			}
+		})
+}
+
+// addInputField adds the field that applies the text once editing is done (Enter, Tab or Escape)
+// rather than on each keystroke. Then the field shows the formatted value, so invalid text gets reverted
+func addInputField(form *tview.Form, label string, format func() string, apply func(text string)) {
+	field := tview.NewInputField().
+		SetLabel(label).
+		SetText(format()).
+		SetFieldWidth(20)
+	field.SetDoneFunc(func(_ tcell.Key) {
+		apply(field.GetText())
+		field.SetText(format())
+	})
+	form.AddFormItem(field)
+}
+
+func addAudioDelay(form *tview.Form, settings *app.Settings) {
+	slot := 0
+	delayField := tview.NewInputField().
+		SetLabel("Audio delay, ms").
+		SetFieldWidth(8).
+		SetAcceptanceFunc(tview.InputFieldInteger)
+	updateDelayField := func() {
+		delayField.SetText(strconv.FormatInt(settings.GetAudioDelay(slot).Milliseconds(), 10))
+	}
+	updateDelayField()
+	delayField.SetChangedFunc(func(text string) {
+		if ms, err := strconv.ParseInt(text, 10, 64); err == nil {
+			if delay := time.Duration(ms) * time.Millisecond; delay != settings.GetAudioDelay(slot) {
+				settings.SetAudioDelay(slot, delay)
+			}
+		}
+	})
+	adjustDelay := func(delta time.Duration) {
+		settings.SetAudioDelay(slot, settings.GetAudioDelay(slot)+delta)
+		updateDelayField()
+	}
+
+	form.AddDropDown(
+		"Calibrate audio delay of",
+		[]string{"Player 1", "Player 2", "Player 3", "Player 4"},
+		slot,
+		func(_ string, optionIndex int) {
+			slot = optionIndex
+			updateDelayField()
+		})
+	form.AddFormItem(delayField)
+	form.AddButton("-10 ms", func() {
+		adjustDelay(-app.AudioDelayCalibrationStep)
+	})
+	form.AddButton("+10 ms", func() {
+		adjustDelay(app.AudioDelayCalibrationStep)
+	})
+}
+
 func addClickPause(form *tview.Form, settings *app.Settings) {
 	label := "Click to pause/resume playback"
 
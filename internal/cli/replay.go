@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/recording"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/state"
+)
+
+// ReplayCommand is the subcommand replaying a session recorded with --record flag
+const ReplayCommand = "replay"
+
+const replayUsage = `Usage: vlc-sync-play replay [--estimator range|kalman] <recording file>`
+
+// RunReplay replays the recording from args and prints the decisions that differ from the recorded ones
+func RunReplay(args []string, out io.Writer) error {
+	flagSet := flag.NewFlagSet(ReplayCommand, flag.ContinueOnError)
+	flagSet.SetOutput(io.Discard)
+	estimator := flagSet.String("estimator", string(state.PositionEstimatorRange), "")
+	if err := flagSet.Parse(args); err != nil {
+		return fmt.Errorf("%s\n%s", err.Error(), replayUsage)
+	}
+	if err := state.PositionEstimator(*estimator).Validate(); err != nil {
+		return err
+	}
+	if flagSet.NArg() != 1 {
+		return fmt.Errorf("recording file is required\n%s", replayUsage)
+	}
+	file, err := os.Open(flagSet.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	report, err := recording.Replay(file, state.PositionEstimator(*estimator), logging.NewNopLogger())
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(
+		out,
+		"statuses: %d, commands: %d, decisions: %d\n",
+		report.Statuses, report.Commands, report.Decisions,
+	)
+	for _, difference := range report.Differences {
+		_, _ = fmt.Fprintln(out, difference.String())
+	}
+	if len(report.Differences) > 0 {
+		return fmt.Errorf("%d differences found", len(report.Differences))
+	}
+	return nil
+}
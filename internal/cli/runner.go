@@ -10,6 +10,7 @@ import (
 	"github.com/cardinalby/vlc-sync-play/internal/args"
 	"github.com/cardinalby/vlc-sync-play/internal/cli/debug"
 	"github.com/cardinalby/vlc-sync-play/internal/cli/interactive"
+	"github.com/cardinalby/vlc-sync-play/internal/webui"
 	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
 )
 
@@ -23,9 +24,24 @@ func RunCliApp(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("error parsing command line args: %w", err)
 	}
+	if !cmdLineArgs.NewSession {
+		lock, isForwarded, err := app.LockOrForward(ctx, cmdLineArgs.FilePaths)
+		if err != nil || isForwarded {
+			return err
+		}
+		defer func() {
+			_ = lock.Release()
+		}()
+	}
 
 	var cliApp cliApp
-	if cmdLineArgs.Debug {
+	if cmdLineArgs.WebUi {
+		logger := logging.NewNopLogger()
+		if cmdLineArgs.Debug {
+			logger = logging.NewLogger(os.Stdout)
+		}
+		cliApp = webui.NewApp(logger, os.Stdout)
+	} else if cmdLineArgs.Debug {
 		cliApp = debug.NewApp(logging.NewLogger(os.Stdout))
 	} else {
 		cliApp = interactive.NewApp(logging.NewNopLogger())
@@ -4,7 +4,6 @@ import (
 	"context"
 
 	"fyne.io/systray"
-	"github.com/cardinalby/vlc-sync-play/assets"
 	"github.com/cardinalby/vlc-sync-play/internal/app"
 	"github.com/cardinalby/vlc-sync-play/internal/trayagent/menu"
 	"github.com/cardinalby/vlc-sync-play/pkg/tray"
@@ -12,13 +11,18 @@ import (
 )
 
 type App struct {
-	app         *app.App
-	appSettings *app.Settings
+	app             *app.App
+	appSettings     *app.Settings
+	playersMenu     *menu.PlayersMenu
+	recentMenu      *menu.RecentMenu
+	healthIndicator *healthIndicator
 }
 
 func NewApp(logger logging.Logger) *App {
 	return &App{
-		app: app.NewApp(logger),
+		app:             app.NewApp(logger),
+		playersMenu:     menu.NewPlayersMenu(),
+		healthIndicator: newHealthIndicator(logger.WithPrefix("tray")),
 	}
 }
 
@@ -27,6 +31,10 @@ func (a *App) Init(settingsPatch app.SettingsPatch) (err error) {
 	if err != nil {
 		return err
 	}
+	a.recentMenu = menu.NewRecentMenu(a.app.GetHistory())
+	a.app.AddService(a.playersMenu.Run)
+	a.app.AddService(a.recentMenu.Run)
+	a.app.AddService(a.healthIndicator.Run)
 	return nil
 }
 
@@ -57,8 +65,14 @@ func (a *App) Start(ctx context.Context) error {
 }
 
 func (a *App) setupTrayMenu(ctx context.Context, onQuit func()) {
-	a.setIcon()
-	systray.SetTooltip("VLC Sync Play")
+	setDefaultIcon()
+	systray.SetTooltip(trayTooltip)
+	a.healthIndicator.SetReady()
+
+	a.playersMenu.AddMenuItems(ctx, nil)
+	a.recentMenu.AddMenuItems(ctx, nil)
+
+	systray.AddSeparator()
 
 	menu.AddSettingsMenuItems(ctx, nil, a.appSettings)
 
@@ -70,13 +84,3 @@ func (a *App) setupTrayMenu(ctx context.Context, onQuit func()) {
 		onQuit,
 	)
 }
-
-func (a *App) setIcon() {
-	icon := assets.GetTrayIcon()
-	templateIcon := assets.GetTemplateTrayIcon()
-	if templateIcon != nil {
-		systray.SetTemplateIcon(templateIcon, templateIcon)
-	} else {
-		systray.SetIcon(icon)
-	}
-}
@@ -0,0 +1,141 @@
+package trayagent
+
+import (
+	"context"
+	"fmt"
+	"image/color"
+	"time"
+
+	"fyne.io/systray"
+	"github.com/cardinalby/vlc-sync-play/assets"
+	"github.com/cardinalby/vlc-sync-play/internal/app"
+	"github.com/cardinalby/vlc-sync-play/pkg/tray"
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
+)
+
+const (
+	trayTooltip = "VLC Sync Play"
+	// healthRefreshInterval is how often the health is checked besides syncer events
+	healthRefreshInterval = time.Second
+)
+
+var healthBadges = map[syncer.HealthState]tray.Badge{
+	syncer.HealthIdle:       {Color: color.RGBA{R: 0x90, G: 0x90, B: 0x90, A: 0xff}, IsHollow: true},
+	syncer.HealthInSync:     {Color: color.RGBA{R: 0x2e, G: 0xb8, B: 0x4b, A: 0xff}},
+	syncer.HealthCorrecting: {Color: color.RGBA{R: 0xf0, G: 0xa0, B: 0x20, A: 0xff}},
+	syncer.HealthDegraded:   {Color: color.RGBA{R: 0xe0, G: 0x30, B: 0x30, A: 0xff}},
+}
+
+// healthIndicator shows the sync health in the tray icon badge and the tooltip
+type healthIndicator struct {
+	ready chan struct{}
+	// icons are cached icon variants
+	icons  map[syncer.HealthState][]byte
+	logger logging.Logger
+}
+
+func newHealthIndicator(logger logging.Logger) *healthIndicator {
+	return &healthIndicator{
+		ready:  make(chan struct{}),
+		icons:  make(map[syncer.HealthState][]byte),
+		logger: logger,
+	}
+}
+
+// SetReady should be called when the tray is ready. Health is not shown until then
+func (h *healthIndicator) SetReady() {
+	close(h.ready)
+}
+
+// Run updates the icon and the tooltip until ctx is done. The default icon is restored then
+func (h *healthIndicator) Run(ctx context.Context, _ *app.Settings, playersSyncer *syncer.Syncer) error {
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-h.ready:
+	}
+
+	changed := make(chan struct{}, 1)
+	defer playersSyncer.SubscribeEvents(func(event syncer.Event) {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}).Unsubscribe()
+
+	ticker := time.NewTicker(healthRefreshInterval)
+	defer ticker.Stop()
+
+	var shownState syncer.HealthState
+	var shownTooltip string
+	for {
+		health := playersSyncer.GetHealth()
+		if health.State != shownState {
+			h.setIcon(health.State)
+			shownState = health.State
+		}
+		if tooltip := formatHealthTooltip(health); tooltip != shownTooltip {
+			systray.SetTooltip(tooltip)
+			shownTooltip = tooltip
+		}
+		select {
+		case <-ctx.Done():
+			setDefaultIcon()
+			systray.SetTooltip(trayTooltip)
+			return nil
+		case <-changed:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *healthIndicator) setIcon(state syncer.HealthState) {
+	templateIcon := assets.GetTemplateTrayIcon()
+	icon, ok := h.icons[state]
+	if !ok {
+		var err error
+		if icon, err = getHealthIcon(templateIcon, state); err != nil {
+			h.logger.Err("Failed to draw tray icon badge: %s", err.Error())
+			return
+		}
+		h.icons[state] = icon
+	}
+	if templateIcon != nil {
+		systray.SetTemplateIcon(icon, icon)
+	} else {
+		systray.SetIcon(icon)
+	}
+}
+
+// getHealthIcon returns the icon with the badge. Template icons are monochrome, the badge color is lost
+// there, so "in sync" is shown as the icon without a badge
+func getHealthIcon(templateIcon []byte, state syncer.HealthState) ([]byte, error) {
+	if templateIcon != nil {
+		if state == syncer.HealthInSync {
+			return templateIcon, nil
+		}
+		return tray.AddBadge(templateIcon, healthBadges[state])
+	}
+	icon, err := tray.AddBadge(assets.GetTrayIconPng(), healthBadges[state])
+	if err != nil {
+		return nil, err
+	}
+	return tray.ToPlatformIcon(icon), nil
+}
+
+func setDefaultIcon() {
+	if templateIcon := assets.GetTemplateTrayIcon(); templateIcon != nil {
+		systray.SetTemplateIcon(templateIcon, templateIcon)
+	} else {
+		systray.SetIcon(assets.GetTrayIcon())
+	}
+}
+
+func formatHealthTooltip(health syncer.Health) string {
+	tooltip := fmt.Sprintf("%s: %s", trayTooltip, health.State)
+	if health.MaxOffset.HasValue {
+		tooltip += fmt.Sprintf(", max offset %d ms", health.MaxOffset.Value.Milliseconds())
+	}
+	return tooltip
+}
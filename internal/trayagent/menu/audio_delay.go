@@ -0,0 +1,53 @@
+package menu
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fyne.io/systray"
+	"github.com/cardinalby/vlc-sync-play/internal/app"
+	"github.com/cardinalby/vlc-sync-play/pkg/tray"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
+)
+
+const audioDelayMenuSlotsNumber = 4
+
+func addAudioDelayMenuItem(ctx context.Context, parent *systray.MenuItem, settings *app.Settings) {
+	item := tray.GetAddMenuItemFn(parent)(
+		"Audio delay",
+		"Compensate audio output latency (e.g. Bluetooth earphones) of each player",
+	)
+	for slot := 0; slot < audioDelayMenuSlotsNumber; slot++ {
+		addSlotAudioDelayMenuItem(ctx, item, settings, slot)
+	}
+}
+
+func addSlotAudioDelayMenuItem(ctx context.Context, parent *systray.MenuItem, settings *app.Settings, slot int) {
+	getTitle := func() string {
+		return fmt.Sprintf("Player %d: %s", slot+1, formatAudioDelay(settings.GetAudioDelay(slot)))
+	}
+	slotItem := parent.AddSubMenuItem(getTitle(), "Adjust while listening until the sound is in sync")
+
+	tray.OnClicked(ctx, slotItem.AddSubMenuItem("-10 ms", "Advance audio"), func() {
+		settings.SetAudioDelay(slot, settings.GetAudioDelay(slot)-app.AudioDelayCalibrationStep)
+	})
+	tray.OnClicked(ctx, slotItem.AddSubMenuItem("+10 ms", "Delay audio"), func() {
+		settings.SetAudioDelay(slot, settings.GetAudioDelay(slot)+app.AudioDelayCalibrationStep)
+	})
+	tray.OnClicked(ctx, slotItem.AddSubMenuItem("Reset", "Remove audio delay"), func() {
+		settings.SetAudioDelay(slot, 0)
+	})
+
+	subscription := settings.InstancesSettings.Subscribe(func(_ []syncer.InstanceSettings) {
+		slotItem.SetTitle(getTitle())
+	})
+	go func() {
+		<-ctx.Done()
+		subscription.Unsubscribe()
+	}()
+}
+
+func formatAudioDelay(delay time.Duration) string {
+	return fmt.Sprintf("%+d ms", delay.Milliseconds())
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/cardinalby/vlc-sync-play/internal/app/static_features"
 	"github.com/cardinalby/vlc-sync-play/pkg/tray"
 	"github.com/cardinalby/vlc-sync-play/pkg/util/rx"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/instance"
 )
 
 func AddSettingsMenuItems(ctx context.Context, parent *systray.MenuItem, settings *app.Settings) {
@@ -17,6 +18,10 @@ func AddSettingsMenuItems(ctx context.Context, parent *systray.MenuItem, setting
 	addNoVideoMenuItem(ctx, parent, settings.NoVideo)
 	addReSeekSrcMenuItem(ctx, parent, settings.ReSeekSrc)
 	addPollingIntervalMenuItem(ctx, parent, settings.PollingInterval)
+	addDriftCorrectionMenuItem(ctx, parent, settings.DriftCorrection)
+	addDriftSeekThresholdMenuItem(ctx, parent, settings.DriftSeekThreshold)
+	addLeaderMenuItem(ctx, parent, settings.LeaderID)
+	addAudioDelayMenuItem(ctx, parent, settings)
 	if static_features.ClickPause {
 		addClickPauseMenuItem(ctx, parent, settings.ClickPause)
 	}
@@ -43,6 +48,34 @@ func addPollingIntervalMenuItem(ctx context.Context, parent *systray.MenuItem, s
 	}, time.Duration.String)
 }
 
+func addDriftSeekThresholdMenuItem(ctx context.Context, parent *systray.MenuItem, setting rx.Value[time.Duration]) {
+	item := tray.GetAddMenuItemFn(parent)(
+		"Drift seek threshold",
+		"Drift that is corrected by seeking instead of changing playback rate",
+	)
+	tray.AddOptionsSubMenu(ctx, item, setting, []time.Duration{
+		300 * time.Millisecond,
+		500 * time.Millisecond,
+		time.Second,
+		2 * time.Second,
+	}, time.Duration.String)
+}
+
+func addLeaderMenuItem(ctx context.Context, parent *systray.MenuItem, setting rx.Value[uint]) {
+	item := tray.GetAddMenuItemFn(parent)(
+		"Leader",
+		"The only player allowed to control others. Changes made in other players are reverted",
+	)
+	tray.AddOptionsSubMenu(ctx, item, setting, []uint{instance.IDNone, 1, 2, 3, 4}, formatLeaderID)
+}
+
+func formatLeaderID(id uint) string {
+	if id == instance.IDNone {
+		return "None"
+	}
+	return "Player " + strconv.FormatUint(uint64(id), 10)
+}
+
 func addNoVideoMenuItem(ctx context.Context, parent *systray.MenuItem, setting rx.Value[bool]) {
 	tray.AddBoolOptionMenu(
 		ctx,
@@ -63,6 +96,16 @@ func addReSeekSrcMenuItem(ctx context.Context, parent *systray.MenuItem, setting
 	)
 }
 
+func addDriftCorrectionMenuItem(ctx context.Context, parent *systray.MenuItem, setting rx.Value[bool]) {
+	tray.AddBoolOptionMenu(
+		ctx,
+		parent,
+		setting,
+		"Drift correction",
+		"Correct small drift between players by changing playback rate",
+	)
+}
+
 func addClickPauseMenuItem(ctx context.Context, parent *systray.MenuItem, setting rx.Value[bool]) {
 	tray.AddBoolOptionMenu(
 		ctx,
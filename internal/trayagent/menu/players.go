@@ -0,0 +1,188 @@
+package menu
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"fyne.io/systray"
+	"github.com/cardinalby/vlc-sync-play/internal/app"
+	"github.com/cardinalby/vlc-sync-play/pkg/tray"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/instance"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
+)
+
+// playersMenuRefreshInterval is how often the playback time of the players is updated besides syncer events
+const playersMenuRefreshInterval = 2 * time.Second
+
+// PlayersMenu shows running players and group playback actions. Items are added before the syncer
+// is started, actions are ignored until Run is called
+type PlayersMenu struct {
+	mu          sync.Mutex
+	playersItem *systray.MenuItem
+	pool        *tray.ItemsPool
+	// playerIDs are IDs of the players shown in the pool items
+	playerIDs []uint
+	built     chan struct{}
+
+	ctx      context.Context
+	settings *app.Settings
+	syncer   *syncer.Syncer
+}
+
+func NewPlayersMenu() *PlayersMenu {
+	return &PlayersMenu{
+		built: make(chan struct{}),
+	}
+}
+
+// AddMenuItems adds "Players" submenu and group actions
+func (m *PlayersMenu) AddMenuItems(ctx context.Context, parent *systray.MenuItem) {
+	addMenuItemFn := tray.GetAddMenuItemFn(parent)
+	m.playersItem = addMenuItemFn("Players", "Running VLC instances")
+	m.pool = tray.NewItemsPool(m.playersItem, app.MaxInstancesNumber, func(index int, item *systray.MenuItem) {
+		m.addPlayerActions(ctx, index, item)
+	})
+
+	tray.OnClicked(ctx, addMenuItemFn("Pause all", "Pause all players"), func() {
+		m.sendState(basic.PlaybackStatePaused)
+	})
+	tray.OnClicked(ctx, addMenuItemFn("Resume all", "Resume all players"), func() {
+		m.sendState(basic.PlaybackStatePlaying)
+	})
+	tray.OnClicked(ctx, addMenuItemFn("Resync now", "Sync all players to the leader"), func() {
+		m.resyncFromLeader()
+	})
+	close(m.built)
+}
+
+func (m *PlayersMenu) addPlayerActions(ctx context.Context, index int, item *systray.MenuItem) {
+	tray.OnClicked(ctx, item.AddSubMenuItem("Make leader", "Allow only this player to control others"), func() {
+		if id, ok := m.getPlayerID(index); ok {
+			m.settings.LeaderID.SetValue(id)
+		}
+	})
+	tray.OnClicked(ctx, item.AddSubMenuItem("Resync from this player", "Sync other players to this one"), func() {
+		if id, ok := m.getPlayerID(index); ok {
+			_ = m.syncer.ResyncFrom(m.ctx, id)
+		}
+	})
+	tray.OnClicked(ctx, item.AddSubMenuItem("Close", "Close the player"), func() {
+		if id, ok := m.getPlayerID(index); ok {
+			_ = app.StopInstance(m.settings, m.syncer, id)
+		}
+	})
+}
+
+// Run updates the menu as the syncer adds and removes players until ctx is done
+func (m *PlayersMenu) Run(ctx context.Context, settings *app.Settings, playersSyncer *syncer.Syncer) error {
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-m.built:
+	}
+	m.mu.Lock()
+	m.ctx = ctx
+	m.settings = settings
+	m.syncer = playersSyncer
+	m.mu.Unlock()
+
+	changed := make(chan struct{}, 1)
+	defer playersSyncer.SubscribeEvents(func(event syncer.Event) {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}).Unsubscribe()
+
+	ticker := time.NewTicker(playersMenuRefreshInterval)
+	defer ticker.Stop()
+	for {
+		m.update(playersSyncer.GetPlayers())
+		select {
+		case <-ctx.Done():
+			m.update(nil)
+			return nil
+		case <-changed:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *PlayersMenu) update(players []syncer.PlayerInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.playerIDs = m.playerIDs[:0]
+	titles := make([]string, 0, len(players))
+	for _, info := range players {
+		m.playerIDs = append(m.playerIDs, info.ID)
+		titles = append(titles, formatPlayerTitle(info))
+	}
+	m.pool.SetTitles(titles)
+	m.playersItem.SetTitle(fmt.Sprintf("Players (%d)", len(players)))
+}
+
+// getPlayerID returns ID of the player shown in the pool item if the syncer is running
+func (m *PlayersMenu) getPlayerID(index int) (uint, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.syncer == nil || index >= len(m.playerIDs) {
+		return instance.IDNone, false
+	}
+	return m.playerIDs[index], true
+}
+
+func (m *PlayersMenu) getSyncer() *syncer.Syncer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.syncer
+}
+
+func (m *PlayersMenu) sendState(state basic.PlaybackState) {
+	if playersSyncer := m.getSyncer(); playersSyncer != nil {
+		commands := extended.CmdGroup{}
+		commands.State.Set(state)
+		playersSyncer.SendCommands(m.ctx, commands)
+	}
+}
+
+func (m *PlayersMenu) resyncFromLeader() {
+	playersSyncer := m.getSyncer()
+	if playersSyncer == nil {
+		return
+	}
+	for _, info := range playersSyncer.GetPlayers() {
+		if info.IsLeader {
+			_ = playersSyncer.ResyncFrom(m.ctx, info.ID)
+			return
+		}
+	}
+}
+
+func formatPlayerTitle(info syncer.PlayerInfo) string {
+	title := fmt.Sprintf("Player %d", info.ID)
+	if info.IsLeader {
+		title += " (leader)"
+	}
+	if !info.Status.HasValue {
+		return title + ": connecting"
+	}
+	status := info.Status.Value
+	if status.State == basic.PlaybackStateStopped {
+		return title + ": stopped"
+	}
+	return fmt.Sprintf(
+		"%s: %s %s / %s",
+		title, status.State, formatPbTime(status.GetPbTime()), formatPbTime(status.GetLength()),
+	)
+}
+
+func formatPbTime(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
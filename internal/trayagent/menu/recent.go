@@ -0,0 +1,86 @@
+package menu
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"fyne.io/systray"
+	"github.com/cardinalby/vlc-sync-play/internal/app"
+	"github.com/cardinalby/vlc-sync-play/pkg/tray"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
+)
+
+// RecentMenu shows recently opened files. Clicking an item reopens the file in all players at the saved
+// position. Items are added before the syncer is started, clicks are ignored until Run is called
+type RecentMenu struct {
+	mu      sync.Mutex
+	history *app.History
+	entries []app.HistoryEntry
+	pool    *tray.ItemsPool
+
+	ctx    context.Context
+	syncer *syncer.Syncer
+}
+
+func NewRecentMenu(history *app.History) *RecentMenu {
+	return &RecentMenu{
+		history: history,
+	}
+}
+
+// AddMenuItems adds "Recent" submenu that follows the history until ctx is done
+func (m *RecentMenu) AddMenuItems(ctx context.Context, parent *systray.MenuItem) {
+	recentItem := tray.GetAddMenuItemFn(parent)("Recent", "Recently opened files")
+	m.pool = tray.NewItemsPool(recentItem, app.HistoryMaxEntries, func(index int, item *systray.MenuItem) {
+		tray.OnClicked(ctx, item, func() {
+			m.restore(index)
+		})
+	})
+	m.update(m.history.Entries.GetValue())
+	subscription := m.history.Entries.Subscribe(m.update)
+	go func() {
+		<-ctx.Done()
+		subscription.Unsubscribe()
+	}()
+}
+
+// Run enables reopening files until ctx is done
+func (m *RecentMenu) Run(ctx context.Context, _ *app.Settings, playersSyncer *syncer.Syncer) error {
+	m.mu.Lock()
+	m.ctx = ctx
+	m.syncer = playersSyncer
+	m.mu.Unlock()
+
+	<-ctx.Done()
+
+	m.mu.Lock()
+	m.syncer = nil
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *RecentMenu) update(entries []app.HistoryEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries = entries
+	titles := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		titles = append(titles, fmt.Sprintf("%s (%s)", entry.GetTitle(), formatPbTime(entry.Session.PbTime)))
+	}
+	m.pool.SetTitles(titles)
+}
+
+func (m *RecentMenu) restore(index int) {
+	m.mu.Lock()
+	playersSyncer, ctx := m.syncer, m.ctx
+	if playersSyncer == nil || index >= len(m.entries) {
+		m.mu.Unlock()
+		return
+	}
+	entry := m.entries[index]
+	m.mu.Unlock()
+
+	_ = playersSyncer.RestoreSession(ctx, entry.Session)
+}
@@ -0,0 +1,65 @@
+package webui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/cardinalby/vlc-sync-play/internal/app"
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
+)
+
+// App runs the app without TUI and serves the web remote UI. The address and the PIN are printed to out
+type App struct {
+	app    *app.App
+	out    io.Writer
+	logger logging.Logger
+}
+
+func NewApp(logger logging.Logger, out io.Writer) *App {
+	return &App{
+		app:    app.NewApp(logger),
+		out:    out,
+		logger: logger,
+	}
+}
+
+func (a *App) Init(settingsPatch app.SettingsPatch) error {
+	if _, err := a.app.Init(settingsPatch); err != nil {
+		return err
+	}
+	a.app.AddService(a.runServer)
+	return nil
+}
+
+func (a *App) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	call := make(chan os.Signal, 1)
+	signal.Notify(call, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(call)
+	go func() {
+		select {
+		case <-call:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return a.app.Start(ctx)
+}
+
+func (a *App) runServer(ctx context.Context, settings *app.Settings, playersSyncer *syncer.Syncer) error {
+	server := NewServer(settings, playersSyncer, a.logger.WithPrefix("webui"))
+	server.OnPinChanged(func(pin string) {
+		_, _ = fmt.Fprintf(a.out, "Web remote PIN: %s\n", pin)
+	})
+	return server.Run(ctx, func(urls []string) {
+		for _, url := range urls {
+			_, _ = fmt.Fprintf(a.out, "Web remote: %s\n", url)
+		}
+	})
+}
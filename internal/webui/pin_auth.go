@@ -0,0 +1,89 @@
+package webui
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+const (
+	pinDigits            = 6
+	pinMaxFailedAttempts = 5
+	sessionTokenBytes    = 32
+)
+
+// pinAuth exchanges a one-time PIN for a session token. The PIN is replaced after a successful
+// login or after pinMaxFailedAttempts wrong attempts
+type pinAuth struct {
+	mu             sync.Mutex
+	pin            string
+	failedAttempts int
+	sessions       map[string]struct{}
+	onPinChanged   func(pin string)
+}
+
+func newPinAuth() *pinAuth {
+	return &pinAuth{
+		sessions: make(map[string]struct{}),
+	}
+}
+
+// Start generates the first PIN
+func (a *pinAuth) Start() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.renewPin()
+}
+
+// Login returns a new session token if the pin is correct
+func (a *pinAuth) Login(pin string) (sessionToken string, ok bool, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.pin == "" {
+		return "", false, nil
+	}
+	if subtle.ConstantTimeCompare([]byte(pin), []byte(a.pin)) != 1 {
+		a.failedAttempts++
+		if a.failedAttempts >= pinMaxFailedAttempts {
+			return "", false, a.renewPin()
+		}
+		return "", false, nil
+	}
+	tokenBytes := make([]byte, sessionTokenBytes)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", false, err
+	}
+	sessionToken = hex.EncodeToString(tokenBytes)
+	a.sessions[sessionToken] = struct{}{}
+	return sessionToken, true, a.renewPin()
+}
+
+func (a *pinAuth) IsValidSession(sessionToken string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	_, ok := a.sessions[sessionToken]
+	return ok
+}
+
+func (a *pinAuth) renewPin() error {
+	maxPin := big.NewInt(1)
+	for i := 0; i < pinDigits; i++ {
+		maxPin.Mul(maxPin, big.NewInt(10))
+	}
+	pin, err := rand.Int(rand.Reader, maxPin)
+	if err != nil {
+		return err
+	}
+	a.pin = fmt.Sprintf("%0*d", pinDigits, pin)
+	a.failedAttempts = 0
+	if a.onPinChanged != nil {
+		a.onPinChanged(a.pin)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package webui
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/internal/app"
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
+)
+
+const (
+	DefaultAddress        = ":7768"
+	sessionCookieName     = "vlc-sync-play-session"
+	serverShutdownTimeout = 3 * time.Second
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+type loginRequest struct {
+	Pin string `json:"pin"`
+}
+
+// Server serves the web remote UI and the HTTP API it uses. API requests are authorized by a session
+// cookie obtained in exchange for the PIN
+type Server struct {
+	settings *app.Settings
+	syncer   *syncer.Syncer
+	auth     *pinAuth
+	logger   logging.Logger
+}
+
+func NewServer(settings *app.Settings, playersSyncer *syncer.Syncer, logger logging.Logger) *Server {
+	return &Server{
+		settings: settings,
+		syncer:   playersSyncer,
+		auth:     newPinAuth(),
+		logger:   logger,
+	}
+}
+
+// OnPinChanged sets the callback called with each new PIN. Should be called before Run
+func (s *Server) OnPinChanged(onPinChanged func(pin string)) {
+	s.auth.onPinChanged = onPinChanged
+}
+
+// Run serves the UI until ctx is done. onListening is called with URLs of the UI
+func (s *Server) Run(ctx context.Context, onListening func(urls []string)) error {
+	address := s.settings.WebUi.Address
+	if address == "" {
+		address = DefaultAddress
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("error listening web UI address: %w", err)
+	}
+	if err := s.auth.Start(); err != nil {
+		_ = listener.Close()
+		return err
+	}
+	server := &http.Server{
+		Handler:           s.handler(ctx),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	if onListening != nil {
+		onListening(getURLs(listener.Addr()))
+	}
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) handler(ctx context.Context) http.Handler {
+	staticRoot, _ := fs.Sub(staticFiles, "static")
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.FS(staticRoot)))
+	mux.HandleFunc("/login", s.handleLogin)
+	mux.Handle("/api/", s.withSession(app.NewHttpApi(s.settings, s.syncer, s.logger).RemoteHandler(ctx)))
+	return mux
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req loginRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1024)).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	sessionToken, ok, err := s.auth.Login(req.Pin)
+	if err != nil {
+		s.logger.Err("error logging in: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		s.logger.Info("wrong PIN from %s", r.RemoteAddr)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	s.logger.Info("%s logged in", r.RemoteAddr)
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    sessionToken,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) withSession(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(sessionCookieName)
+		if err != nil || !s.auth.IsValidSession(cookie.Value) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// getURLs returns URLs of the UI on all IPv4 addresses of the machine if listening on all interfaces
+func getURLs(addr net.Addr) []string {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return []string{"http://" + addr.String()}
+	}
+	if !tcpAddr.IP.IsUnspecified() {
+		return []string{"http://" + tcpAddr.String()}
+	}
+	var urls []string
+	if interfaceAddrs, err := net.InterfaceAddrs(); err == nil {
+		for _, interfaceAddr := range interfaceAddrs {
+			if ipNet, ok := interfaceAddr.(*net.IPNet); ok && ipNet.IP.To4() != nil && !ipNet.IP.IsLoopback() {
+				urls = append(urls, fmt.Sprintf("http://%s:%d", ipNet.IP, tcpAddr.Port))
+			}
+		}
+	}
+	if len(urls) == 0 {
+		urls = append(urls, fmt.Sprintf("http://127.0.0.1:%d", tcpAddr.Port))
+	}
+	return urls
+}
@@ -0,0 +1,233 @@
+'use strict';
+
+const refreshInterval = 1000;
+const reconnectInterval = 2000;
+const skipStep = 10000;
+
+const el = (id) => document.getElementById(id);
+
+let players = [];
+let leaderId = 0;
+let isScrubbing = false;
+let refreshTimer = null;
+
+async function request(method, path, body) {
+    const resp = await fetch(path, {
+        method,
+        headers: body !== undefined ? {'Content-Type': 'application/json'} : {},
+        body: body !== undefined ? JSON.stringify(body) : undefined,
+    });
+    if (resp.status === 401) {
+        showLogin();
+        throw new Error('unauthorized');
+    }
+    if (!resp.ok) {
+        throw new Error(`${method} ${path}: ${resp.status}`);
+    }
+    return resp.status === 204 || resp.status === 202 ? null : resp.json();
+}
+
+function showLogin() {
+    clearInterval(refreshTimer);
+    refreshTimer = null;
+    el('remote').hidden = true;
+    el('login').hidden = false;
+    el('pin').focus();
+}
+
+function showRemote() {
+    el('login').hidden = true;
+    el('remote').hidden = false;
+    if (refreshTimer === null) {
+        refreshTimer = setInterval(refresh, refreshInterval);
+        connectEvents();
+    }
+}
+
+el('login-form').addEventListener('submit', async (e) => {
+    e.preventDefault();
+    const resp = await fetch('/login', {
+        method: 'POST',
+        headers: {'Content-Type': 'application/json'},
+        body: JSON.stringify({pin: el('pin').value}),
+    });
+    el('login-error').hidden = resp.ok;
+    el('pin').value = '';
+    if (resp.ok) {
+        await refresh();
+    }
+});
+
+function formatTime(ms) {
+    const totalSec = Math.max(0, Math.floor(ms / 1000));
+    const h = Math.floor(totalSec / 3600);
+    const m = Math.floor(totalSec / 60) % 60;
+    const s = String(totalSec % 60).padStart(2, '0');
+    return h > 0 ? `${h}:${String(m).padStart(2, '0')}:${s}` : `${m}:${s}`;
+}
+
+function fileName(uri) {
+    if (!uri) {
+        return 'No file';
+    }
+    return decodeURIComponent(uri.split('/').pop());
+}
+
+// expectedTimeMs extrapolates the playback time from the moment of the status
+function expectedTimeMs(status) {
+    let time = status['time-ms'];
+    if (status.state === 'playing') {
+        time += (Date.now() - Date.parse(status.moment)) * status.rate;
+    }
+    return Math.min(time, status['length-ms']);
+}
+
+function getLeader() {
+    return players.find((p) => p.leader) || players[0];
+}
+
+async function refresh() {
+    try {
+        let leader;
+        [players, leader] = await Promise.all([
+            request('GET', '/api/players'),
+            request('GET', '/api/leader'),
+        ]);
+        leaderId = leader.leader;
+        el('connection').hidden = true;
+        showRemote();
+        render();
+    } catch (e) {
+        if (e.message !== 'unauthorized') {
+            el('connection').hidden = false;
+        }
+    }
+}
+
+function render() {
+    const leader = getLeader();
+    const status = leader && leader.status;
+    el('now-playing').textContent = fileName(status && status['file-uri']);
+    if (status) {
+        const time = expectedTimeMs(status);
+        el('scrubber').max = status['length-ms'];
+        if (!isScrubbing) {
+            el('scrubber').value = time;
+            el('time').textContent = formatTime(time);
+        }
+        el('length').textContent = formatTime(status['length-ms']);
+        el('play-pause').textContent = status.state === 'playing' ? 'Pause' : 'Play';
+    }
+    renderPlayers();
+}
+
+function renderPlayers() {
+    const container = el('players');
+    const cards = new Map([...container.children].map((card) => [card.dataset.id, card]));
+    for (const player of players) {
+        let card = cards.get(String(player.id));
+        if (!card) {
+            card = createCard(player.id);
+        }
+        cards.delete(String(player.id));
+        container.appendChild(card);
+        updateCard(card, player);
+    }
+    cards.forEach((card) => card.remove());
+}
+
+function createCard(id) {
+    const card = el('player-template').content.firstElementChild.cloneNode(true);
+    card.dataset.id = id;
+    const volume = card.querySelector('.volume input');
+    volume.addEventListener('input', () => {
+        card.querySelector('.volume-value').textContent = `${volume.value}%`;
+    });
+    volume.addEventListener('pointerdown', () => card.dataset.adjusting = 'true');
+    volume.addEventListener('change', async () => {
+        delete card.dataset.adjusting;
+        await request('POST', `/api/players/${id}/volume`, {volume: volume.value / 100}).catch(console.error);
+    });
+    card.querySelector('.leader-button').addEventListener('click', async () => {
+        await request('PUT', '/api/leader', {leader: leaderId === id ? 0 : id}).catch(console.error);
+        await refresh();
+    });
+    return card;
+}
+
+function updateCard(card, player) {
+    card.querySelector('.title').textContent = `Player ${player.slot + 1}`;
+    const badges = [];
+    if (player.leader) {
+        badges.push('<span class="badge leader">leader</span>');
+    }
+    if (player.attached) {
+        badges.push('<span class="badge">attached</span>');
+    }
+    card.querySelector('.badges').innerHTML = badges.join('');
+
+    const status = player.status;
+    card.querySelector('.status').textContent = status
+        ? `${status.state} ${formatTime(expectedTimeMs(status))} / ${formatTime(status['length-ms'])}` +
+          (status.rate !== 1 ? ` x${status.rate.toFixed(2)}` : '')
+        : 'connecting...';
+
+    const volume = card.querySelector('.volume input');
+    if (status && !card.dataset.adjusting) {
+        volume.value = Math.round(status.volume * 100);
+        card.querySelector('.volume-value').textContent = `${volume.value}%`;
+    }
+    card.querySelector('.leader-button').textContent = leaderId === player.id
+        ? 'Let all players control'
+        : 'Make the only controlling player';
+}
+
+const scrubber = el('scrubber');
+scrubber.addEventListener('input', () => {
+    isScrubbing = true;
+    el('time').textContent = formatTime(Number(scrubber.value));
+});
+scrubber.addEventListener('change', async () => {
+    await seek(Number(scrubber.value));
+    isScrubbing = false;
+});
+
+async function seek(timeMs) {
+    await request('POST', '/api/commands/seek', {'time-ms': Math.max(0, Math.round(timeMs))}).catch(console.error);
+    await refresh();
+}
+
+function skip(deltaMs) {
+    const leader = getLeader();
+    if (leader && leader.status) {
+        return seek(expectedTimeMs(leader.status) + deltaMs);
+    }
+}
+
+el('back').addEventListener('click', () => skip(-skipStep));
+el('forward').addEventListener('click', () => skip(skipStep));
+el('play-pause').addEventListener('click', async () => {
+    const leader = getLeader();
+    const isPlaying = leader && leader.status && leader.status.state === 'playing';
+    await request('POST', `/api/commands/${isPlaying ? 'pause' : 'resume'}`).catch(console.error);
+    await refresh();
+});
+
+// connectEvents refreshes the UI immediately when players are synced, launched or finished
+function connectEvents() {
+    const protocol = location.protocol === 'https:' ? 'wss:' : 'ws:';
+    const ws = new WebSocket(`${protocol}//${location.host}/api/events`);
+    ws.onmessage = (msg) => {
+        const event = JSON.parse(msg.data);
+        if (event.type !== 'update-skipped') {
+            refresh();
+        }
+    };
+    ws.onclose = () => {
+        if (refreshTimer !== null) {
+            setTimeout(connectEvents, reconnectInterval);
+        }
+    };
+}
+
+refresh();
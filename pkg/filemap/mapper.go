@@ -0,0 +1,140 @@
+package filemap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// File is a file of a FileSet
+type File struct {
+	Path      string
+	Transform TimeTransform
+}
+
+// FileSet is a set of files with the same content (e.g. different dubs) to open in different instance slots.
+// Files are indexed by slots
+type FileSet struct {
+	Files []File
+}
+
+// SlotRule finds a file for an instance slot automatically among sibling files by their names.
+// For example, if "Movie.eng.mkv" is opened in the slot with "eng" Suffix, the slot with "rus" Suffix
+// opens "Movie.rus.mkv" (or the sibling with "Movie.rus" name and another extension)
+type SlotRule struct {
+	Suffix    string
+	Transform TimeTransform
+}
+
+// Mapping is a file to open in the destination slot and the transform of the playback time to it
+type Mapping struct {
+	Path      string
+	Transform PairTransform
+}
+
+// Mapper finds files to open in different instance slots by explicit FileSets or by SlotRules
+type Mapper struct {
+	FileSets  []FileSet
+	SlotRules []SlotRule
+}
+
+var ignoredSiblingExtensions = []string{".srt", ".ass", ".ssa", ".sub", ".vtt", ".idx", ".nfo", ".txt", ".jpg", ".png"}
+
+// Map returns a file for the dstSlot corresponding to the srcPath file opened in the srcSlot.
+// Returns false if the same file should be opened
+func (m Mapper) Map(srcPath string, srcSlot, dstSlot int) (Mapping, bool) {
+	if srcSlot == dstSlot {
+		return Mapping{}, false
+	}
+	if mapping, ok := m.mapByFileSets(srcPath, dstSlot); ok {
+		return mapping, true
+	}
+	return m.mapBySlotRules(srcPath, srcSlot, dstSlot)
+}
+
+func (m Mapper) mapByFileSets(srcPath string, dstSlot int) (Mapping, bool) {
+	for _, fileSet := range m.FileSets {
+		srcIndex := slices.IndexFunc(fileSet.Files, func(file File) bool {
+			return isSamePath(file.Path, srcPath)
+		})
+		if srcIndex == -1 || dstSlot >= len(fileSet.Files) || fileSet.Files[dstSlot].Path == "" {
+			continue
+		}
+		srcFile := fileSet.Files[srcIndex]
+		dstFile := fileSet.Files[dstSlot]
+		if isSamePath(srcFile.Path, dstFile.Path) {
+			return Mapping{}, false
+		}
+		return Mapping{
+			Path: dstFile.Path,
+			Transform: PairTransform{
+				Src: srcFile.Transform,
+				Dst: dstFile.Transform,
+			},
+		}, true
+	}
+	return Mapping{}, false
+}
+
+func (m Mapper) mapBySlotRules(srcPath string, srcSlot, dstSlot int) (Mapping, bool) {
+	srcRule := m.getSlotRule(srcSlot)
+	dstRule := m.getSlotRule(dstSlot)
+	if srcRule.Suffix == dstRule.Suffix {
+		return Mapping{}, false
+	}
+
+	dir := filepath.Dir(srcPath)
+	srcName := strings.TrimSuffix(filepath.Base(srcPath), filepath.Ext(srcPath))
+	baseName := srcName
+	if srcRule.Suffix != "" {
+		var ok bool
+		if baseName, ok = cutSuffixFold(srcName, "."+srcRule.Suffix); !ok {
+			return Mapping{}, false
+		}
+	}
+	dstName := baseName
+	if dstRule.Suffix != "" {
+		dstName += "." + dstRule.Suffix
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return Mapping{}, false
+	}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || slices.Contains(ignoredSiblingExtensions, strings.ToLower(ext)) {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSuffix(entry.Name(), ext), dstName) {
+			return Mapping{
+				Path: filepath.Join(dir, entry.Name()),
+				Transform: PairTransform{
+					Src: srcRule.Transform,
+					Dst: dstRule.Transform,
+				},
+			}, true
+		}
+	}
+	return Mapping{}, false
+}
+
+func (m Mapper) getSlotRule(slot int) SlotRule {
+	if slot < len(m.SlotRules) {
+		return m.SlotRules[slot]
+	}
+	return SlotRule{}
+}
+
+func isSamePath(path1, path2 string) bool {
+	return filepath.Clean(path1) == filepath.Clean(path2)
+}
+
+func cutSuffixFold(str, suffix string) (string, bool) {
+	if len(str) < len(suffix) || !strings.EqualFold(str[len(str)-len(suffix):], suffix) {
+		return str, false
+	}
+	return str[:len(str)-len(suffix)], true
+}
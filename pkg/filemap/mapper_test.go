@@ -0,0 +1,115 @@
+package filemap
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapperFileSets(t *testing.T) {
+	t.Parallel()
+
+	transform := TimeTransform{Offset: -5 * time.Second}
+	mapper := Mapper{
+		FileSets: []FileSet{{
+			Files: []File{
+				{Path: "/movies/movie.eng.mkv"},
+				{Path: "/movies/movie.rus.mkv", Transform: transform},
+				{Path: ""},
+				{Path: "/movies/./movie.eng.mkv"},
+			},
+		}},
+	}
+
+	mapping, ok := mapper.Map("/movies/movie.eng.mkv", 0, 1)
+	require.True(t, ok)
+	require.Equal(t, "/movies/movie.rus.mkv", mapping.Path)
+	require.Equal(t, PairTransform{Dst: transform}, mapping.Transform)
+
+	// the file of any slot of the set can be opened
+	mapping, ok = mapper.Map("/movies/movie.rus.mkv", 1, 0)
+	require.True(t, ok)
+	require.Equal(t, "/movies/movie.eng.mkv", mapping.Path)
+	require.Equal(t, PairTransform{Src: transform}, mapping.Transform)
+
+	_, ok = mapper.Map("/movies/movie.eng.mkv", 0, 0)
+	require.False(t, ok, "same slot")
+	_, ok = mapper.Map("/movies/movie.eng.mkv", 0, 2)
+	require.False(t, ok, "empty path of the slot")
+	_, ok = mapper.Map("/movies/movie.eng.mkv", 0, 3)
+	require.False(t, ok, "same file in the slot")
+	_, ok = mapper.Map("/movies/movie.eng.mkv", 0, 4)
+	require.False(t, ok, "slot out of the set")
+	_, ok = mapper.Map("/movies/other.mkv", 0, 1)
+	require.False(t, ok, "file is not in the set")
+}
+
+func TestMapperSlotRules(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{
+		"Movie.ENG.mkv",
+		"Movie.rus.avi",
+		"Movie.rus.srt",
+		"Movie.ukr.srt",
+		"Movie.mkv",
+		"Other.eng.mkv",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0600))
+	}
+	engTransform := TimeTransform{Scale: 25 / 23.976}
+	mapper := Mapper{
+		SlotRules: []SlotRule{
+			{Suffix: "eng", Transform: engTransform},
+			{Suffix: "rus"},
+			{Suffix: "ukr"},
+			{},
+		},
+	}
+	srcPath := filepath.Join(dir, "Movie.ENG.mkv")
+
+	// the sibling with another extension is chosen, suffixes are case-insensitive
+	mapping, ok := mapper.Map(srcPath, 0, 1)
+	require.True(t, ok)
+	require.Equal(t, filepath.Join(dir, "Movie.rus.avi"), mapping.Path)
+	require.Equal(t, PairTransform{Src: engTransform}, mapping.Transform)
+
+	// the slots without a suffix (or a rule) open the file without a suffix
+	for _, dstSlot := range []int{3, 4} {
+		mapping, ok = mapper.Map(srcPath, 0, dstSlot)
+		require.True(t, ok)
+		require.Equal(t, filepath.Join(dir, "Movie.mkv"), mapping.Path)
+	}
+	mapping, ok = mapper.Map(filepath.Join(dir, "Movie.mkv"), 3, 0)
+	require.True(t, ok)
+	require.Equal(t, srcPath, mapping.Path)
+	require.Equal(t, PairTransform{Dst: engTransform}, mapping.Transform)
+
+	_, ok = mapper.Map(srcPath, 0, 2)
+	require.False(t, ok, "subtitles are not opened")
+	_, ok = mapper.Map(filepath.Join(dir, "Movie.rus.avi"), 0, 1)
+	require.False(t, ok, "the file doesn't have the suffix of its slot")
+	_, ok = mapper.Map(filepath.Join(dir, "Other.eng.mkv"), 0, 1)
+	require.False(t, ok, "no sibling")
+}
+
+func TestMapperPrefersFileSets(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"Movie.eng.mkv", "Movie.rus.mkv"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0600))
+	}
+	srcPath := filepath.Join(dir, "Movie.eng.mkv")
+	mapper := Mapper{
+		FileSets:  []FileSet{{Files: []File{{Path: srcPath}, {Path: "/movies/dub.mkv"}}}},
+		SlotRules: []SlotRule{{Suffix: "eng"}, {Suffix: "rus"}},
+	}
+	mapping, ok := mapper.Map(srcPath, 0, 1)
+	require.True(t, ok)
+	require.Equal(t, "/movies/dub.mkv", mapping.Path)
+}
@@ -0,0 +1,48 @@
+package filemap
+
+import "time"
+
+// TimeTransform is a linear transform of the playback time of a file to the time of a reference file
+// (the one other files are aligned to): ref = (time - Offset) * Scale.
+// For example, a file with a 5 seconds intro cut has Offset = -5s; a 25 fps encode of a 23.976 fps
+// reference has Scale = 25 / 23.976
+type TimeTransform struct {
+	Offset time.Duration
+	// Scale of 0 is treated as 1
+	Scale float64
+}
+
+func (t TimeTransform) IsIdentity() bool {
+	return t.Offset == 0 && t.getScale() == 1
+}
+
+func (t TimeTransform) ToReference(pbTime time.Duration) time.Duration {
+	return time.Duration(float64(pbTime-t.Offset) * t.getScale())
+}
+
+func (t TimeTransform) FromReference(refTime time.Duration) time.Duration {
+	return time.Duration(float64(refTime)/t.getScale()) + t.Offset
+}
+
+func (t TimeTransform) getScale() float64 {
+	if t.Scale == 0 {
+		return 1
+	}
+	return t.Scale
+}
+
+// PairTransform transforms the playback time of one file to the time of another file
+type PairTransform struct {
+	Src TimeTransform
+	Dst TimeTransform
+}
+
+func (p PairTransform) Apply(srcPbTime time.Duration) time.Duration {
+	return p.Dst.FromReference(p.Src.ToReference(srcPbTime))
+}
+
+// ApplyRate transforms the playback rate of the source file to the rate of the destination file
+// keeping them in sync
+func (p PairTransform) ApplyRate(srcRate float64) float64 {
+	return srcRate * p.Src.getScale() / p.Dst.getScale()
+}
@@ -0,0 +1,43 @@
+package filemap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeTransform(t *testing.T) {
+	t.Parallel()
+
+	identity := TimeTransform{}
+	require.True(t, identity.IsIdentity())
+	require.True(t, TimeTransform{Scale: 1}.IsIdentity())
+	require.Equal(t, time.Minute, identity.ToReference(time.Minute))
+	require.Equal(t, time.Minute, identity.FromReference(time.Minute))
+
+	// 5 seconds intro is cut
+	cut := TimeTransform{Offset: -5 * time.Second}
+	require.False(t, cut.IsIdentity())
+	require.Equal(t, 65*time.Second, cut.ToReference(time.Minute))
+	require.Equal(t, time.Minute, cut.FromReference(65*time.Second))
+
+	scaled := TimeTransform{Offset: 2 * time.Second, Scale: 2}
+	require.Equal(t, 116*time.Second, scaled.ToReference(time.Minute))
+	require.Equal(t, time.Minute, scaled.FromReference(116*time.Second))
+}
+
+func TestPairTransform(t *testing.T) {
+	t.Parallel()
+
+	pair := PairTransform{
+		Src: TimeTransform{Offset: -5 * time.Second},
+		Dst: TimeTransform{Offset: 10 * time.Second, Scale: 0.5},
+	}
+	// ref = 65s, dst = 65s / 0.5 + 10s
+	require.Equal(t, 140*time.Second, pair.Apply(time.Minute))
+	require.Equal(t, 3.0, pair.ApplyRate(1.5))
+
+	require.Equal(t, time.Minute, PairTransform{}.Apply(time.Minute))
+	require.Equal(t, 1.5, PairTransform{}.ApplyRate(1.5))
+}
@@ -0,0 +1,94 @@
+package mpris
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/godbus/dbus/v5"
+)
+
+var errNoFile = dbus.MakeFailedError(errors.New("no file opened"))
+
+// rootMethods implement org.mpris.MediaPlayer2 interface
+type rootMethods struct {
+	p *Player
+}
+
+func (m rootMethods) Raise() *dbus.Error {
+	return nil
+}
+
+func (m rootMethods) Quit() *dbus.Error {
+	return nil
+}
+
+// playerMethods implement org.mpris.MediaPlayer2.Player interface
+type playerMethods struct {
+	p *Player
+}
+
+func (m playerMethods) Next() *dbus.Error {
+	return nil
+}
+
+func (m playerMethods) Previous() *dbus.Error {
+	return nil
+}
+
+func (m playerMethods) Pause() *dbus.Error {
+	m.p.sendState(basic.PlaybackStatePaused)
+	return nil
+}
+
+func (m playerMethods) Play() *dbus.Error {
+	m.p.sendState(basic.PlaybackStatePlaying)
+	return nil
+}
+
+func (m playerMethods) PlayPause() *dbus.Error {
+	status, ok := m.p.controller.GetLeaderStatus()
+	if ok && status.State == basic.PlaybackStatePlaying {
+		m.p.sendState(basic.PlaybackStatePaused)
+	} else {
+		m.p.sendState(basic.PlaybackStatePlaying)
+	}
+	return nil
+}
+
+func (m playerMethods) Stop() *dbus.Error {
+	m.p.sendState(basic.PlaybackStateStopped)
+	return nil
+}
+
+// SeekBy is exported as Seek method. Seeks forward by offset microseconds (backward if negative)
+func (m playerMethods) SeekBy(offset int64) *dbus.Error {
+	status, ok := m.p.controller.GetLeaderStatus()
+	if !ok || status.LengthSec == 0 {
+		return errNoFile
+	}
+	m.p.seek(status, getExpectedPbTime(status, time.Now())+time.Duration(offset)*time.Microsecond)
+	return nil
+}
+
+// SetPosition seeks to position microseconds if trackID is the current track
+func (m playerMethods) SetPosition(trackID dbus.ObjectPath, position int64) *dbus.Error {
+	status, ok := m.p.controller.GetLeaderStatus()
+	if !ok || status.LengthSec == 0 {
+		return errNoFile
+	}
+	pbTime := time.Duration(position) * time.Microsecond
+	// ignored according to the spec
+	if trackID != getTrackID(status) || pbTime < 0 || pbTime > status.GetLength() {
+		return nil
+	}
+	m.p.seek(status, pbTime)
+	return nil
+}
+
+func (m playerMethods) OpenUri(uri string) *dbus.Error {
+	if err := m.p.controller.OpenFile(m.p.ctx, uri); err != nil {
+		return dbus.MakeFailedError(err)
+	}
+	return nil
+}
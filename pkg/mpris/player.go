@@ -0,0 +1,170 @@
+package mpris
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+)
+
+const (
+	BusName                             = "org.mpris.MediaPlayer2.vlcsyncplay"
+	ObjectPath          dbus.ObjectPath = "/org/mpris/MediaPlayer2"
+	RootInterface                       = "org.mpris.MediaPlayer2"
+	PlayerInterface                     = "org.mpris.MediaPlayer2.Player"
+	propertiesInterface                 = "org.freedesktop.DBus.Properties"
+	// seekDetectionThreshold is a difference between the expected and the actual position considered as a seek
+	seekDetectionThreshold = time.Second
+)
+
+var ErrNameTaken = errors.New("MPRIS bus name is already taken")
+
+// Controller is the group of synced players controlled over MPRIS
+type Controller interface {
+	// SendCommands sends commands to all players
+	SendCommands(ctx context.Context, commands extended.CmdGroup)
+	// OpenFile opens the file in all players
+	OpenFile(ctx context.Context, fileURI string) error
+	// GetLeaderStatus returns the last status of the player others are synced to
+	GetLeaderStatus() (basic.StatusEx, bool)
+}
+
+// Player publishes the group of players as a single MPRIS media player.
+// See: https://specifications.freedesktop.org/mpris-spec/latest/
+type Player struct {
+	ctx        context.Context
+	conn       *dbus.Conn
+	controller Controller
+	logger     logging.Logger
+
+	mu sync.Mutex
+	// emittedProps are the last values of the player properties reported in PropertiesChanged signal
+	emittedProps map[string]dbus.Variant
+	lastStatus   basic.StatusEx
+	lastRefresh  time.Time
+}
+
+// Export publishes the player on the bus. Commands are sent with ctx
+func Export(ctx context.Context, conn *dbus.Conn, controller Controller, logger logging.Logger) (*Player, error) {
+	p := &Player{
+		ctx:        ctx,
+		conn:       conn,
+		controller: controller,
+		logger:     logger,
+	}
+	p.emittedProps = p.getEmittedProps()
+
+	if err := conn.Export(rootMethods{p}, ObjectPath, RootInterface); err != nil {
+		return nil, err
+	}
+	// Seek name can't be used for Go method because of "go vet" stdmethods check
+	playerMethodsMapping := map[string]string{"SeekBy": "Seek"}
+	if err := conn.ExportWithMap(playerMethods{p}, playerMethodsMapping, ObjectPath, PlayerInterface); err != nil {
+		return nil, err
+	}
+	if err := conn.Export(properties{p}, ObjectPath, propertiesInterface); err != nil {
+		return nil, err
+	}
+	if err := conn.Export(introspect.NewIntrospectable(introspectNode), ObjectPath, introspect.IntrospectData.Name); err != nil {
+		return nil, err
+	}
+	reply, err := conn.RequestName(BusName, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting %s name: %w", BusName, err)
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		return nil, ErrNameTaken
+	}
+	return p, nil
+}
+
+// Close releases the bus name
+func (p *Player) Close() error {
+	_, err := p.conn.ReleaseName(BusName)
+	return err
+}
+
+// Refresh emits PropertiesChanged signal for the changed properties and Seeked signal if the leader
+// position has jumped. It should be called when the players are updated
+func (p *Player) Refresh() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	props := p.getEmittedProps()
+	changed := make(map[string]dbus.Variant)
+	for name, value := range props {
+		if prev, ok := p.emittedProps[name]; !ok || !reflect.DeepEqual(prev.Value(), value.Value()) {
+			changed[name] = value
+		}
+	}
+	p.emittedProps = props
+	if len(changed) > 0 {
+		p.emit(propertiesInterface+".PropertiesChanged", PlayerInterface, changed, []string{})
+	}
+
+	now := time.Now()
+	status, ok := p.controller.GetLeaderStatus()
+	if !ok {
+		return
+	}
+	pbTime := getExpectedPbTime(status, now)
+	if !p.lastRefresh.IsZero() && status.FileURI == p.lastStatus.FileURI {
+		expectedPbTime := getExpectedPbTime(p.lastStatus, now)
+		if (pbTime - expectedPbTime).Abs() > seekDetectionThreshold {
+			p.emitSeeked(pbTime)
+		}
+	}
+	p.lastStatus = status
+	p.lastRefresh = now
+}
+
+func (p *Player) emitSeeked(pbTime time.Duration) {
+	p.emit(PlayerInterface+".Seeked", pbTime.Microseconds())
+}
+
+func (p *Player) emit(name string, values ...any) {
+	if err := p.conn.Emit(ObjectPath, name, values...); err != nil {
+		p.logger.Err("error emitting %s: %s", name, err.Error())
+	}
+}
+
+// seek seeks all players to the playback time of the leader file
+func (p *Player) seek(status basic.StatusEx, pbTime time.Duration) {
+	length := status.GetLength()
+	pbTime = max(0, min(pbTime, length))
+	seekedAt := time.Now()
+	rate := 0.0
+	if status.State == basic.PlaybackStatePlaying {
+		rate = status.Rate
+	}
+	commands := extended.CmdGroup{}
+	commands.Seek.Set(func(atMoment time.Time) float64 {
+		expectedPbTime := float64(pbTime) + float64(atMoment.Sub(seekedAt))*rate
+		return max(0, min(expectedPbTime/float64(length), 1))
+	})
+	p.controller.SendCommands(p.ctx, commands)
+	p.emitSeeked(pbTime)
+}
+
+func (p *Player) sendState(state basic.PlaybackState) {
+	commands := extended.CmdGroup{}
+	commands.State.Set(state)
+	p.controller.SendCommands(p.ctx, commands)
+}
+
+// getExpectedPbTime returns playback time of the status extrapolated to the moment
+func getExpectedPbTime(status basic.StatusEx, at time.Time) time.Duration {
+	pbTime := status.GetPbTime()
+	if status.State == basic.PlaybackStatePlaying {
+		pbTime += time.Duration(float64(at.Sub(status.Moment.Center())) * status.Rate)
+	}
+	return max(0, min(pbTime, status.GetLength()))
+}
@@ -0,0 +1,243 @@
+package mpris
+
+import (
+	"bufio"
+	"context"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+const testFileURI = "file:///movies/Some%20Movie.mkv"
+
+type testController struct {
+	mu       sync.Mutex
+	status   basic.StatusEx
+	commands chan extended.CmdGroup
+	opened   chan string
+}
+
+func newTestController(status basic.StatusEx) *testController {
+	return &testController{
+		status:   status,
+		commands: make(chan extended.CmdGroup, 10),
+		opened:   make(chan string, 10),
+	}
+}
+
+func (c *testController) SendCommands(_ context.Context, commands extended.CmdGroup) {
+	c.commands <- commands
+}
+
+func (c *testController) OpenFile(_ context.Context, fileURI string) error {
+	c.opened <- fileURI
+	return nil
+}
+
+func (c *testController) GetLeaderStatus() (basic.StatusEx, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status, true
+}
+
+func (c *testController) setStatus(status basic.StatusEx) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status = status
+}
+
+func (c *testController) receive(t *testing.T) extended.CmdGroup {
+	select {
+	case commands := <-c.commands:
+		return commands
+	case <-time.After(3 * time.Second):
+		require.FailNow(t, "commands were not received")
+		return extended.CmdGroup{}
+	}
+}
+
+func newTestStatus(state basic.PlaybackState, position float64) basic.StatusEx {
+	now := time.Now()
+	return basic.StatusEx{
+		Status: basic.Status{
+			LengthSec: 100,
+			Rate:      1,
+			State:     state,
+			Position:  position,
+			Volume:    1,
+			Moment:    timeutil.Range{Min: now, Max: now},
+		},
+		FileURI: testFileURI,
+	}
+}
+
+// startPrivateBus starts a private dbus-daemon and returns its address
+func startPrivateBus(t *testing.T) string {
+	daemonPath, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon is not found")
+	}
+	cmd := exec.Command(daemonPath, "--session", "--nofork", "--print-address=1")
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	require.NoError(t, err)
+	return strings.TrimSpace(address)
+}
+
+func connect(t *testing.T, address string) *dbus.Conn {
+	conn, err := dbus.Connect(address)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+type testEnv struct {
+	controller *testController
+	player     *Player
+	client     *dbus.Conn
+	obj        dbus.BusObject
+}
+
+func newTestEnv(t *testing.T, status basic.StatusEx) *testEnv {
+	address := startPrivateBus(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	controller := newTestController(status)
+	player, err := Export(ctx, connect(t, address), controller, logging.NewNopLogger())
+	require.NoError(t, err)
+	client := connect(t, address)
+	return &testEnv{
+		controller: controller,
+		player:     player,
+		client:     client,
+		obj:        client.Object(BusName, ObjectPath),
+	}
+}
+
+func (e *testEnv) getProp(t *testing.T, name string) any {
+	variant, err := e.obj.GetProperty(PlayerInterface + "." + name)
+	require.NoError(t, err)
+	return variant.Value()
+}
+
+func TestExportTakesName(t *testing.T) {
+	env := newTestEnv(t, newTestStatus(basic.PlaybackStatePlaying, 0.5))
+
+	var names []string
+	require.NoError(t, env.client.BusObject().Call("org.freedesktop.DBus.ListNames", 0).Store(&names))
+	require.Contains(t, names, BusName)
+
+	identityVariant, err := env.obj.GetProperty(RootInterface + ".Identity")
+	require.NoError(t, err)
+	require.Equal(t, identity, identityVariant.Value())
+
+	_, err = Export(context.Background(), env.client, env.controller, logging.NewNopLogger())
+	require.ErrorIs(t, err, ErrNameTaken)
+}
+
+func TestPropertiesReflectLeaderStatus(t *testing.T) {
+	env := newTestEnv(t, newTestStatus(basic.PlaybackStatePaused, 0.5))
+
+	require.Equal(t, "Paused", env.getProp(t, "PlaybackStatus"))
+	require.Equal(t, 1.0, env.getProp(t, "Rate"))
+	require.Equal(t, int64(50*time.Second/time.Microsecond), env.getProp(t, "Position"))
+
+	metadata, ok := env.getProp(t, "Metadata").(map[string]dbus.Variant)
+	require.True(t, ok)
+	require.Equal(t, "Some Movie.mkv", metadata["xesam:title"].Value())
+	require.Equal(t, testFileURI, metadata["xesam:url"].Value())
+	require.Equal(t, int64(100*time.Second/time.Microsecond), metadata["mpris:length"].Value())
+}
+
+func TestPlayPauseSendsStateCommands(t *testing.T) {
+	env := newTestEnv(t, newTestStatus(basic.PlaybackStatePlaying, 0.5))
+
+	require.NoError(t, env.obj.Call(PlayerInterface+".PlayPause", 0).Err)
+	commands := env.controller.receive(t)
+	require.True(t, commands.State.HasValue)
+	require.Equal(t, basic.PlaybackStatePaused, commands.State.Value)
+
+	env.controller.setStatus(newTestStatus(basic.PlaybackStatePaused, 0.5))
+	require.NoError(t, env.obj.Call(PlayerInterface+".PlayPause", 0).Err)
+	commands = env.controller.receive(t)
+	require.Equal(t, basic.PlaybackStatePlaying, commands.State.Value)
+}
+
+func TestSeekCommands(t *testing.T) {
+	env := newTestEnv(t, newTestStatus(basic.PlaybackStatePaused, 0.5))
+
+	require.NoError(t, env.obj.Call(PlayerInterface+".Seek", 0, int64(10*time.Second/time.Microsecond)).Err)
+	commands := env.controller.receive(t)
+	require.True(t, commands.Seek.HasValue)
+	require.InDelta(t, 0.6, commands.Seek.Value(time.Now()), 0.001)
+
+	trackID := getTrackID(env.controller.status)
+	require.NoError(t, env.obj.Call(
+		PlayerInterface+".SetPosition", 0, trackID, int64(20*time.Second/time.Microsecond),
+	).Err)
+	commands = env.controller.receive(t)
+	require.InDelta(t, 0.2, commands.Seek.Value(time.Now()), 0.001)
+
+	// SetPosition for another track is ignored
+	require.NoError(t, env.obj.Call(
+		PlayerInterface+".SetPosition", 0, dbus.ObjectPath("/other/track"), int64(0),
+	).Err)
+	select {
+	case <-env.controller.commands:
+		require.FailNow(t, "SetPosition with another track ID should be ignored")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestSetRateSendsRateCommand(t *testing.T) {
+	env := newTestEnv(t, newTestStatus(basic.PlaybackStatePlaying, 0.5))
+
+	require.NoError(t, env.obj.SetProperty(PlayerInterface+".Rate", dbus.MakeVariant(1.5)))
+	commands := env.controller.receive(t)
+	require.True(t, commands.Rate.HasValue)
+	require.Equal(t, 1.5, commands.Rate.Value)
+}
+
+func TestRefreshEmitsPropertiesChanged(t *testing.T) {
+	env := newTestEnv(t, newTestStatus(basic.PlaybackStatePlaying, 0.5))
+
+	require.NoError(t, env.client.AddMatchSignal(
+		dbus.WithMatchObjectPath(ObjectPath),
+		dbus.WithMatchInterface(propertiesInterface),
+	))
+	signals := make(chan *dbus.Signal, 10)
+	env.client.Signal(signals)
+
+	env.controller.setStatus(newTestStatus(basic.PlaybackStatePaused, 0.5))
+	env.player.Refresh()
+
+	select {
+	case signal := <-signals:
+		require.Equal(t, propertiesInterface+".PropertiesChanged", signal.Name)
+		require.Equal(t, PlayerInterface, signal.Body[0])
+		changed, ok := signal.Body[1].(map[string]dbus.Variant)
+		require.True(t, ok)
+		require.Equal(t, "Paused", changed["PlaybackStatus"].Value())
+		require.NotContains(t, changed, "Metadata")
+	case <-time.After(3 * time.Second):
+		require.FailNow(t, "PropertiesChanged signal was not received")
+	}
+}
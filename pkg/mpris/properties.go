@@ -0,0 +1,238 @@
+package mpris
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+)
+
+const (
+	identity                    = "VLC Sync Play"
+	noTrackID   dbus.ObjectPath = "/org/mpris/MediaPlayer2/TrackList/NoTrack"
+	minimumRate                 = 0.25
+	maximumRate                 = 4.0
+)
+
+var errUnknownInterface = errors.New("unknown interface")
+
+// properties implement org.freedesktop.DBus.Properties interface
+type properties struct {
+	p *Player
+}
+
+func (pr properties) Get(iface string, name string) (dbus.Variant, *dbus.Error) {
+	props, err := pr.getInterfaceProps(iface)
+	if err != nil {
+		return dbus.Variant{}, err
+	}
+	value, ok := props[name]
+	if !ok {
+		return dbus.Variant{}, dbus.MakeFailedError(fmt.Errorf("unknown property %s", name))
+	}
+	return value, nil
+}
+
+func (pr properties) GetAll(iface string) (map[string]dbus.Variant, *dbus.Error) {
+	return pr.getInterfaceProps(iface)
+}
+
+func (pr properties) Set(iface string, name string, value dbus.Variant) *dbus.Error {
+	if iface != PlayerInterface || name != "Rate" {
+		return dbus.MakeFailedError(fmt.Errorf("property %s is read-only", name))
+	}
+	rate, ok := value.Value().(float64)
+	if !ok {
+		return dbus.MakeFailedError(errors.New("rate should be double"))
+	}
+	// rate 0 should be treated as pause according to the spec
+	commands := extended.CmdGroup{}
+	if rate == 0 {
+		commands.State.Set(basic.PlaybackStatePaused)
+	} else {
+		commands.Rate.Set(max(minimumRate, min(rate, maximumRate)))
+	}
+	pr.p.controller.SendCommands(pr.p.ctx, commands)
+	return nil
+}
+
+func (pr properties) getInterfaceProps(iface string) (map[string]dbus.Variant, *dbus.Error) {
+	switch iface {
+	case RootInterface:
+		return getRootProps(), nil
+	case PlayerInterface:
+		props := pr.p.getEmittedProps()
+		position := time.Duration(0)
+		if status, ok := pr.p.controller.GetLeaderStatus(); ok {
+			position = getExpectedPbTime(status, time.Now())
+		}
+		props["Position"] = dbus.MakeVariant(position.Microseconds())
+		props["MinimumRate"] = dbus.MakeVariant(minimumRate)
+		props["MaximumRate"] = dbus.MakeVariant(maximumRate)
+		props["CanGoNext"] = dbus.MakeVariant(false)
+		props["CanGoPrevious"] = dbus.MakeVariant(false)
+		props["CanControl"] = dbus.MakeVariant(true)
+		return props, nil
+	}
+	return nil, dbus.MakeFailedError(fmt.Errorf("%w: %s", errUnknownInterface, iface))
+}
+
+func getRootProps() map[string]dbus.Variant {
+	return map[string]dbus.Variant{
+		"CanQuit":             dbus.MakeVariant(false),
+		"CanRaise":            dbus.MakeVariant(false),
+		"HasTrackList":        dbus.MakeVariant(false),
+		"Identity":            dbus.MakeVariant(identity),
+		"SupportedUriSchemes": dbus.MakeVariant([]string{"file"}),
+		"SupportedMimeTypes":  dbus.MakeVariant([]string{}),
+	}
+}
+
+// getEmittedProps returns the player properties reported in PropertiesChanged signal
+func (p *Player) getEmittedProps() map[string]dbus.Variant {
+	status, ok := p.controller.GetLeaderStatus()
+	hasFile := ok && status.LengthSec > 0
+	playbackStatus := "Stopped"
+	rate := 1.0
+	volume := 1.0
+	if ok {
+		switch status.State {
+		case basic.PlaybackStatePlaying:
+			playbackStatus = "Playing"
+		case basic.PlaybackStatePaused:
+			playbackStatus = "Paused"
+		}
+		rate = status.Rate
+		volume = status.Volume
+	}
+	return map[string]dbus.Variant{
+		"PlaybackStatus": dbus.MakeVariant(playbackStatus),
+		"Metadata":       dbus.MakeVariant(getMetadata(status, ok)),
+		"Rate":           dbus.MakeVariant(rate),
+		"Volume":         dbus.MakeVariant(volume),
+		"CanPlay":        dbus.MakeVariant(hasFile),
+		"CanPause":       dbus.MakeVariant(hasFile),
+		"CanSeek":        dbus.MakeVariant(hasFile),
+	}
+}
+
+func getMetadata(status basic.StatusEx, ok bool) map[string]dbus.Variant {
+	if !ok || status.FileURI == "" {
+		return map[string]dbus.Variant{
+			"mpris:trackid": dbus.MakeVariant(noTrackID),
+		}
+	}
+	title := status.FileName
+	if title == "" {
+		title = path.Base(status.FileURI)
+		if unescaped, err := url.PathUnescape(title); err == nil {
+			title = unescaped
+		}
+	}
+	return map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(getTrackID(status)),
+		"mpris:length":  dbus.MakeVariant(status.GetLength().Microseconds()),
+		"xesam:title":   dbus.MakeVariant(title),
+		"xesam:url":     dbus.MakeVariant(status.FileURI),
+	}
+}
+
+// getTrackID returns an ID derived from the file URI
+func getTrackID(status basic.StatusEx) dbus.ObjectPath {
+	if status.FileURI == "" {
+		return noTrackID
+	}
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(status.FileURI))
+	return dbus.ObjectPath(fmt.Sprintf("/org/vlcsyncplay/track/%x", hash.Sum64()))
+}
+
+var introspectNode = &introspect.Node{
+	Name: string(ObjectPath),
+	Interfaces: []introspect.Interface{
+		introspect.IntrospectData,
+		{
+			Name: propertiesInterface,
+			Methods: []introspect.Method{
+				{Name: "Get", Args: []introspect.Arg{
+					{Name: "interface", Type: "s", Direction: "in"},
+					{Name: "property", Type: "s", Direction: "in"},
+					{Name: "value", Type: "v", Direction: "out"},
+				}},
+				{Name: "GetAll", Args: []introspect.Arg{
+					{Name: "interface", Type: "s", Direction: "in"},
+					{Name: "props", Type: "a{sv}", Direction: "out"},
+				}},
+				{Name: "Set", Args: []introspect.Arg{
+					{Name: "interface", Type: "s", Direction: "in"},
+					{Name: "property", Type: "s", Direction: "in"},
+					{Name: "value", Type: "v", Direction: "in"},
+				}},
+			},
+			Signals: []introspect.Signal{
+				{Name: "PropertiesChanged", Args: []introspect.Arg{
+					{Name: "interface", Type: "s"},
+					{Name: "changed_properties", Type: "a{sv}"},
+					{Name: "invalidated_properties", Type: "as"},
+				}},
+			},
+		},
+		{
+			Name: RootInterface,
+			Methods: []introspect.Method{
+				{Name: "Raise"},
+				{Name: "Quit"},
+			},
+			Properties: []introspect.Property{
+				{Name: "CanQuit", Type: "b", Access: "read"},
+				{Name: "CanRaise", Type: "b", Access: "read"},
+				{Name: "HasTrackList", Type: "b", Access: "read"},
+				{Name: "Identity", Type: "s", Access: "read"},
+				{Name: "SupportedUriSchemes", Type: "as", Access: "read"},
+				{Name: "SupportedMimeTypes", Type: "as", Access: "read"},
+			},
+		},
+		{
+			Name: PlayerInterface,
+			Methods: []introspect.Method{
+				{Name: "Next"},
+				{Name: "Previous"},
+				{Name: "Pause"},
+				{Name: "PlayPause"},
+				{Name: "Stop"},
+				{Name: "Play"},
+				{Name: "Seek", Args: []introspect.Arg{{Name: "Offset", Type: "x", Direction: "in"}}},
+				{Name: "SetPosition", Args: []introspect.Arg{
+					{Name: "TrackId", Type: "o", Direction: "in"},
+					{Name: "Position", Type: "x", Direction: "in"},
+				}},
+				{Name: "OpenUri", Args: []introspect.Arg{{Name: "Uri", Type: "s", Direction: "in"}}},
+			},
+			Signals: []introspect.Signal{
+				{Name: "Seeked", Args: []introspect.Arg{{Name: "Position", Type: "x"}}},
+			},
+			Properties: []introspect.Property{
+				{Name: "PlaybackStatus", Type: "s", Access: "read"},
+				{Name: "Rate", Type: "d", Access: "readwrite"},
+				{Name: "Metadata", Type: "a{sv}", Access: "read"},
+				{Name: "Volume", Type: "d", Access: "read"},
+				{Name: "Position", Type: "x", Access: "read"},
+				{Name: "MinimumRate", Type: "d", Access: "read"},
+				{Name: "MaximumRate", Type: "d", Access: "read"},
+				{Name: "CanGoNext", Type: "b", Access: "read"},
+				{Name: "CanGoPrevious", Type: "b", Access: "read"},
+				{Name: "CanPlay", Type: "b", Access: "read"},
+				{Name: "CanPause", Type: "b", Access: "read"},
+				{Name: "CanSeek", Type: "b", Access: "read"},
+				{Name: "CanControl", Type: "b", Access: "read"},
+			},
+		},
+	},
+}
@@ -0,0 +1,63 @@
+package peer
+
+import (
+	"sync"
+	"time"
+)
+
+// clockSamplesNumber is a number of the last samples to choose the most precise one from
+const clockSamplesNumber = 8
+
+type clockSample struct {
+	// offset is the remote clock minus the local clock
+	offset time.Duration
+	rtt    time.Duration
+}
+
+// clock estimates the offset of the remote peer clock NTP-style. The sample with the lowest round trip time
+// among the last ones is considered the most precise
+type clock struct {
+	mu      sync.RWMutex
+	samples []clockSample
+}
+
+// addSample adds a sample of a ping sent at localSent, received by the remote peer at remoteReceived,
+// replied at remoteReplied and received back at localReceived
+func (c *clock) addSample(localSent, remoteReceived, remoteReplied, localReceived time.Time) {
+	sample := clockSample{
+		offset: (remoteReceived.Sub(localSent) + remoteReplied.Sub(localReceived)) / 2,
+		rtt:    localReceived.Sub(localSent) - remoteReplied.Sub(remoteReceived),
+	}
+	if sample.rtt < 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.samples = append(c.samples, sample)
+	if len(c.samples) > clockSamplesNumber {
+		c.samples = c.samples[len(c.samples)-clockSamplesNumber:]
+	}
+}
+
+// getOffset returns the estimated offset of the remote clock. Returns false if there are no samples yet
+func (c *clock) getOffset() (offset time.Duration, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if len(c.samples) == 0 {
+		return 0, false
+	}
+	best := c.samples[0]
+	for _, sample := range c.samples[1:] {
+		if sample.rtt < best.rtt {
+			best = sample
+		}
+	}
+	return best.offset, true
+}
+
+func (c *clock) toLocal(remoteTime time.Time) time.Time {
+	offset, _ := c.getOffset()
+	return remoteTime.Add(-offset)
+}
@@ -0,0 +1,196 @@
+package peer
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+)
+
+const (
+	pingInterval     = 2 * time.Second
+	outQueueSize     = 64
+	maxMessageSize   = 64 * 1024
+	handshakeTimeout = 10 * time.Second
+)
+
+var ErrAuthFailed = errors.New("peer authentication failed")
+var ErrEmptySecret = errors.New("peer secret is required")
+
+// conn is a connection to a remote peer
+type conn struct {
+	netConn net.Conn
+	// isServer is true for connections accepted by the listening node
+	isServer     bool
+	isAuthorized atomic.Bool
+	clock        clock
+	out          chan message
+	logger       logging.Logger
+}
+
+func newConn(netConn net.Conn, isServer bool, logger logging.Logger) *conn {
+	return &conn{
+		netConn:  netConn,
+		isServer: isServer,
+		out:      make(chan message, outQueueSize),
+		logger:   logger.WithPrefix(fmt.Sprintf("Peer[%s]", netConn.RemoteAddr())),
+	}
+}
+
+// send queues the message. Messages are dropped if the peer doesn't keep up
+func (c *conn) send(msg message) {
+	select {
+	case c.out <- msg:
+	default:
+		c.logger.Err("Outgoing queue is full, dropping %s message", msg.Type)
+	}
+}
+
+// serve handles the connection until it's closed or ctx is done
+func (c *conn) serve(
+	ctx context.Context,
+	secret string,
+	onCommands func(c *conn, dto *commandsDto),
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		_ = c.netConn.Close()
+	}()
+	go c.writeLoop(ctx)
+
+	if !c.isServer {
+		c.send(message{Type: messageTypeHello, Secret: secret})
+	}
+	authTimer := time.AfterFunc(handshakeTimeout, func() {
+		if !c.isAuthorized.Load() {
+			c.logger.Err("Handshake timeout")
+			cancel()
+		}
+	})
+	defer authTimer.Stop()
+
+	scanner := bufio.NewScanner(c.netConn)
+	scanner.Buffer(make([]byte, 0, 4096), maxMessageSize)
+	for scanner.Scan() {
+		receivedAt := time.Now()
+		var msg message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			c.logger.Err("Invalid message: %s", err.Error())
+			continue
+		}
+		if err := c.onMessage(ctx, &msg, receivedAt, secret, onCommands); err != nil {
+			return err
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if !c.isAuthorized.Load() {
+		// the listening peer closes the connection if the secret is wrong
+		return ErrAuthFailed
+	}
+	return nil
+}
+
+func (c *conn) onMessage(
+	ctx context.Context,
+	msg *message,
+	receivedAt time.Time,
+	secret string,
+	onCommands func(c *conn, dto *commandsDto),
+) error {
+	if msg.Type == messageTypeHello {
+		if c.isServer {
+			if subtle.ConstantTimeCompare([]byte(msg.Secret), []byte(secret)) != 1 {
+				return ErrAuthFailed
+			}
+			c.send(message{Type: messageTypeHello})
+		}
+		c.logger.Info("Connected")
+		c.isAuthorized.Store(true)
+		go c.pingLoop(ctx)
+		return nil
+	}
+	if !c.isAuthorized.Load() {
+		if c.isServer {
+			return ErrAuthFailed
+		}
+		return nil
+	}
+
+	switch msg.Type {
+	case messageTypePing:
+		if msg.Ping != nil {
+			c.send(message{
+				Type: messageTypePong,
+				Ping: &pingDto{
+					Sent:     msg.Ping.Sent,
+					Received: receivedAt.UnixNano(),
+					Replied:  time.Now().UnixNano(),
+				},
+			})
+		}
+	case messageTypePong:
+		if msg.Ping != nil {
+			c.clock.addSample(
+				time.Unix(0, msg.Ping.Sent),
+				time.Unix(0, msg.Ping.Received),
+				time.Unix(0, msg.Ping.Replied),
+				receivedAt,
+			)
+		}
+	case messageTypeCommands:
+		if msg.Commands != nil {
+			onCommands(c, msg.Commands)
+		}
+	}
+	return nil
+}
+
+func (c *conn) pingLoop(ctx context.Context) {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		c.send(message{
+			Type: messageTypePing,
+			Ping: &pingDto{Sent: time.Now().UnixNano()},
+		})
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *conn) writeLoop(ctx context.Context) {
+	encoder := json.NewEncoder(c.netConn)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-c.out:
+			if msg.Ping != nil && msg.Type == messageTypePing {
+				// the closer to the actual sending the better
+				msg.Ping.Sent = time.Now().UnixNano()
+			}
+			if err := encoder.Encode(msg); err != nil {
+				c.logger.Err("Failed to send %s message: %s", msg.Type, err.Error())
+				_ = c.netConn.Close()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package peer
+
+import (
+	"time"
+
+	mathutil "github.com/cardinalby/vlc-sync-play/pkg/util/math"
+	typeutil "github.com/cardinalby/vlc-sync-play/pkg/util/type"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
+)
+
+type messageType string
+
+const (
+	messageTypeHello    messageType = "hello"
+	messageTypePing     messageType = "ping"
+	messageTypePong     messageType = "pong"
+	messageTypeCommands messageType = "commands"
+)
+
+// message is sent as a JSON line. Times are unix nanoseconds of the sender clock
+type message struct {
+	Type     messageType  `json:"type"`
+	Secret   string       `json:"secret,omitempty"`
+	Ping     *pingDto     `json:"ping,omitempty"`
+	Commands *commandsDto `json:"commands,omitempty"`
+}
+
+type pingDto struct {
+	Sent     int64 `json:"sent"`
+	Received int64 `json:"received,omitempty"`
+	Replied  int64 `json:"replied,omitempty"`
+}
+
+// commandsDto contains commands that make sense for remote players. OpenFile is the file URI,
+// peers open it by the same URI
+type commandsDto struct {
+	Seek     *seekDto             `json:"seek,omitempty"`
+	Rate     *float64             `json:"rate,omitempty"`
+	State    *basic.PlaybackState `json:"state,omitempty"`
+	OpenFile *string              `json:"open-file,omitempty"`
+}
+
+// seekDto is extended.ExpectedPositionGetter approximated by a linear function
+type seekDto struct {
+	Moment   int64   `json:"moment"`
+	Position float64 `json:"position"`
+	// Velocity is a change of the position per second
+	Velocity float64 `json:"velocity"`
+}
+
+// toCommandsDto returns false if there are no commands to send to remote peers
+func toCommandsDto(commands extended.CmdGroup, now time.Time) (*commandsDto, bool) {
+	dto := &commandsDto{}
+	if commands.Seek.HasValue {
+		position := commands.Seek.Value(now)
+		dto.Seek = &seekDto{
+			Moment:   now.UnixNano(),
+			Position: position,
+			Velocity: commands.Seek.Value(now.Add(time.Second)) - position,
+		}
+	}
+	if commands.Rate.HasValue {
+		dto.Rate = typeutil.Ptr(commands.Rate.Value)
+	}
+	if commands.State.HasValue {
+		dto.State = typeutil.Ptr(commands.State.Value)
+	}
+	if commands.OpenFile.HasValue {
+		dto.OpenFile = typeutil.Ptr(commands.OpenFile.Value)
+	}
+	return dto, dto.Seek != nil || dto.Rate != nil || dto.State != nil || dto.OpenFile != nil
+}
+
+func (dto *commandsDto) toCmdGroup(clock *clock) extended.CmdGroup {
+	var commands extended.CmdGroup
+	if dto.Seek != nil {
+		seek := *dto.Seek
+		localMoment := clock.toLocal(time.Unix(0, seek.Moment))
+		commands.Seek.Set(func(atMoment time.Time) float64 {
+			return mathutil.Clamp(seek.Position+seek.Velocity*atMoment.Sub(localMoment).Seconds(), 0, 1)
+		})
+	}
+	if dto.Rate != nil {
+		commands.Rate.Set(*dto.Rate)
+	}
+	if dto.State != nil {
+		commands.State.Set(*dto.State)
+	}
+	if dto.OpenFile != nil {
+		commands.OpenFile.Set(*dto.OpenFile)
+	}
+	return commands
+}
@@ -0,0 +1,165 @@
+package peer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
+)
+
+// Node syncs local players with remote vlc-sync-play peers. One node listens for connections of others
+// and relays commands between them, the others connect to it
+type Node struct {
+	secret     string
+	onCommands func(commands extended.CmdGroup)
+	mu         sync.RWMutex
+	conns      map[*conn]struct{}
+	logger     logging.Logger
+}
+
+// NewNode creates a node. Peers should have the same secret. onCommands is called with commands
+// received from remote peers, seek commands are converted to the local clock
+func NewNode(
+	secret string,
+	onCommands func(commands extended.CmdGroup),
+	logger logging.Logger,
+) *Node {
+	return &Node{
+		secret:     secret,
+		onCommands: onCommands,
+		conns:      make(map[*conn]struct{}),
+		logger:     logger,
+	}
+}
+
+// Listen accepts connections of peers on the address until ctx is done
+func (n *Node) Listen(ctx context.Context, address string) error {
+	if n.secret == "" {
+		return ErrEmptySecret
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	return n.Serve(ctx, listener)
+}
+
+// Serve accepts connections of peers on the listener until ctx is done
+func (n *Node) Serve(ctx context.Context, listener net.Listener) error {
+	if n.secret == "" {
+		_ = listener.Close()
+		return ErrEmptySecret
+	}
+	n.logger.Info("Listening for peers on %s", listener.Addr())
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+
+	wg := sync.WaitGroup{}
+	defer wg.Wait()
+	for {
+		netConn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := n.serveConn(ctx, newConn(netConn, true, n.logger)); err != nil {
+				n.logger.Err("Peer %s: %s", netConn.RemoteAddr(), err.Error())
+			}
+		}()
+	}
+}
+
+// Connect connects to the listening peer and serves the connection until it's closed or ctx is done
+func (n *Node) Connect(ctx context.Context, address string) error {
+	if n.secret == "" {
+		return ErrEmptySecret
+	}
+	dialer := net.Dialer{Timeout: handshakeTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	return n.serveConn(ctx, newConn(netConn, false, n.logger))
+}
+
+// ConnectWithRetries connects to the listening peer and reconnects after the interval
+// if the connection is lost, until ctx is done
+func (n *Node) ConnectWithRetries(ctx context.Context, address string, interval time.Duration) {
+	for ctx.Err() == nil {
+		if err := n.Connect(ctx, address); err != nil && ctx.Err() == nil {
+			n.logger.Err("Peer %s: %s", address, err.Error())
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Broadcast sends commands to all connected peers
+func (n *Node) Broadcast(commands extended.CmdGroup) {
+	n.broadcast(commands, nil)
+}
+
+// GetPeersNumber returns the number of connected peers
+func (n *Node) GetPeersNumber() int {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	res := 0
+	for c := range n.conns {
+		if c.isAuthorized.Load() {
+			res++
+		}
+	}
+	return res
+}
+
+func (n *Node) broadcast(commands extended.CmdGroup, except *conn) {
+	dto, ok := toCommandsDto(commands, time.Now())
+	if !ok {
+		return
+	}
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	for c := range n.conns {
+		if c != except && c.isAuthorized.Load() {
+			c.send(message{Type: messageTypeCommands, Commands: dto})
+		}
+	}
+}
+
+func (n *Node) serveConn(ctx context.Context, c *conn) error {
+	n.mu.Lock()
+	n.conns[c] = struct{}{}
+	n.mu.Unlock()
+	defer func() {
+		n.mu.Lock()
+		delete(n.conns, c)
+		n.mu.Unlock()
+		c.logger.Info("Disconnected")
+	}()
+
+	err := c.serve(ctx, n.secret, func(c *conn, dto *commandsDto) {
+		commands := dto.toCmdGroup(&c.clock)
+		if c.isServer {
+			// relay to other peers connected to this node
+			n.broadcast(commands, c)
+		}
+		n.onCommands(commands)
+	})
+	if errors.Is(err, net.ErrClosed) || errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
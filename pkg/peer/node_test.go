@@ -0,0 +1,148 @@
+package peer
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "secret"
+
+type testPeer struct {
+	node     *Node
+	commands chan extended.CmdGroup
+}
+
+func newTestPeer(secret string) *testPeer {
+	p := &testPeer{commands: make(chan extended.CmdGroup, 10)}
+	p.node = NewNode(secret, func(commands extended.CmdGroup) {
+		p.commands <- commands
+	}, logging.NewNopLogger())
+	return p
+}
+
+func (p *testPeer) receive(t *testing.T) extended.CmdGroup {
+	select {
+	case commands := <-p.commands:
+		return commands
+	case <-time.After(3 * time.Second):
+		require.FailNow(t, "commands were not received")
+		return extended.CmdGroup{}
+	}
+}
+
+func startServer(t *testing.T, ctx context.Context) (*testPeer, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := newTestPeer(testSecret)
+	go func() {
+		_ = server.node.Serve(ctx, listener)
+	}()
+	return server, listener.Addr().String()
+}
+
+func connectClient(t *testing.T, ctx context.Context, address string, secret string) *testPeer {
+	client := newTestPeer(secret)
+	go func() {
+		_ = client.node.Connect(ctx, address)
+	}()
+	return client
+}
+
+func TestNodeCommandsOnLoopback(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server, address := startServer(t, ctx)
+	client1 := connectClient(t, ctx, address, testSecret)
+	client2 := connectClient(t, ctx, address, testSecret)
+	require.Eventually(t, func() bool {
+		return server.node.GetPeersNumber() == 2 &&
+			client1.node.GetPeersNumber() == 1 &&
+			client2.node.GetPeersNumber() == 1
+	}, 3*time.Second, 10*time.Millisecond)
+
+	sentAt := time.Now()
+	commands := extended.CmdGroup{}
+	commands.Seek.Set(func(atMoment time.Time) float64 {
+		return 0.5 + atMoment.Sub(sentAt).Seconds()*0.001
+	})
+	commands.Rate.Set(1.5)
+	commands.State.Set(basic.PlaybackStatePaused)
+	commands.OpenFile.Set("file:///movie.mkv")
+	client1.node.Broadcast(commands)
+
+	for _, receiver := range []*testPeer{server, client2} {
+		received := receiver.receive(t)
+		require.Equal(t, "file:///movie.mkv", received.OpenFile.Value)
+		require.Equal(t, 1.5, received.Rate.Value)
+		require.Equal(t, basic.PlaybackStatePaused, received.State.Value)
+		require.True(t, received.Seek.HasValue)
+		checkAt := sentAt.Add(10 * time.Second)
+		// the clock offset on loopback is close to 0
+		require.InDelta(t, commands.Seek.Value(checkAt), received.Seek.Value(checkAt), 0.0001)
+	}
+
+	select {
+	case <-client1.commands:
+		require.Fail(t, "commands should not be sent back to the sender")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestNodeRejectsWrongSecret(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server, address := startServer(t, ctx)
+	client := newTestPeer("wrong")
+	err := client.node.Connect(ctx, address)
+	require.ErrorIs(t, err, ErrAuthFailed)
+	require.Equal(t, 0, server.node.GetPeersNumber())
+	require.Equal(t, 0, client.node.GetPeersNumber())
+}
+
+func TestNodeRejectsEmptySecret(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server, address := startServer(t, ctx)
+	node := newTestPeer("").node
+	require.ErrorIs(t, node.Connect(ctx, address), ErrEmptySecret)
+	require.ErrorIs(t, node.Listen(ctx, "127.0.0.1:0"), ErrEmptySecret)
+	require.Equal(t, 0, server.node.GetPeersNumber())
+}
+
+func TestClockOffset(t *testing.T) {
+	t.Parallel()
+
+	c := clock{}
+	_, ok := c.getOffset()
+	require.False(t, ok)
+
+	const offset = 3 * time.Second
+	localSent := time.Now()
+	addSample := func(forwardDelay, backDelay time.Duration) {
+		remoteReceived := localSent.Add(offset + forwardDelay)
+		remoteReplied := remoteReceived.Add(time.Millisecond)
+		c.addSample(localSent, remoteReceived, remoteReplied, remoteReplied.Add(-offset+backDelay))
+	}
+	// asymmetric delays of a congested sample are ignored in favor of the fastest one
+	addSample(200*time.Millisecond, 10*time.Millisecond)
+	addSample(5*time.Millisecond, 5*time.Millisecond)
+	addSample(50*time.Millisecond, 300*time.Millisecond)
+
+	estimated, ok := c.getOffset()
+	require.True(t, ok)
+	require.Equal(t, offset, estimated)
+	require.Equal(t, localSent, c.toLocal(localSent.Add(offset)))
+}
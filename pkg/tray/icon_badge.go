@@ -0,0 +1,90 @@
+package tray
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+)
+
+const (
+	// badgeRadiusRatio is the badge radius relative to the icon size
+	badgeRadiusRatio = 0.22
+	// badgeBorderRatio is the width of the transparent border around the badge relative to the icon size
+	badgeBorderRatio = 0.05
+	// badgeRingRatio is the width of the ring of a hollow badge relative to the icon size
+	badgeRingRatio = 0.07
+)
+
+// Badge is a circle drawn in the bottom right corner of an icon
+type Badge struct {
+	Color color.Color
+	// IsHollow makes the badge a ring. Shape is the only thing visible in template (monochrome) icons
+	IsHollow bool
+}
+
+// AddBadge draws the badge on the PNG icon and returns a new PNG icon
+func AddBadge(pngIcon []byte, badge Badge) ([]byte, error) {
+	src, err := png.Decode(bytes.NewReader(pngIcon))
+	if err != nil {
+		return nil, err
+	}
+	bounds := src.Bounds()
+	dst := image.NewNRGBA(bounds)
+	draw.Draw(dst, bounds, src, bounds.Min, draw.Src)
+
+	size := float64(min(bounds.Dx(), bounds.Dy()))
+	radius := size * badgeRadiusRatio
+	border := size * badgeBorderRatio
+	ring := size * badgeRingRatio
+	centerX := float64(bounds.Max.X) - radius - 0.5
+	centerY := float64(bounds.Max.Y) - radius - 0.5
+	r, g, b, _ := color.NRGBAModel.Convert(badge.Color).RGBA()
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			dist := math.Hypot(float64(x)-centerX, float64(y)-centerY)
+			if dist > radius+border+0.5 {
+				continue
+			}
+			// coverage of the pixel by the badge, with a 1px smooth edge
+			coverage := clamp01(radius + 0.5 - dist)
+			if badge.IsHollow {
+				coverage = min(coverage, clamp01(dist-(radius-ring)+0.5))
+			}
+			// the icon is cut out under the badge and its border
+			keep := clamp01(dist - radius - border + 0.5)
+			if badge.IsHollow {
+				keep = max(keep, clamp01(radius-ring-border-dist+0.5))
+			}
+			pixel := dst.NRGBAAt(x, y)
+			iconAlpha := float64(pixel.A) / 0xff * keep
+			alpha := coverage + iconAlpha*(1-coverage)
+			if alpha == 0 {
+				dst.SetNRGBA(x, y, color.NRGBA{})
+				continue
+			}
+			blend := func(badgeChannel uint32, iconChannel uint8) uint8 {
+				return uint8((float64(badgeChannel>>8)*coverage + float64(iconChannel)*iconAlpha*(1-coverage)) / alpha)
+			}
+			dst.SetNRGBA(x, y, color.NRGBA{
+				R: blend(r, pixel.R),
+				G: blend(g, pixel.G),
+				B: blend(b, pixel.B),
+				A: uint8(math.Round(alpha * 0xff)),
+			})
+		}
+	}
+
+	buf := bytes.Buffer{}
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func clamp01(v float64) float64 {
+	return max(0, min(1, v))
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package tray
+
+// ToPlatformIcon returns the PNG icon as is: it's supported by the tray on the platform
+func ToPlatformIcon(pngIcon []byte) []byte {
+	return pngIcon
+}
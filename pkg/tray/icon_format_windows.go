@@ -0,0 +1,37 @@
+//go:build windows
+
+package tray
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/png"
+)
+
+// ToPlatformIcon wraps the PNG icon into ICO container required by Windows tray
+func ToPlatformIcon(pngIcon []byte) []byte {
+	var width, height uint8
+	if cfg, err := png.DecodeConfig(bytes.NewReader(pngIcon)); err == nil {
+		// 0 means 256 or more pixels
+		if cfg.Width < 256 {
+			width = uint8(cfg.Width)
+		}
+		if cfg.Height < 256 {
+			height = uint8(cfg.Height)
+		}
+	}
+	const headerSize = 6
+	const entrySize = 16
+
+	buf := bytes.Buffer{}
+	// ICONDIR: reserved, type (1 is icon), images count
+	_ = binary.Write(&buf, binary.LittleEndian, [3]uint16{0, 1, 1})
+	// ICONDIRENTRY: width, height, colors count, reserved
+	buf.Write([]byte{width, height, 0, 0})
+	// color planes, bits per pixel
+	_ = binary.Write(&buf, binary.LittleEndian, [2]uint16{1, 32})
+	// image data size and offset
+	_ = binary.Write(&buf, binary.LittleEndian, [2]uint32{uint32(len(pngIcon)), headerSize + entrySize})
+	buf.Write(pngIcon)
+	return buf.Bytes()
+}
@@ -0,0 +1,42 @@
+package tray
+
+import (
+	"fyne.io/systray"
+)
+
+// ItemsPool is a fixed number of menu items representing a changing list. Unused items are hidden.
+// Items are created once because removing menu items is not supported on all platforms
+type ItemsPool struct {
+	items []*systray.MenuItem
+}
+
+// NewItemsPool adds size hidden items. setup is called for each item to add sub items and click handlers
+func NewItemsPool(
+	parent *systray.MenuItem,
+	size int,
+	setup func(index int, item *systray.MenuItem),
+) *ItemsPool {
+	addMenuItemFn := GetAddMenuItemFn(parent)
+	pool := &ItemsPool{}
+	for i := 0; i < size; i++ {
+		item := addMenuItemFn("", "")
+		item.Hide()
+		if setup != nil {
+			setup(i, item)
+		}
+		pool.items = append(pool.items, item)
+	}
+	return pool
+}
+
+// SetTitles shows an item for each title and hides the rest. Extra titles are ignored
+func (p *ItemsPool) SetTitles(titles []string) {
+	for i, item := range p.items {
+		if i < len(titles) {
+			item.SetTitle(titles[i])
+			item.Show()
+		} else {
+			item.Hide()
+		}
+	}
+}
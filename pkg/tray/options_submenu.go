@@ -29,19 +29,28 @@ func AddOptionsSubMenu[T constraints.Ordered](
 		slices.Sort(values)
 	}
 
-	var menuItems []*systray.MenuItem
+	var options []subMenuOption[T]
 	for _, v := range values {
 		v := v
 		menuItem := addOptionCheckboxFn(valFormatter(v), "", currValue == v)
-		menuItems = append(menuItems, menuItem)
+		options = append(options, subMenuOption[T]{menuItem: menuItem, value: v})
 		OnClicked(ctx, menuItem, func() {
 			setting.SetValue(v)
-			menuItem.Check()
-			for _, otherMenuItem := range menuItems {
-				if otherMenuItem != menuItem {
-					otherMenuItem.Uncheck()
-				}
-			}
 		})
 	}
+
+	// the setting can be changed not only from the menu
+	subscription := setting.Subscribe(func(value T) {
+		for _, option := range options {
+			if option.value == value {
+				option.menuItem.Check()
+			} else {
+				option.menuItem.Uncheck()
+			}
+		}
+	})
+	go func() {
+		<-ctx.Done()
+		subscription.Unsubscribe()
+	}()
 }
@@ -0,0 +1,37 @@
+package urlutil
+
+import (
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const fileScheme = "file"
+
+// ToFilePath converts "file://" URI or a local path to a local file path.
+// Returns false for URIs of other schemes
+func ToFilePath(uri string) (string, bool) {
+	if !strings.Contains(uri, "://") {
+		return uri, true
+	}
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != fileScheme {
+		return "", false
+	}
+	path := u.Path
+	if runtime.GOOS == "windows" {
+		// "/C:/dir/file" -> "C:/dir/file"
+		path = strings.TrimPrefix(path, "/")
+	}
+	return filepath.FromSlash(path), true
+}
+
+// FromFilePath converts a local file path to "file://" URI
+func FromFilePath(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: fileScheme, Path: path}).String()
+}
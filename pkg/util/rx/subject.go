@@ -0,0 +1,52 @@
+package rx
+
+import "sync"
+
+// Subject notifies subscribers about events. Unlike Value, it doesn't hold the last value
+type Subject[T any] struct {
+	mu   sync.RWMutex
+	subs map[*subjectSubscription[T]]struct{}
+}
+
+type subjectSubscription[T any] struct {
+	subject  *Subject[T]
+	callback func(value T)
+}
+
+func (s *subjectSubscription[T]) Unsubscribe() {
+	s.subject.mu.Lock()
+	defer s.subject.mu.Unlock()
+	delete(s.subject.subs, s)
+}
+
+func NewSubject[T any]() *Subject[T] {
+	return &Subject[T]{
+		subs: make(map[*subjectSubscription[T]]struct{}),
+	}
+}
+
+// Next calls subscribers with the value
+func (s *Subject[T]) Next(value T) {
+	s.mu.RLock()
+	subs := make([]*subjectSubscription[T], 0, len(s.subs))
+	for sub := range s.subs {
+		subs = append(subs, sub)
+	}
+	s.mu.RUnlock()
+
+	for _, sub := range subs {
+		sub.callback(value)
+	}
+}
+
+func (s *Subject[T]) Subscribe(callback func(value T)) Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub := &subjectSubscription[T]{
+		subject:  s,
+		callback: callback,
+	}
+	s.subs[sub] = struct{}{}
+	return sub
+}
@@ -5,8 +5,9 @@ import (
 	"time"
 )
 
-// SleepCtx sleeps for the specified duration (returns nil) or until the context is done (returns context error).
-func SleepCtx(ctx context.Context, d time.Duration) error {
+// SleepCtx sleeps for the specified duration of the clock (returns nil) or until the context is done
+// (returns context error).
+func SleepCtx(ctx context.Context, clock Clock, d time.Duration) error {
 	if d <= 0 {
 		select {
 		case <-ctx.Done():
@@ -17,12 +18,12 @@ func SleepCtx(ctx context.Context, d time.Duration) error {
 	}
 
 	// using time.After() can lead to resources leak since it can't be cancelled
-	timer := time.NewTimer(d)
+	timer := clock.NewTimer(d)
 	defer timer.Stop()
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
-	case <-timer.C:
+	case <-timer.C():
 		return nil
 	}
 }
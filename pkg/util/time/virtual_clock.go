@@ -0,0 +1,162 @@
+package timeutil
+
+import (
+	"sync"
+	"time"
+)
+
+// VirtualClock is Clock that moves only when Advance is called. Timers and tickers fire during Advance
+// in the order of their deadlines, the clock shows the deadline time while they fire
+type VirtualClock struct {
+	mu      sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	timers  []*virtualTimer
+}
+
+type virtualTimer struct {
+	clock *VirtualClock
+	c     chan time.Time
+	at    time.Time
+	// period is 0 for timers
+	period time.Duration
+}
+
+func NewVirtualClock(now time.Time) *VirtualClock {
+	clock := &VirtualClock{now: now}
+	clock.changed = sync.NewCond(&clock.mu)
+	return clock
+}
+
+func (c *VirtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *VirtualClock) NewTimer(d time.Duration) Timer {
+	return c.addTimer(d, 0)
+}
+
+func (c *VirtualClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for VirtualClock.NewTicker")
+	}
+	return virtualTicker{timer: c.addTimer(d, d)}
+}
+
+// Advance moves the clock forward firing the timers with deadlines up to the new time
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	target := c.now.Add(d)
+	for {
+		next := c.getNextTimer()
+		if next == nil || next.at.After(target) {
+			break
+		}
+		if next.at.After(c.now) {
+			c.now = next.at
+		}
+		next.fire()
+	}
+	c.now = target
+	c.changed.Broadcast()
+}
+
+// WaitForTimers blocks until there are at least n active timers and tickers. It's useful to make sure
+// that goroutines have started waiting before calling Advance
+func (c *VirtualClock) WaitForTimers(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.timers) < n {
+		c.changed.Wait()
+	}
+}
+
+// GetTimersNumber returns the number of active timers and tickers
+func (c *VirtualClock) GetTimersNumber() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+func (c *VirtualClock) addTimer(d time.Duration, period time.Duration) *virtualTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	timer := &virtualTimer{
+		clock:  c,
+		c:      make(chan time.Time, 1),
+		at:     c.now.Add(d),
+		period: period,
+	}
+	if d <= 0 {
+		timer.fire()
+	} else {
+		c.timers = append(c.timers, timer)
+		c.changed.Broadcast()
+	}
+	return timer
+}
+
+// getNextTimer returns the timer with the earliest deadline. Should be called with mu locked
+func (c *VirtualClock) getNextTimer() *virtualTimer {
+	var next *virtualTimer
+	for _, timer := range c.timers {
+		if next == nil || timer.at.Before(next.at) {
+			next = timer
+		}
+	}
+	return next
+}
+
+// removeTimer returns false if the timer is not active. Should be called with mu locked
+func (c *VirtualClock) removeTimer(timer *virtualTimer) bool {
+	for i, t := range c.timers {
+		if t == timer {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.changed.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+// fire sends the time to the channel and reschedules a ticker. Should be called with clock.mu locked
+func (t *virtualTimer) fire() {
+	// as in the time package, the tick is dropped if the previous one hasn't been received
+	select {
+	case t.c <- t.at:
+	default:
+	}
+	if t.period > 0 {
+		t.at = t.at.Add(t.period)
+	} else {
+		t.clock.removeTimer(t)
+	}
+}
+
+func (t *virtualTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *virtualTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.removeTimer(t)
+}
+
+type virtualTicker struct {
+	timer *virtualTimer
+}
+
+func (t virtualTicker) C() <-chan time.Time {
+	return t.timer.C()
+}
+
+func (t virtualTicker) Stop() {
+	t.timer.Stop()
+}
@@ -0,0 +1,91 @@
+package timeutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var virtualClockStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func requireFired(t *testing.T, c <-chan time.Time, expected time.Time) {
+	select {
+	case actual := <-c:
+		require.Equal(t, expected, actual)
+	default:
+		require.FailNow(t, "not fired")
+	}
+}
+
+func requireNotFired(t *testing.T, c <-chan time.Time) {
+	select {
+	case <-c:
+		require.FailNow(t, "fired")
+	default:
+	}
+}
+
+func TestVirtualClockTimer(t *testing.T) {
+	t.Parallel()
+
+	clock := NewVirtualClock(virtualClockStart)
+	timer := clock.NewTimer(time.Second)
+	clock.Advance(999 * time.Millisecond)
+	requireNotFired(t, timer.C())
+	clock.Advance(time.Hour)
+	requireFired(t, timer.C(), virtualClockStart.Add(time.Second))
+	require.Equal(t, virtualClockStart.Add(time.Hour+999*time.Millisecond), clock.Now())
+	require.False(t, timer.Stop())
+	require.Equal(t, 0, clock.GetTimersNumber())
+}
+
+func TestVirtualClockStoppedTimer(t *testing.T) {
+	t.Parallel()
+
+	clock := NewVirtualClock(virtualClockStart)
+	timer := clock.NewTimer(time.Second)
+	require.True(t, timer.Stop())
+	clock.Advance(time.Hour)
+	requireNotFired(t, timer.C())
+}
+
+func TestVirtualClockTicker(t *testing.T) {
+	t.Parallel()
+
+	clock := NewVirtualClock(virtualClockStart)
+	ticker := clock.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	clock.Advance(time.Second)
+	requireFired(t, ticker.C(), virtualClockStart.Add(time.Second))
+	// not received ticks are dropped
+	clock.Advance(3 * time.Second)
+	requireFired(t, ticker.C(), virtualClockStart.Add(2*time.Second))
+	requireNotFired(t, ticker.C())
+	clock.Advance(time.Second)
+	requireFired(t, ticker.C(), virtualClockStart.Add(5*time.Second))
+}
+
+func TestVirtualClockSleepCtx(t *testing.T) {
+	t.Parallel()
+
+	clock := NewVirtualClock(virtualClockStart)
+	slept := make(chan error)
+	go func() {
+		slept <- SleepCtx(context.Background(), clock, time.Minute)
+	}()
+	clock.WaitForTimers(1)
+	clock.Advance(time.Minute)
+	require.NoError(t, <-slept)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		slept <- SleepCtx(ctx, clock, time.Minute)
+	}()
+	clock.WaitForTimers(1)
+	cancel()
+	require.ErrorIs(t, <-slept, context.Canceled)
+	require.Equal(t, 0, clock.GetTimersNumber())
+}
@@ -2,61 +2,147 @@ package basic
 
 import (
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Key string
 
+// SubtitleTrackOff is a subtitles stream ID that disables subtitles
+const SubtitleTrackOff = -1
+
 const (
 	KeyCommand Key = "command"
 	KeyInput   Key = "input"
 	KeyVal     Key = "val"
 )
 
+// Command names. API clients of other protocols and players map them to their own commands
+const (
+	CmdNamePause         = "pl_forcepause"
+	CmdNameResume        = "pl_forceresume"
+	CmdNamePauseResume   = "pl_pause"
+	CmdNameStop          = "pl_stop"
+	CmdNameSeek          = "seek"
+	CmdNameRate          = "rate"
+	CmdNamePlayFile      = "in_play"
+	CmdNameAudioTrack    = "audio_track"
+	CmdNameSubtitleTrack = "subtitle_track"
+	CmdNameAddSubtitle   = "addsubtitle"
+	CmdNameVolume        = "volume"
+	CmdNameAudioDelay    = "audiodelay"
+)
+
+// VolumeScale is the VLC volume value corresponding to 100%
+const VolumeScale = 256
+
 // Command is a Command to send to the VLC API.
 // See: https://github.com/videolan/vlc/tree/master/share/lua/http/requests
 type Command map[Key]string
 
 func PauseCmd() Command {
 	return Command{
-		KeyCommand: "pl_forcepause",
+		KeyCommand: CmdNamePause,
 	}
 }
 
 func ResumeCmd() Command {
 	return Command{
-		KeyCommand: "pl_forceresume",
+		KeyCommand: CmdNameResume,
 	}
 }
 
 func PauseResumeCmd() Command {
 	return Command{
-		KeyCommand: "pl_pause",
+		KeyCommand: CmdNamePauseResume,
 	}
 }
 
 func StopCmd() Command {
 	return Command{
-		KeyCommand: "pl_stop",
+		KeyCommand: CmdNameStop,
 	}
 }
 
 func SeekCmd(position float64) Command {
 	return Command{
-		KeyCommand: "seek",
+		KeyCommand: CmdNameSeek,
 		KeyVal:     fmt.Sprintf("%f", position*100) + "%",
 	}
 }
 
 func RateCmd(rate float64) Command {
 	return Command{
-		KeyCommand: "rate",
+		KeyCommand: CmdNameRate,
 		KeyVal:     fmt.Sprintf("%f", rate),
 	}
 }
 
 func PlayFileCmd(input string) Command {
 	return Command{
-		KeyCommand: "in_play",
+		KeyCommand: CmdNamePlayFile,
 		KeyInput:   input,
 	}
 }
+
+func AudioTrackCmd(streamID int) Command {
+	return Command{
+		KeyCommand: CmdNameAudioTrack,
+		KeyVal:     strconv.Itoa(streamID),
+	}
+}
+
+// SubtitleTrackCmd selects subtitles stream. SubtitleTrackOff disables subtitles
+func SubtitleTrackCmd(streamID int) Command {
+	return Command{
+		KeyCommand: CmdNameSubtitleTrack,
+		KeyVal:     strconv.Itoa(streamID),
+	}
+}
+
+// AddSubtitleCmd loads and selects external subtitles file
+func AddSubtitleCmd(filePath string) Command {
+	return Command{
+		KeyCommand: CmdNameAddSubtitle,
+		KeyVal:     filePath,
+	}
+}
+
+// VolumeCmd sets the volume. 1 is 100%, VLC allows up to 2
+func VolumeCmd(volume float64) Command {
+	return Command{
+		KeyCommand: CmdNameVolume,
+		KeyVal:     strconv.Itoa(int(math.Round(volume * VolumeScale))),
+	}
+}
+
+// AudioDelayCmd sets audio delay of the current input. Positive values delay audio, negative ones advance it
+func AudioDelayCmd(delay time.Duration) Command {
+	return Command{
+		KeyCommand: CmdNameAudioDelay,
+		KeyVal:     fmt.Sprintf("%f", delay.Seconds()),
+	}
+}
+
+// GetName returns one of CmdName* values
+func (c Command) GetName() string {
+	return c[KeyCommand]
+}
+
+// GetSeekPosition returns the position (from 0 to 1) of SeekCmd
+func (c Command) GetSeekPosition() (float64, error) {
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(c[KeyVal], "%"), 64)
+	return percent / 100, err
+}
+
+// GetFloatVal returns the value of RateCmd and AudioDelayCmd (seconds)
+func (c Command) GetFloatVal() (float64, error) {
+	return strconv.ParseFloat(c[KeyVal], 64)
+}
+
+// GetIntVal returns the value of AudioTrackCmd, SubtitleTrackCmd and VolumeCmd (in VolumeScale units)
+func (c Command) GetIntVal() (int, error) {
+	return strconv.Atoi(c[KeyVal])
+}
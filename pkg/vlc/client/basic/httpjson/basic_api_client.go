@@ -9,8 +9,10 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/cardinalby/vlc-sync-play/pkg/util/arr"
 	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
 	osutil "github.com/cardinalby/vlc-sync-play/pkg/util/os"
 	rndutil "github.com/cardinalby/vlc-sync-play/pkg/util/rnd"
@@ -52,6 +54,12 @@ func NewLocalBasicApiClient(logger logging.Logger) (*BasicApiClient, error) {
 	return newBasicApiClient(connectionInfo, logger), nil
 }
 
+// NewRemoteBasicApiClient creates a client for VLC started by someone else with http interface enabled
+func NewRemoteBasicApiClient(connectionInfo ConnectionInfo, logger logging.Logger) *BasicApiClient {
+	logger.Info("Connection to remote VLC json api: %s", connectionInfo.GetAddress())
+	return newBasicApiClient(connectionInfo, logger)
+}
+
 func newBasicApiClient(
 	connectionInfo ConnectionInfo,
 	logger logging.Logger,
@@ -59,7 +67,7 @@ func newBasicApiClient(
 	//goland:noinspection HttpUrlsUsage
 	return &BasicApiClient{
 		connectionInfo: connectionInfo,
-		baseUrl:        fmt.Sprintf("http://%s:%d/requests/", connectionInfo.Host, connectionInfo.Port),
+		baseUrl:        fmt.Sprintf("http://%s/requests/", connectionInfo.GetAddress()),
 		authHeader:     "Basic " + base64.StdEncoding.EncodeToString([]byte(":"+connectionInfo.Password)),
 		logger:         logger,
 	}
@@ -118,8 +126,14 @@ func (apiClient *BasicApiClient) IsRecoverableErr(err error) bool {
 }
 
 func (apiClient *BasicApiClient) GetLaunchArgs() []string {
+	return apiClient.GetLaunchArgsWithIntf()
+}
+
+// GetLaunchArgsWithIntf returns launch args enabling the http interface along with other extra interfaces.
+// VLC takes only the last --extraintf arg, so they should be passed together
+func (apiClient *BasicApiClient) GetLaunchArgsWithIntf(extraIntfs ...string) []string {
 	return []string{
-		"--extraintf=http",
+		"--extraintf=" + strings.Join(append([]string{"http"}, extraIntfs...), ":"),
 		"--http-host",
 		apiClient.connectionInfo.Host,
 		"--http-port",
@@ -182,11 +196,21 @@ func sendApiRequest[T any](
 
 func toStatus(dto status_dto.Status, moment timeutil.Range) basic.Status {
 	return basic.Status{
-		Moment:    moment,
-		LengthSec: dto.LengthSec,
-		Rate:      dto.Rate,
-		State:     dto.State,
-		Position:  dto.Position,
-		FileName:  dto.GetFileName(),
+		Moment:     moment,
+		LengthSec:  dto.LengthSec,
+		Rate:       dto.Rate,
+		State:      dto.State,
+		Position:   dto.Position,
+		FileName:   dto.GetFileName(),
+		AudioDelay: time.Duration(dto.AudioDelay * float64(time.Second)),
+		Volume:     dto.Volume / basic.VolumeScale,
+		Streams: arr.Map(dto.GetStreams(), func(stream status_dto.Stream) basic.Stream {
+			return basic.Stream{
+				ID:       stream.ID,
+				Type:     basic.StreamType(stream.Type),
+				Language: stream.Language,
+				Codec:    stream.Codec,
+			}
+		}),
 	}
 }
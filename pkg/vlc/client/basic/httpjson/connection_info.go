@@ -1,15 +1,53 @@
 package httpjson
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"strconv"
+	"strings"
 )
 
+var ErrInvalidConnectionInfo = errors.New("invalid connection info")
+
 type ConnectionInfo struct {
 	Host     string
 	Port     int
 	Password string
 }
 
+// ParseConnectionInfo parses connection info in "password@host:port" format. Password is optional
+func ParseConnectionInfo(str string) (ConnectionInfo, error) {
+	var res ConnectionInfo
+	address := str
+	if atIndex := strings.LastIndex(str, "@"); atIndex != -1 {
+		res.Password = str[:atIndex]
+		address = str[atIndex+1:]
+	}
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return res, fmt.Errorf("%w '%s': %w", ErrInvalidConnectionInfo, str, err)
+	}
+	if res.Port, err = strconv.Atoi(portStr); err != nil || res.Port <= 0 || res.Port > 65535 {
+		return res, fmt.Errorf("%w '%s': invalid port", ErrInvalidConnectionInfo, str)
+	}
+	res.Host = host
+	return res, nil
+}
+
+// Format returns connection info in the format accepted by ParseConnectionInfo
+func (c ConnectionInfo) Format() string {
+	address := c.GetAddress()
+	if c.Password != "" {
+		return c.Password + "@" + address
+	}
+	return address
+}
+
+func (c ConnectionInfo) GetAddress() string {
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
 func (c ConnectionInfo) String() string {
 	return fmt.Sprintf("http://%s:%d\nPassword: %s", c.Host, c.Port, c.Password)
 }
@@ -0,0 +1,82 @@
+package status_dto
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// streamCategoryPrefix is a prefix of the category describing a stream ("Stream 0", "Stream 1", ...).
+// VLC translates category and field names to the interface language, only English names are supported
+const streamCategoryPrefix = "Stream "
+
+type Meta struct {
+	FileName string `json:"filename"`
+}
+
+type Stream struct {
+	ID       int
+	Type     string
+	Language string
+	Codec    string
+}
+
+// Category is the "information.category" object that contains "meta" and a category for each stream
+type Category struct {
+	Meta    Meta
+	Streams []Stream
+}
+
+func (c *Category) UnmarshalJSON(data []byte) error {
+	var categories map[string]json.RawMessage
+	if err := json.Unmarshal(data, &categories); err != nil {
+		// VLC returns an empty array instead of an object if there is no information
+		if len(data) > 0 && data[0] == '[' {
+			return nil
+		}
+		return err
+	}
+	for name, rawCategory := range categories {
+		if name == "meta" {
+			if err := json.Unmarshal(rawCategory, &c.Meta); err != nil {
+				return err
+			}
+			continue
+		}
+		if stream, ok := parseStream(name, rawCategory); ok {
+			c.Streams = append(c.Streams, stream)
+		}
+	}
+	slices.SortFunc(c.Streams, func(a, b Stream) int {
+		return a.ID - b.ID
+	})
+	return nil
+}
+
+func parseStream(categoryName string, rawCategory json.RawMessage) (Stream, bool) {
+	if !strings.HasPrefix(categoryName, streamCategoryPrefix) {
+		return Stream{}, false
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(categoryName, streamCategoryPrefix))
+	if err != nil {
+		return Stream{}, false
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(rawCategory, &fields); err != nil {
+		return Stream{}, false
+	}
+	getField := func(name string) string {
+		if value, ok := fields[name].(string); ok {
+			return value
+		}
+		return ""
+	}
+	return Stream{
+		ID:       id,
+		Type:     getField("Type"),
+		Language: getField("Language"),
+		Codec:    getField("Codec"),
+	}, true
+}
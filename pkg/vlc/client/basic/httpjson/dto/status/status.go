@@ -9,15 +9,17 @@ type Status struct {
 	Rate        float64             `json:"rate"`
 	State       basic.PlaybackState `json:"state"`
 	Position    float64             `json:"position"`
+	AudioDelay  float64             `json:"audiodelay"`
+	Volume      float64             `json:"volume"`
 	Information struct {
-		Category struct {
-			Meta struct {
-				FileName string `json:"filename"`
-			}
-		} `json:"category"`
+		Category Category `json:"category"`
 	} `json:"information"`
 }
 
 func (s Status) GetFileName() string {
 	return s.Information.Category.Meta.FileName
 }
+
+func (s Status) GetStreams() []Stream {
+	return s.Information.Category.Streams
+}
@@ -0,0 +1,29 @@
+package fake
+
+import (
+	"context"
+
+	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+)
+
+// clockApiClient sets moments of the statuses by the clock the player is driven by. Used with a virtual clock
+// since httpjson client measures moments with the real one
+type clockApiClient struct {
+	basic.ApiClient
+	clock timeutil.Clock
+}
+
+func (c clockApiClient) GetStatus(ctx context.Context) (basic.Status, error) {
+	requestedAt := c.clock.Now()
+	status, err := c.ApiClient.GetStatus(ctx)
+	status.Moment = timeutil.Range{Min: requestedAt, Max: c.clock.Now()}
+	return status, err
+}
+
+func (c clockApiClient) SendStatusCmd(ctx context.Context, cmd basic.Command) (basic.Status, error) {
+	requestedAt := c.clock.Now()
+	status, err := c.ApiClient.SendStatusCmd(ctx, cmd)
+	status.Moment = timeutil.Range{Min: requestedAt, Max: c.clock.Now()}
+	return status, err
+}
@@ -0,0 +1,72 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic/httpjson"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/instance"
+)
+
+var ErrAttachUnsupported = errors.New("fake launcher doesn't support attaching")
+
+// Launcher is instance.Launcher that launches fake players. Instances are controlled by
+// httpjson client via the fake Server. Pass the same Options.Clock to syncer.NewSyncer
+type Launcher struct {
+	options Options
+	logger  logging.Logger
+	mu      sync.Mutex
+	nextID  uint
+	servers []*Server
+}
+
+func NewLauncher(options Options, logger logging.Logger) *Launcher {
+	return &Launcher{
+		options: options,
+		logger:  logger,
+		nextID:  instance.IDNone + 1,
+	}
+}
+
+func (l *Launcher) Launch(_ context.Context, options instance.LaunchOptions) (*instance.Instance, error) {
+	server, err := NewServer(l.options)
+	if err != nil {
+		return nil, err
+	}
+	if options.FileURI.HasValue {
+		server.player.applyCommand(basic.CmdNamePlayFile, options.FileURI.Value, "")
+	}
+
+	l.mu.Lock()
+	id := l.nextID
+	l.nextID++
+	l.servers = append(l.servers, server)
+	l.mu.Unlock()
+
+	var api basic.ApiClient = httpjson.NewRemoteBasicApiClient(server.GetConnectionInfo(), l.logger)
+	if l.options.Clock != nil {
+		api = clockApiClient{ApiClient: api, clock: l.options.Clock}
+	}
+	return instance.NewExternalInstance(
+		id,
+		api,
+		server.Close,
+		server.Done(),
+		l.options.getClock(),
+		l.logger,
+	), nil
+}
+
+func (l *Launcher) Attach(context.Context, instance.AttachTarget, instance.LaunchOptions) (*instance.Instance, error) {
+	return nil, ErrAttachUnsupported
+}
+
+// GetServers returns servers of the launched players in the launch order
+func (l *Launcher) GetServers() []*Server {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]*Server(nil), l.servers...)
+}
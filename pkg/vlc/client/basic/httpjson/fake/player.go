@@ -0,0 +1,377 @@
+package fake
+
+import (
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
+	typeutil "github.com/cardinalby/vlc-sync-play/pkg/util/type"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	status_dto "github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic/httpjson/dto/status"
+	"golang.org/x/exp/slices"
+)
+
+const defaultFileLength = time.Hour
+
+// File is a media file the fake player can open
+type File struct {
+	URI    string
+	Length time.Duration
+	// Streams are reported in the status. ID is a stream index
+	Streams []status_dto.Stream
+}
+
+// PlayerStatus is a snapshot of the fake player state
+type PlayerStatus struct {
+	FileURI string
+	State   basic.PlaybackState
+	PbTime  time.Duration
+	Rate    float64
+	// Volume is 1 for 100%
+	Volume        float64
+	AudioDelay    time.Duration
+	AudioTrack    typeutil.Optional[int]
+	SubtitleTrack typeutil.Optional[int]
+	SubtitleFiles []string
+}
+
+type pendingAction struct {
+	at    time.Time
+	apply func()
+}
+
+// Player simulates VLC playback. API commands take time set in Options to open a file or to continue
+// the playback after a seek, user actions (Seek, Pause, ...) are applied immediately
+type Player struct {
+	mu      sync.Mutex
+	options Options
+	clock   timeutil.Clock
+	file    typeutil.Optional[File]
+	status  PlayerStatus
+	// pbTimeAt is the moment status.PbTime corresponds to
+	pbTimeAt time.Time
+	// seekingUntil is the moment the playback continues after a seek command
+	seekingUntil time.Time
+	playlist     []string
+	pending      []pendingAction
+	// drift is the fraction of the rate the playback is faster by
+	drift float64
+}
+
+func newPlayer(options Options) *Player {
+	clock := options.getClock()
+	return &Player{
+		options: options,
+		clock:   clock,
+		status: PlayerStatus{
+			State:  basic.PlaybackStateStopped,
+			Rate:   1,
+			Volume: 1,
+		},
+		pbTimeAt: clock.Now(),
+	}
+}
+
+// GetStatus returns the current state of the player
+func (p *Player) GetStatus() PlayerStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.advanceTo(p.clock.Now())
+	status := p.status
+	status.SubtitleFiles = append([]string(nil), p.status.SubtitleFiles...)
+	return status
+}
+
+// OpenFile opens the file as a user does in VLC
+func (p *Player) OpenFile(fileURI string) {
+	p.doNow(func() {
+		p.open(fileURI)
+	})
+}
+
+// Seek seeks to the playback time as a user does in VLC
+func (p *Player) Seek(pbTime time.Duration) {
+	p.doNow(func() {
+		p.seek(pbTime)
+	})
+}
+
+// Pause pauses the playback as a user does in VLC
+func (p *Player) Pause() {
+	p.doNow(func() {
+		p.setState(basic.PlaybackStatePaused)
+	})
+}
+
+// Resume resumes the playback as a user does in VLC
+func (p *Player) Resume() {
+	p.doNow(func() {
+		p.setState(basic.PlaybackStatePlaying)
+	})
+}
+
+// Stop stops the playback as a user does in VLC
+func (p *Player) Stop() {
+	p.doNow(func() {
+		p.setState(basic.PlaybackStateStopped)
+	})
+}
+
+// SetRate sets the playback rate as a user does in VLC
+func (p *Player) SetRate(rate float64) {
+	p.doNow(func() {
+		p.status.Rate = rate
+	})
+}
+
+// SetDrift makes the playback faster by the fraction of the rate (slower if negative) without reporting it,
+// as VLC with a drifting clock does
+func (p *Player) SetDrift(drift float64) {
+	p.doNow(func() {
+		p.drift = drift
+	})
+}
+
+func (p *Player) doNow(action func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.advanceTo(p.clock.Now())
+	action()
+}
+
+// applyCommand applies VLC HTTP API status.json command. Open is applied with a delay
+func (p *Player) applyCommand(command, input, val string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.clock.Now()
+	p.advanceTo(now)
+
+	switch command {
+	case basic.CmdNamePlayFile:
+		p.later(now.Add(p.options.OpenDelay), func() {
+			p.open(input)
+		})
+	case basic.CmdNamePauseResume:
+		if p.status.State == basic.PlaybackStatePlaying {
+			p.setState(basic.PlaybackStatePaused)
+		} else {
+			p.setState(basic.PlaybackStatePlaying)
+		}
+	case basic.CmdNamePause:
+		if p.status.State == basic.PlaybackStatePlaying {
+			p.setState(basic.PlaybackStatePaused)
+		}
+	case basic.CmdNameResume:
+		if p.status.State == basic.PlaybackStatePaused {
+			p.setState(basic.PlaybackStatePlaying)
+		}
+	case basic.CmdNameStop:
+		p.setState(basic.PlaybackStateStopped)
+	case basic.CmdNameSeek:
+		if pbTime, ok := p.parseSeekVal(val); ok {
+			p.seek(pbTime)
+			p.seekingUntil = now.Add(p.options.SeekDelay)
+		}
+	case basic.CmdNameRate:
+		if rate, err := strconv.ParseFloat(val, 64); err == nil && rate > 0 {
+			p.status.Rate = rate
+		}
+	case basic.CmdNameVolume:
+		if volume, err := strconv.Atoi(val); err == nil {
+			p.status.Volume = float64(volume) / basic.VolumeScale
+		}
+	case basic.CmdNameAudioDelay:
+		if delaySec, err := strconv.ParseFloat(val, 64); err == nil {
+			p.status.AudioDelay = time.Duration(delaySec * float64(time.Second))
+		}
+	case basic.CmdNameAudioTrack:
+		if streamID, err := strconv.Atoi(val); err == nil {
+			p.status.AudioTrack.Set(streamID)
+		}
+	case basic.CmdNameSubtitleTrack:
+		if streamID, err := strconv.Atoi(val); err == nil {
+			p.status.SubtitleTrack.Set(streamID)
+		}
+	case basic.CmdNameAddSubtitle:
+		p.status.SubtitleFiles = append(p.status.SubtitleFiles, val)
+	}
+}
+
+// parseSeekVal parses "50%", "+10", "-10" and "10" (seconds) seek values
+func (p *Player) parseSeekVal(val string) (time.Duration, bool) {
+	if !p.file.HasValue {
+		return 0, false
+	}
+	if percentStr, isPercent := strings.CutSuffix(val, "%"); isPercent {
+		percent, err := strconv.ParseFloat(percentStr, 64)
+		if err != nil {
+			return 0, false
+		}
+		return time.Duration(percent / 100 * float64(p.file.Value.Length)), true
+	}
+	seconds, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, false
+	}
+	pbTime := time.Duration(seconds * float64(time.Second))
+	if strings.HasPrefix(val, "+") || strings.HasPrefix(val, "-") {
+		pbTime += p.status.PbTime
+	}
+	return pbTime, true
+}
+
+func (p *Player) later(at time.Time, apply func()) {
+	if !at.After(p.pbTimeAt) {
+		apply()
+		return
+	}
+	// keep the actions ordered by time
+	i := len(p.pending)
+	for i > 0 && p.pending[i-1].at.After(at) {
+		i--
+	}
+	p.pending = slices.Insert(p.pending, i, pendingAction{at: at, apply: apply})
+}
+
+// advanceTo applies pending actions and advances the playback time to the moment
+func (p *Player) advanceTo(moment time.Time) {
+	for len(p.pending) > 0 && !p.pending[0].at.After(moment) {
+		action := p.pending[0]
+		p.pending = p.pending[1:]
+		p.advancePbTime(action.at)
+		action.apply()
+	}
+	p.advancePbTime(moment)
+}
+
+func (p *Player) advancePbTime(moment time.Time) {
+	if moment.Before(p.pbTimeAt) {
+		return
+	}
+	playingFrom := p.pbTimeAt
+	if p.seekingUntil.After(playingFrom) {
+		playingFrom = p.seekingUntil
+	}
+	if p.status.State == basic.PlaybackStatePlaying && moment.After(playingFrom) {
+		p.status.PbTime += time.Duration(float64(moment.Sub(playingFrom)) * p.status.Rate * (1 + p.drift))
+		if p.file.HasValue && p.status.PbTime >= p.file.Value.Length {
+			// VLC stops at the end of the playlist
+			p.status.PbTime = 0
+			p.status.State = basic.PlaybackStateStopped
+		}
+	}
+	p.pbTimeAt = moment
+}
+
+func (p *Player) open(fileURI string) {
+	file := File{URI: fileURI, Length: p.options.DefaultFileLength}
+	for _, knownFile := range p.options.Files {
+		if knownFile.URI == fileURI {
+			file = knownFile
+		}
+	}
+	if file.Length == 0 {
+		file.Length = defaultFileLength
+	}
+	p.file.Set(file)
+	p.playlist = append(p.playlist, fileURI)
+	p.status.FileURI = fileURI
+	p.status.State = basic.PlaybackStatePlaying
+	p.status.PbTime = 0
+	p.status.AudioTrack.Reset()
+	p.status.SubtitleTrack.Reset()
+	p.status.SubtitleFiles = nil
+}
+
+func (p *Player) seek(pbTime time.Duration) {
+	if !p.file.HasValue || p.status.State == basic.PlaybackStateStopped {
+		return
+	}
+	p.status.PbTime = min(max(pbTime, 0), p.file.Value.Length)
+}
+
+func (p *Player) setState(state basic.PlaybackState) {
+	if !p.file.HasValue {
+		return
+	}
+	if state == basic.PlaybackStateStopped {
+		p.status.PbTime = 0
+	}
+	p.status.State = state
+}
+
+// getStatusDto returns status.json response in VLC format
+func (p *Player) getStatusDto() map[string]any {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.advanceTo(p.clock.Now())
+	status := map[string]any{
+		"state":      p.status.State,
+		"rate":       p.status.Rate,
+		"volume":     p.status.Volume * basic.VolumeScale,
+		"audiodelay": p.status.AudioDelay.Seconds(),
+		"length":     0,
+		"position":   0,
+	}
+	if !p.file.HasValue {
+		return status
+	}
+	file := p.file.Value
+	status["length"] = int(file.Length.Seconds())
+	status["position"] = float64(p.status.PbTime) / float64(file.Length)
+	category := map[string]any{
+		"meta": map[string]any{
+			"filename": path.Base(file.URI),
+		},
+	}
+	for _, stream := range file.Streams {
+		category["Stream "+strconv.Itoa(stream.ID)] = map[string]any{
+			"Type":     stream.Type,
+			"Language": stream.Language,
+			"Codec":    stream.Codec,
+		}
+	}
+	status["information"] = map[string]any{
+		"category": category,
+	}
+	return status
+}
+
+// getPlaylistDto returns playlist.json response in VLC format
+func (p *Player) getPlaylistDto() map[string]any {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.advanceTo(p.clock.Now())
+	items := make([]map[string]any, 0, len(p.playlist))
+	for i, fileURI := range p.playlist {
+		item := map[string]any{
+			"type": "leaf",
+			"name": path.Base(fileURI),
+			"uri":  fileURI,
+		}
+		// the last opened item is the current one
+		if i == len(p.playlist)-1 {
+			item["current"] = "current"
+		}
+		items = append(items, item)
+	}
+	return map[string]any{
+		"type": "node",
+		"name": "Undefined",
+		"children": []map[string]any{
+			{
+				"type":     "node",
+				"name":     "Playlist",
+				"children": items,
+			},
+		},
+	}
+}
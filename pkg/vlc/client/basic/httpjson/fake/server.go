@@ -0,0 +1,171 @@
+package fake
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic/httpjson"
+)
+
+// Options configure the fake player and its HTTP API
+type Options struct {
+	// Files are files with known length and streams. Other files are opened with DefaultFileLength
+	Files []File
+	// DefaultFileLength is 1 hour if not set
+	DefaultFileLength time.Duration
+	// Latency is added to each request before and after the player status is taken
+	Latency time.Duration
+	// Jitter is the max random addition to each Latency
+	Jitter time.Duration
+	// SeekDelay is the time the playback stays at the position after a seek command (buffering).
+	// As in VLC, the new position is reported right away
+	SeekDelay time.Duration
+	// OpenDelay is the time between an open file command and the playback start
+	OpenDelay time.Duration
+	// Password is required by the API if set
+	Password string
+	// Clock drives the playback and the delays. Real clock is used if not set. With a virtual clock
+	// the statuses get moments of the clock
+	Clock timeutil.Clock
+}
+
+func (o Options) getClock() timeutil.Clock {
+	if o.Clock == nil {
+		return timeutil.RealClock
+	}
+	return o.Clock
+}
+
+// Server is an in-process fake of VLC HTTP JSON API (status.json and playlist.json) backed by the simulated Player.
+// See: https://github.com/videolan/vlc/tree/master/share/lua/http/requests
+type Server struct {
+	options    Options
+	player     *Player
+	listener   net.Listener
+	httpServer *http.Server
+	done       chan struct{}
+	closeOnce  sync.Once
+	rndMu      sync.Mutex
+	rnd        *rand.Rand
+}
+
+// NewServer starts the server on a free localhost port
+func NewServer(options Options) (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		options:  options,
+		player:   newPlayer(options),
+		listener: listener,
+		done:     make(chan struct{}),
+		rnd:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/requests/status.json", s.handleStatus)
+	mux.HandleFunc("/requests/playlist.json", s.handlePlaylist)
+	s.httpServer = &http.Server{Handler: mux}
+	go func() {
+		_ = s.httpServer.Serve(listener)
+	}()
+	return s, nil
+}
+
+// GetPlayer returns the simulated player to check its state and to simulate user actions
+func (s *Server) GetPlayer() *Player {
+	return s.player
+}
+
+// GetConnectionInfo returns the connection info for httpjson client
+func (s *Server) GetConnectionInfo() httpjson.ConnectionInfo {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return httpjson.ConnectionInfo{
+		Host:     addr.IP.String(),
+		Port:     addr.Port,
+		Password: s.options.Password,
+	}
+}
+
+// Close stops the server as if the player was closed
+func (s *Server) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		err = s.httpServer.Close()
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+		close(s.done)
+	})
+	return err
+}
+
+// Done is closed when the server is closed
+func (s *Server) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if !s.checkAuth(w, r) {
+		return
+	}
+	s.delay(r)
+	query := r.URL.Query()
+	if command := query.Get(string(basic.KeyCommand)); command != "" {
+		s.player.applyCommand(command, query.Get(string(basic.KeyInput)), query.Get(string(basic.KeyVal)))
+	}
+	s.writeJson(w, r, s.player.getStatusDto())
+}
+
+func (s *Server) handlePlaylist(w http.ResponseWriter, r *http.Request) {
+	if !s.checkAuth(w, r) {
+		return
+	}
+	s.delay(r)
+	s.writeJson(w, r, s.player.getPlaylistDto())
+}
+
+func (s *Server) checkAuth(w http.ResponseWriter, r *http.Request) bool {
+	if s.options.Password == "" {
+		return true
+	}
+	expected := "Basic " + base64.StdEncoding.EncodeToString([]byte(":"+s.options.Password))
+	if r.Header.Get("Authorization") != expected {
+		w.WriteHeader(http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+func (s *Server) writeJson(w http.ResponseWriter, r *http.Request, response any) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	// the response travels back to the client
+	s.delay(r)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	_, _ = w.Write(data)
+}
+
+// delay simulates one-way latency with jitter
+func (s *Server) delay(r *http.Request) {
+	d := s.options.Latency
+	if s.options.Jitter > 0 {
+		s.rndMu.Lock()
+		d += time.Duration(s.rnd.Int63n(int64(s.options.Jitter)))
+		s.rndMu.Unlock()
+	}
+	_ = timeutil.SleepCtx(r.Context(), s.options.getClock(), d)
+}
@@ -0,0 +1,111 @@
+package luapush
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	osutil "github.com/cardinalby/vlc-sync-play/pkg/util/os"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic/httpjson"
+)
+
+var ErrDisconnected = errors.New("disconnected from vlc_sync_play lua interface")
+
+type eventDto struct {
+	State    basic.PlaybackState `json:"state"`
+	Uri      string              `json:"uri"`
+	Position float64             `json:"position"`
+	Rate     float64             `json:"rate"`
+}
+
+// ApiClient is VLC HTTP JSON API client that also receives status change events pushed by
+// the bundled vlc_sync_play Lua interface script
+type ApiClient struct {
+	*httpjson.BasicApiClient
+	pushAddress string
+	logger      logging.Logger
+}
+
+// NewLocalApiClient creates a client for VLC to launch with the http interface and the push script.
+// Returns plain HTTP JSON API client if the script can't be installed
+func NewLocalApiClient(logger logging.Logger) (basic.ApiClient, error) {
+	httpClient, err := httpjson.NewLocalBasicApiClient(logger)
+	if err != nil {
+		return nil, err
+	}
+	if err := installScriptOnce(); err != nil {
+		logger.Err("Failed to install VLC lua script, status events are disabled: %s", err.Error())
+		return httpClient, nil
+	}
+	host, port, err := osutil.GetFreePort()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get free port: %w", err)
+	}
+	pushAddress := net.JoinHostPort(host, strconv.Itoa(port))
+	logger.Info("Connection to VLC status events: %s", pushAddress)
+	return &ApiClient{
+		BasicApiClient: httpClient,
+		pushAddress:    pushAddress,
+		logger:         logger,
+	}, nil
+}
+
+func (c *ApiClient) GetLaunchArgs() []string {
+	host, port, _ := net.SplitHostPort(c.pushAddress)
+	return append(
+		c.BasicApiClient.GetLaunchArgsWithIntf("luaintf"),
+		"--lua-intf="+scriptName,
+		fmt.Sprintf(`--lua-config=%s={host="%s",port=%s}`, scriptName, host, port),
+	)
+}
+
+func (c *ApiClient) ListenStatusEvents(ctx context.Context, events chan<- basic.StatusEvent) error {
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", c.pushAddress)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDisconnected, err)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+	})
+	defer func() {
+		stop()
+		_ = conn.Close()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		receivedAt := time.Now()
+		var dto eventDto
+		if err := json.Unmarshal(scanner.Bytes(), &dto); err != nil {
+			c.logger.Err("Invalid VLC status event '%s': %s", scanner.Text(), err.Error())
+			continue
+		}
+		event := basic.StatusEvent{
+			State:      dto.State,
+			FileURI:    dto.Uri,
+			Position:   dto.Position,
+			Rate:       dto.Rate,
+			ReceivedAt: receivedAt,
+		}
+		// events are hints to request the status, a pending one is enough
+		select {
+		case events <- event:
+		default:
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: %w", ErrDisconnected, err)
+	}
+	return ErrDisconnected
+}
@@ -0,0 +1,148 @@
+package luapush
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/stretchr/testify/require"
+)
+
+const testFileURI = "file:///movies/movie.mkv"
+
+// startFakeScript listens like vlc_sync_play.lua script does and passes accepted connections to the channel
+func startFakeScript(t *testing.T) (*ApiClient, <-chan net.Conn) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+	conns := make(chan net.Conn)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conns <- conn
+		}
+	}()
+	client := &ApiClient{
+		pushAddress: listener.Addr().String(),
+		logger:      logging.NewNopLogger(),
+	}
+	return client, conns
+}
+
+func listenStatusEvents(ctx context.Context, client *ApiClient) (<-chan basic.StatusEvent, <-chan error) {
+	events := make(chan basic.StatusEvent, 10)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- client.ListenStatusEvents(ctx, events)
+	}()
+	return events, errs
+}
+
+func receiveConn(t *testing.T, conns <-chan net.Conn) net.Conn {
+	select {
+	case conn := <-conns:
+		return conn
+	case <-time.After(time.Second):
+		require.Fail(t, "client didn't connect")
+		return nil
+	}
+}
+
+func receiveEvent(t *testing.T, events <-chan basic.StatusEvent) basic.StatusEvent {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		require.Fail(t, "event wasn't received")
+		return basic.StatusEvent{}
+	}
+}
+
+func receiveErr(t *testing.T, errs <-chan error) error {
+	select {
+	case err := <-errs:
+		return err
+	case <-time.After(time.Second):
+		require.Fail(t, "ListenStatusEvents didn't return")
+		return nil
+	}
+}
+
+func TestListenStatusEventsDecodesEvents(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, conns := startFakeScript(t)
+	events, errs := listenStatusEvents(ctx, client)
+	conn := receiveConn(t, conns)
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	sentAt := time.Now()
+	_, err := conn.Write([]byte(
+		`{"state":"playing","uri":"` + testFileURI + `","position":0.25,"rate":1.5}` + "\n" +
+			// invalid events are skipped
+			"{garbage\n" +
+			`{"state":"stopped","uri":"","position":0,"rate":1}` + "\n",
+	))
+	require.NoError(t, err)
+
+	event := receiveEvent(t, events)
+	require.False(t, event.ReceivedAt.Before(sentAt))
+	event.ReceivedAt = time.Time{}
+	require.Equal(t, basic.StatusEvent{
+		State:    basic.PlaybackStatePlaying,
+		FileURI:  testFileURI,
+		Position: 0.25,
+		Rate:     1.5,
+	}, event)
+
+	event = receiveEvent(t, events)
+	event.ReceivedAt = time.Time{}
+	require.Equal(t, basic.StatusEvent{State: basic.PlaybackStateStopped, Rate: 1}, event)
+
+	cancel()
+	require.ErrorIs(t, receiveErr(t, errs), context.Canceled)
+}
+
+func TestListenStatusEventsReconnects(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, conns := startFakeScript(t)
+	events, errs := listenStatusEvents(ctx, client)
+	conn := receiveConn(t, conns)
+	_, err := conn.Write([]byte(`{"state":"playing","uri":"` + testFileURI + `","position":0.25,"rate":1}` + "\n"))
+	require.NoError(t, err)
+	require.Equal(t, basic.PlaybackStatePlaying, receiveEvent(t, events).State)
+
+	// the script connection drops, e.g. VLC restarted the interface
+	require.NoError(t, conn.Close())
+	require.ErrorIs(t, receiveErr(t, errs), ErrDisconnected)
+
+	// the caller listens again on the new connection
+	events, errs = listenStatusEvents(ctx, client)
+	conn = receiveConn(t, conns)
+	defer func() {
+		_ = conn.Close()
+	}()
+	_, err = conn.Write([]byte(`{"state":"paused","uri":"` + testFileURI + `","position":0.5,"rate":1}` + "\n"))
+	require.NoError(t, err)
+	event := receiveEvent(t, events)
+	require.Equal(t, basic.PlaybackStatePaused, event.State)
+	require.Equal(t, 0.5, event.Position)
+
+	cancel()
+	require.ErrorIs(t, receiveErr(t, errs), context.Canceled)
+}
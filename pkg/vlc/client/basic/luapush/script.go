@@ -0,0 +1,30 @@
+package luapush
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// scriptName is the name of the Lua interface script, passed to VLC as --lua-intf
+const scriptName = "vlc_sync_play"
+
+//go:embed vlc_sync_play.lua
+var script []byte
+
+var installScriptOnce = sync.OnceValue(installScript)
+
+// installScript writes the script to the user Lua interfaces directory of VLC. VLC looks for --lua-intf
+// scripts only in its Lua directories
+func installScript() error {
+	dir, err := getUserIntfDir()
+	if err != nil {
+		return fmt.Errorf("can't get VLC user lua dir: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, scriptName+".lua"), script, 0644)
+}
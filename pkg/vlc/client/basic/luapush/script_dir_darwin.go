@@ -0,0 +1,14 @@
+package luapush
+
+import (
+	"os"
+	"path/filepath"
+)
+
+func getUserIntfDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, "Library", "Application Support", "org.videolan.vlc", "lua", "intf"), nil
+}
@@ -0,0 +1,20 @@
+//go:build !windows && !darwin
+
+package luapush
+
+import (
+	"os"
+	"path/filepath"
+)
+
+func getUserIntfDir() (string, error) {
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if dataDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataDir = filepath.Join(homeDir, ".local", "share")
+	}
+	return filepath.Join(dataDir, "vlc", "lua", "intf"), nil
+}
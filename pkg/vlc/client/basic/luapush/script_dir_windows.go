@@ -0,0 +1,15 @@
+package luapush
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+func getUserIntfDir() (string, error) {
+	appData := os.Getenv("APPDATA")
+	if appData == "" {
+		return "", errors.New("APPDATA is not set")
+	}
+	return filepath.Join(appData, "vlc", "lua", "intf"), nil
+}
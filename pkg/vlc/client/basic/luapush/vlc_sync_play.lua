@@ -0,0 +1,125 @@
+--[[
+vlc-sync-play status push interface.
+
+Listens on config.host:config.port and sends a JSON line with the playback status to connected clients
+when the state, the rate or the input changes or the playback time jumps (seek). The current status is
+sent to a client right after it connects.
+
+VLC 3 Lua interfaces can't subscribe to input variable callbacks, so the variables are checked every
+check_interval_ms inside VLC, which is much cheaper than polling the status over HTTP.
+
+Launched by vlc-sync-play with:
+  --extraintf=luaintf --lua-intf=vlc_sync_play --lua-config=vlc_sync_play={host="127.0.0.1",port=1234}
+--]]
+
+local host = config.host or "127.0.0.1"
+local port = config.port
+local check_interval_ms = 20
+-- playback time difference from the expected one (microseconds) considered a seek
+local seek_threshold_us = 300000
+
+if not port then
+    vlc.msg.err("vlc_sync_play: port is not set")
+    return
+end
+
+local listener = vlc.net.listen_tcp(host, port)
+local clients = {}
+local last = nil
+
+local function json_string(str)
+    return '"' .. string.gsub(str, '[%c"\\]', function(c)
+        return string.format("\\u%04x", string.byte(c))
+    end) .. '"'
+end
+
+local function get_status()
+    local status = {
+        state = vlc.playlist.status() or "stopped",
+        uri = "",
+        position = 0,
+        time = 0,
+        rate = 1,
+        at = vlc.misc.mdate(),
+    }
+    local input = vlc.object.input()
+    if input then
+        local item = vlc.input.item()
+        if item then
+            status.uri = item:uri() or ""
+        end
+        status.position = vlc.var.get(input, "position") or 0
+        status.time = vlc.var.get(input, "time") or 0
+        status.rate = vlc.var.get(input, "rate") or 1
+    end
+    return status
+end
+
+local function format_status(status)
+    return string.format(
+        '{"state":%s,"uri":%s,"position":%.6f,"time_us":%d,"rate":%.4f}\n',
+        json_string(status.state), json_string(status.uri), status.position, math.floor(status.time), status.rate
+    )
+end
+
+local function is_changed(prev, status)
+    if prev == nil or prev.state ~= status.state or prev.uri ~= status.uri or prev.rate ~= status.rate then
+        return true
+    end
+    local expected_time = prev.time
+    if status.state == "playing" then
+        expected_time = prev.time + (status.at - prev.at) * status.rate
+    end
+    return math.abs(status.time - expected_time) > seek_threshold_us
+end
+
+local function send(fd, line)
+    local sent = vlc.net.send(fd, line)
+    if sent == nil or sent < 0 then
+        vlc.net.close(fd)
+        clients[fd] = nil
+    end
+end
+
+while not vlc.misc.should_die() do
+    local pollfds = {}
+    for _, fd in ipairs({ listener:fds() }) do
+        pollfds[fd] = vlc.net.POLLIN
+    end
+    for fd in pairs(clients) do
+        pollfds[fd] = vlc.net.POLLIN
+    end
+    if vlc.net.poll(pollfds, check_interval_ms) > 0 then
+        for fd, revents in pairs(pollfds) do
+            if revents ~= 0 then
+                if clients[fd] then
+                    -- clients don't send anything, empty read means disconnect
+                    local data = vlc.net.recv(fd, 1000)
+                    if data == nil or data == "" then
+                        vlc.net.close(fd)
+                        clients[fd] = nil
+                    end
+                else
+                    local client = listener:accept()
+                    if client and client >= 0 then
+                        clients[client] = true
+                        send(client, format_status(get_status()))
+                    end
+                end
+            end
+        end
+    end
+
+    local status = get_status()
+    if is_changed(last, status) then
+        local line = format_status(status)
+        for fd in pairs(clients) do
+            send(fd, line)
+        end
+    end
+    last = status
+end
+
+for fd in pairs(clients) do
+    vlc.net.close(fd)
+end
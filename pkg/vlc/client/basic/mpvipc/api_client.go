@@ -0,0 +1,242 @@
+package mpvipc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	urlutil "github.com/cardinalby/vlc-sync-play/pkg/url"
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	rndutil "github.com/cardinalby/vlc-sync-play/pkg/util/rnd"
+	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+)
+
+var ErrNotConnected = errors.New("not connected to mpv")
+var ErrDisconnected = errors.New("disconnected from mpv")
+var ErrFormingRequest = errors.New("failed to form a request")
+var ErrParsingResponse = errors.New("failed to parse response")
+var ErrCommandFailed = errors.New("mpv command failed")
+var ErrUnsupportedCommand = errors.New("unsupported command")
+var ErrUnsupportedPlatform = errors.New("mpv IPC is not supported on the platform")
+
+const socketNameLength = 8
+
+// mpvVolumeScale is the mpv volume corresponding to 100%
+const mpvVolumeScale = 100
+
+// observedProperties are kept up to date by mpv property-change events. Playback time is requested on each
+// status request to know the moment it corresponds to
+var observedProperties = []string{
+	propPause, propSpeed, propIdleActive, propFilename, propPath, propDuration,
+	propTrackList, propAudioDelay, propVolume,
+}
+
+const (
+	propTimePos          = "time-pos"
+	propPause            = "pause"
+	propSpeed            = "speed"
+	propIdleActive       = "idle-active"
+	propFilename         = "filename"
+	propPath             = "path"
+	propDuration         = "duration"
+	propTrackList        = "track-list"
+	propAudioDelay       = "audio-delay"
+	propVolume           = "volume"
+	propWorkingDirectory = "working-directory"
+)
+
+// ApiClient is a client for mpv JSON IPC (--input-ipc-server).
+// See: https://mpv.io/manual/stable/#json-ipc
+type ApiClient struct {
+	socketPath string
+	logger     logging.Logger
+	connMu     sync.Mutex
+	conn       *connection
+	propsMu    sync.RWMutex
+	props      map[string]json.RawMessage
+	workingDir string
+}
+
+// NewLocalApiClient creates a client for mpv to launch with the IPC socket in the temp dir
+func NewLocalApiClient(logger logging.Logger) *ApiClient {
+	socketPath := filepath.Join(
+		os.TempDir(),
+		fmt.Sprintf("vlc-sync-play-mpv-%s.sock", rndutil.GeneratePassword(socketNameLength)),
+	)
+	logger.Info("Connection to mpv IPC: %s", socketPath)
+	return NewRemoteApiClient(socketPath, logger)
+}
+
+// NewRemoteApiClient creates a client for mpv started by someone else with --input-ipc-server=socketPath
+func NewRemoteApiClient(socketPath string, logger logging.Logger) *ApiClient {
+	return &ApiClient{
+		socketPath: socketPath,
+		logger:     logger,
+		props:      make(map[string]json.RawMessage),
+	}
+}
+
+func (c *ApiClient) GetStatus(ctx context.Context) (basic.Status, error) {
+	var moment timeutil.Range
+	moment.Min = time.Now()
+	timePosData, err := c.request(ctx, "get_property", propTimePos)
+	moment.Max = time.Now()
+	if err != nil && !errors.Is(err, ErrCommandFailed) {
+		return basic.Status{}, err
+	}
+	// time-pos is unavailable if no file is playing
+	var timePos float64
+	if err == nil {
+		if err := json.Unmarshal(timePosData, &timePos); err != nil {
+			return basic.Status{}, fmt.Errorf("%w: time-pos: %w", ErrParsingResponse, err)
+		}
+	}
+	return c.getStatus(timePos, moment), nil
+}
+
+func (c *ApiClient) SendStatusCmd(ctx context.Context, cmd basic.Command) (basic.Status, error) {
+	c.logger.Info("CMD %s %v", c.socketPath, cmd)
+	mpvCommand, err := c.toMpvCommand(cmd)
+	if err != nil {
+		return basic.Status{}, err
+	}
+	if _, err := c.request(ctx, mpvCommand...); err != nil {
+		if !errors.Is(err, ErrCommandFailed) {
+			return basic.Status{}, err
+		}
+		// VLC ignores inapplicable commands as well (e.g. seek without a file)
+		c.logger.Err("%s", err.Error())
+	}
+	return c.GetStatus(ctx)
+}
+
+func (c *ApiClient) GetCurrentFileUri(ctx context.Context) (string, error) {
+	path := c.getStringProp(propPath)
+	if path == "" {
+		return "", nil
+	}
+	if strings.Contains(path, "://") {
+		return path, nil
+	}
+	if !filepath.IsAbs(path) {
+		workingDir, err := c.getWorkingDir(ctx)
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(workingDir, path)
+	}
+	return urlutil.FromFilePath(path), nil
+}
+
+func (c *ApiClient) IsRecoverableErr(err error) bool {
+	return !errors.Is(err, ErrFormingRequest) &&
+		!errors.Is(err, ErrParsingResponse) &&
+		!errors.Is(err, ErrUnsupportedCommand) &&
+		!errors.Is(err, ErrUnsupportedPlatform)
+}
+
+func (c *ApiClient) GetLaunchArgs() []string {
+	return []string{"--input-ipc-server=" + c.socketPath}
+}
+
+func (c *ApiClient) request(ctx context.Context, command ...any) (json.RawMessage, error) {
+	conn, err := c.getConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return conn.request(ctx, command...)
+}
+
+// getConnection returns the connection, connecting if it's not established or has been closed.
+// Observed properties are subscribed on each connection
+func (c *ApiClient) getConnection(ctx context.Context) (*connection, error) {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+
+	if c.conn != nil && !c.conn.isClosed() {
+		return c.conn, nil
+	}
+	netConn, err := dial(ctx, c.socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotConnected, err)
+	}
+	c.propsMu.Lock()
+	clear(c.props)
+	c.propsMu.Unlock()
+
+	conn := newConnection(netConn, c.onPropertyChange)
+	for i, property := range observedProperties {
+		if _, err := conn.request(ctx, "observe_property", i+1, property); err != nil {
+			conn.close(err)
+			return nil, err
+		}
+	}
+	c.conn = conn
+	return conn, nil
+}
+
+func (c *ApiClient) onPropertyChange(name string, data json.RawMessage) {
+	c.propsMu.Lock()
+	defer c.propsMu.Unlock()
+
+	if len(data) == 0 || string(data) == "null" {
+		delete(c.props, name)
+	} else {
+		c.props[name] = data
+	}
+}
+
+func (c *ApiClient) getWorkingDir(ctx context.Context) (string, error) {
+	c.propsMu.RLock()
+	workingDir := c.workingDir
+	c.propsMu.RUnlock()
+	if workingDir != "" {
+		return workingDir, nil
+	}
+
+	data, err := c.request(ctx, "get_property", propWorkingDirectory)
+	if err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal(data, &workingDir); err != nil {
+		return "", fmt.Errorf("%w: working-directory: %w", ErrParsingResponse, err)
+	}
+	c.propsMu.Lock()
+	c.workingDir = workingDir
+	c.propsMu.Unlock()
+	return workingDir, nil
+}
+
+// getLengthSec returns the duration rounded up. Positions are fractions of it to keep playback time exact
+func (c *ApiClient) getLengthSec() int {
+	duration, _ := getProp[float64](c, propDuration)
+	return int(math.Ceil(duration))
+}
+
+func (c *ApiClient) getStringProp(name string) string {
+	value, _ := getProp[string](c, name)
+	return value
+}
+
+func getProp[T any](c *ApiClient, name string) (T, bool) {
+	c.propsMu.RLock()
+	data, ok := c.props[name]
+	c.propsMu.RUnlock()
+
+	var value T
+	if !ok {
+		return value, false
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, false
+	}
+	return value, true
+}
@@ -0,0 +1,214 @@
+package mpvipc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMpv responds to get_property requests with its properties and sends property-change events
+type fakeMpv struct {
+	netConn net.Conn
+	writeMu sync.Mutex
+	propsMu sync.Mutex
+	props   map[string]any
+}
+
+func (f *fakeMpv) serve() {
+	reader := bufio.NewReader(f.netConn)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			return
+		}
+		res := map[string]any{"request_id": req.RequestID, "error": responseSuccess}
+		if req.Command[0] == "get_property" {
+			f.propsMu.Lock()
+			value, ok := f.props[req.Command[1].(string)]
+			f.propsMu.Unlock()
+			if ok {
+				res["data"] = value
+			} else {
+				res["error"] = "property unavailable"
+			}
+		}
+		f.write(res)
+	}
+}
+
+func (f *fakeMpv) setProp(name string, value any) {
+	f.propsMu.Lock()
+	f.props[name] = value
+	f.propsMu.Unlock()
+}
+
+// changeProp sends property-change event of the observed property
+func (f *fakeMpv) changeProp(name string, value any) {
+	f.write(map[string]any{"event": "property-change", "id": 1, "name": name, "data": value})
+}
+
+func (f *fakeMpv) write(msg map[string]any) {
+	data, _ := json.Marshal(msg)
+	f.writeMu.Lock()
+	defer f.writeMu.Unlock()
+	_, _ = f.netConn.Write(append(data, '\n'))
+}
+
+func newTestApiClient(t *testing.T) (*ApiClient, *fakeMpv) {
+	clientConn, serverConn := net.Pipe()
+	mpv := &fakeMpv{
+		netConn: serverConn,
+		props:   make(map[string]any),
+	}
+	go mpv.serve()
+
+	client := NewRemoteApiClient("fake.sock", logging.NewNopLogger())
+	client.conn = newConnection(clientConn, client.onPropertyChange)
+	t.Cleanup(func() {
+		client.conn.close(nil)
+		_ = serverConn.Close()
+	})
+	return client, mpv
+}
+
+func TestApiClientGetStatus(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	client, mpv := newTestApiClient(t)
+
+	// idle without a file, time-pos is unavailable
+	mpv.changeProp(propIdleActive, true)
+	mpv.changeProp(propVolume, 100)
+	status, err := client.GetStatus(ctx)
+	require.NoError(t, err)
+	require.Equal(t, basic.PlaybackStateStopped, status.State)
+	require.Equal(t, 0, status.LengthSec)
+	require.Equal(t, 0.0, status.Position)
+	require.Equal(t, 1.0, status.Rate)
+	require.Equal(t, 1.0, status.Volume)
+	require.False(t, status.Moment.Min.IsZero())
+	require.False(t, status.Moment.Max.Before(status.Moment.Min))
+
+	// the file is opened, time-pos is null until it's loaded
+	mpv.setProp(propTimePos, nil)
+	mpv.changeProp(propIdleActive, false)
+	mpv.changeProp(propPause, false)
+	mpv.changeProp(propFilename, "movie.mkv")
+	mpv.changeProp(propDuration, 100.2)
+	status, err = client.GetStatus(ctx)
+	require.NoError(t, err)
+	require.Equal(t, basic.PlaybackStatePlaying, status.State)
+	require.Equal(t, "movie.mkv", status.FileName)
+	// rounded up to keep the playback time exact
+	require.Equal(t, 101, status.LengthSec)
+	require.Equal(t, 0.0, status.Position)
+
+	mpv.setProp(propTimePos, 50.5)
+	mpv.changeProp(propPause, true)
+	mpv.changeProp(propSpeed, 1.5)
+	mpv.changeProp(propVolume, 50)
+	mpv.changeProp(propAudioDelay, -0.25)
+	mpv.changeProp(propTrackList, []map[string]any{
+		{"id": 1, "type": "video", "codec": "h264"},
+		{"id": 1, "type": "audio", "lang": "eng", "codec": "aac"},
+		{"id": 2, "type": "sub", "lang": "rus"},
+		{"id": 3, "type": "unknown"},
+	})
+	status, err = client.GetStatus(ctx)
+	require.NoError(t, err)
+	require.Equal(t, basic.PlaybackStatePaused, status.State)
+	require.InDelta(t, 0.5, status.Position, 0.0001)
+	require.Equal(t, 50500*time.Millisecond, status.GetPbTime().Round(time.Millisecond))
+	require.Equal(t, 1.5, status.Rate)
+	require.Equal(t, 0.5, status.Volume)
+	require.Equal(t, -250*time.Millisecond, status.AudioDelay)
+	require.Equal(t, []basic.Stream{
+		{ID: 1, Type: basic.StreamTypeVideo, Codec: "h264"},
+		{ID: 1, Type: basic.StreamTypeAudio, Language: "eng", Codec: "aac"},
+		{ID: 2, Type: basic.StreamTypeSubtitle, Language: "rus"},
+	}, status.Streams)
+
+	// null values reset observed properties
+	mpv.changeProp(propDuration, nil)
+	mpv.changeProp(propSpeed, nil)
+	status, err = client.GetStatus(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, status.LengthSec)
+	require.Equal(t, 0.0, status.Position)
+	require.Equal(t, 1.0, status.Rate)
+}
+
+func TestApiClientGetCurrentFileUri(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	client, mpv := newTestApiClient(t)
+
+	uri, err := client.GetCurrentFileUri(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "", uri)
+
+	mpv.setProp(propWorkingDirectory, "/movies")
+	mpv.changeProp(propPath, "series/movie 1.mkv")
+	// the event is applied before the response to the request sent after it
+	_, err = client.GetStatus(ctx)
+	require.NoError(t, err)
+	uri, err = client.GetCurrentFileUri(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "file:///movies/series/movie%201.mkv", uri)
+
+	mpv.changeProp(propPath, "https://example.com/movie.mkv")
+	_, err = client.GetStatus(ctx)
+	require.NoError(t, err)
+	uri, err = client.GetCurrentFileUri(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/movie.mkv", uri)
+}
+
+func TestToMpvCommand(t *testing.T) {
+	t.Parallel()
+	client := NewRemoteApiClient("fake.sock", logging.NewNopLogger())
+	client.onPropertyChange(propDuration, json.RawMessage("99.5"))
+
+	tests := []struct {
+		cmd        basic.Command
+		mpvCommand []any
+	}{
+		{cmd: basic.PauseCmd(), mpvCommand: []any{"set_property", propPause, true}},
+		{cmd: basic.ResumeCmd(), mpvCommand: []any{"set_property", propPause, false}},
+		{cmd: basic.PauseResumeCmd(), mpvCommand: []any{"cycle", propPause}},
+		{cmd: basic.StopCmd(), mpvCommand: []any{"stop"}},
+		{cmd: basic.PlayFileCmd("file:///movies/movie.mkv"), mpvCommand: []any{
+			"loadfile", "file:///movies/movie.mkv", "replace",
+		}},
+		{cmd: basic.AddSubtitleCmd("/movies/movie.srt"), mpvCommand: []any{"sub-add", "/movies/movie.srt", "select"}},
+		{cmd: basic.SeekCmd(0.25), mpvCommand: []any{"seek", 25.0, "absolute+exact"}},
+		{cmd: basic.RateCmd(1.5), mpvCommand: []any{"set_property", propSpeed, 1.5}},
+		{cmd: basic.AudioDelayCmd(-250 * time.Millisecond), mpvCommand: []any{"set_property", propAudioDelay, -0.25}},
+		{cmd: basic.VolumeCmd(0.5), mpvCommand: []any{"set_property", propVolume, 50.0}},
+		{cmd: basic.AudioTrackCmd(2), mpvCommand: []any{"set_property", "aid", 2}},
+		{cmd: basic.SubtitleTrackCmd(3), mpvCommand: []any{"set_property", "sid", 3}},
+		{cmd: basic.SubtitleTrackCmd(basic.SubtitleTrackOff), mpvCommand: []any{"set_property", "sid", "no"}},
+	}
+	for _, test := range tests {
+		mpvCommand, err := client.toMpvCommand(test.cmd)
+		require.NoError(t, err, test.cmd.GetName())
+		require.Equal(t, test.mpvCommand, mpvCommand, test.cmd.GetName())
+	}
+
+	_, err := client.toMpvCommand(basic.Command{basic.KeyCommand: "unknown"})
+	require.ErrorIs(t, err, ErrUnsupportedCommand)
+	_, err = client.toMpvCommand(basic.Command{basic.KeyCommand: basic.CmdNameRate, basic.KeyVal: "fast"})
+	require.ErrorIs(t, err, ErrFormingRequest)
+}
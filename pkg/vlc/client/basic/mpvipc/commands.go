@@ -0,0 +1,66 @@
+package mpvipc
+
+import (
+	"fmt"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+)
+
+// toMpvCommand maps the command to mpv IPC command
+func (c *ApiClient) toMpvCommand(cmd basic.Command) ([]any, error) {
+	switch cmd.GetName() {
+	case basic.CmdNamePause:
+		return []any{"set_property", propPause, true}, nil
+	case basic.CmdNameResume:
+		return []any{"set_property", propPause, false}, nil
+	case basic.CmdNamePauseResume:
+		return []any{"cycle", propPause}, nil
+	case basic.CmdNameStop:
+		return []any{"stop"}, nil
+	case basic.CmdNamePlayFile:
+		return []any{"loadfile", cmd[basic.KeyInput], "replace"}, nil
+	case basic.CmdNameAddSubtitle:
+		return []any{"sub-add", cmd[basic.KeyVal], "select"}, nil
+	case basic.CmdNameSeek:
+		position, err := cmd.GetSeekPosition()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFormingRequest, err)
+		}
+		return []any{"seek", position * float64(c.getLengthSec()), "absolute+exact"}, nil
+	case basic.CmdNameRate:
+		rate, err := cmd.GetFloatVal()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFormingRequest, err)
+		}
+		return []any{"set_property", propSpeed, rate}, nil
+	case basic.CmdNameAudioDelay:
+		delaySec, err := cmd.GetFloatVal()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFormingRequest, err)
+		}
+		return []any{"set_property", propAudioDelay, delaySec}, nil
+	case basic.CmdNameVolume:
+		volume, err := cmd.GetIntVal()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFormingRequest, err)
+		}
+		return []any{"set_property", propVolume, float64(volume) / basic.VolumeScale * mpvVolumeScale}, nil
+	case basic.CmdNameAudioTrack:
+		streamID, err := cmd.GetIntVal()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFormingRequest, err)
+		}
+		return []any{"set_property", "aid", streamID}, nil
+	case basic.CmdNameSubtitleTrack:
+		streamID, err := cmd.GetIntVal()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFormingRequest, err)
+		}
+		if streamID == basic.SubtitleTrackOff {
+			return []any{"set_property", "sid", "no"}, nil
+		}
+		return []any{"set_property", "sid", streamID}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCommand, cmd.GetName())
+	}
+}
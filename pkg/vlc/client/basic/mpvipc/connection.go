@@ -0,0 +1,141 @@
+package mpvipc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+)
+
+const responseSuccess = "success"
+
+type request struct {
+	Command   []any `json:"command"`
+	RequestID int64 `json:"request_id"`
+}
+
+// message is a response to a request or an event
+type message struct {
+	RequestID *int64          `json:"request_id"`
+	Error     string          `json:"error"`
+	Data      json.RawMessage `json:"data"`
+	Event     string          `json:"event"`
+	Name      string          `json:"name"`
+}
+
+// connection is a JSON IPC connection to mpv. Responses are matched to requests by request_id,
+// property-change events are passed to onPropertyChange
+type connection struct {
+	netConn          net.Conn
+	writeMu          sync.Mutex
+	pendingMu        sync.Mutex
+	pending          map[int64]chan message
+	nextRequestID    int64
+	closed           chan struct{}
+	closeErr         error
+	onPropertyChange func(name string, data json.RawMessage)
+}
+
+func newConnection(netConn net.Conn, onPropertyChange func(name string, data json.RawMessage)) *connection {
+	conn := &connection{
+		netConn:          netConn,
+		pending:          make(map[int64]chan message),
+		nextRequestID:    1,
+		closed:           make(chan struct{}),
+		onPropertyChange: onPropertyChange,
+	}
+	go conn.readLoop()
+	return conn
+}
+
+func (c *connection) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *connection) close(err error) {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+	if c.isClosed() {
+		return
+	}
+	c.closeErr = err
+	close(c.closed)
+	_ = c.netConn.Close()
+}
+
+// request sends the command and waits for the response. Returns ErrCommandFailed if mpv reports an error
+func (c *connection) request(ctx context.Context, command ...any) (json.RawMessage, error) {
+	c.pendingMu.Lock()
+	if c.isClosed() {
+		c.pendingMu.Unlock()
+		return nil, fmt.Errorf("%w: %w", ErrDisconnected, c.closeErr)
+	}
+	requestID := c.nextRequestID
+	c.nextRequestID++
+	responseCh := make(chan message, 1)
+	c.pending[requestID] = responseCh
+	c.pendingMu.Unlock()
+
+	defer func() {
+		c.pendingMu.Lock()
+		delete(c.pending, requestID)
+		c.pendingMu.Unlock()
+	}()
+
+	data, err := json.Marshal(request{Command: command, RequestID: requestID})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFormingRequest, err)
+	}
+	c.writeMu.Lock()
+	_, err = c.netConn.Write(append(data, '\n'))
+	c.writeMu.Unlock()
+	if err != nil {
+		c.close(err)
+		return nil, fmt.Errorf("%w: %w", ErrDisconnected, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.closed:
+		return nil, fmt.Errorf("%w: %w", ErrDisconnected, c.closeErr)
+	case response := <-responseCh:
+		if response.Error != responseSuccess {
+			return nil, fmt.Errorf("%w: %v: %s", ErrCommandFailed, command, response.Error)
+		}
+		return response.Data, nil
+	}
+}
+
+func (c *connection) readLoop() {
+	reader := bufio.NewReader(c.netConn)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			c.close(err)
+			return
+		}
+		var msg message
+		if err := json.Unmarshal(line, &msg); err != nil {
+			// skip malformed lines, they don't break the protocol
+			continue
+		}
+		switch {
+		case msg.Event == "property-change":
+			c.onPropertyChange(msg.Name, msg.Data)
+		case msg.Event == "" && msg.RequestID != nil:
+			c.pendingMu.Lock()
+			if responseCh, ok := c.pending[*msg.RequestID]; ok {
+				responseCh <- msg
+			}
+			c.pendingMu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package mpvipc
+
+import (
+	"context"
+	"net"
+)
+
+func dial(ctx context.Context, socketPath string) (net.Conn, error) {
+	dialer := net.Dialer{}
+	return dialer.DialContext(ctx, "unix", socketPath)
+}
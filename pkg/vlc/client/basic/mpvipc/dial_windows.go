@@ -0,0 +1,13 @@
+//go:build windows
+
+package mpvipc
+
+import (
+	"context"
+	"net"
+)
+
+// dial fails: mpv uses named pipes for IPC on Windows, they are not supported yet
+func dial(_ context.Context, _ string) (net.Conn, error) {
+	return nil, ErrUnsupportedPlatform
+}
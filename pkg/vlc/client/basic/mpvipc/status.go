@@ -0,0 +1,65 @@
+package mpvipc
+
+import (
+	"time"
+
+	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+)
+
+type track struct {
+	ID    int    `json:"id"`
+	Type  string `json:"type"`
+	Lang  string `json:"lang"`
+	Codec string `json:"codec"`
+}
+
+var streamTypes = map[string]basic.StreamType{
+	"video": basic.StreamTypeVideo,
+	"audio": basic.StreamTypeAudio,
+	"sub":   basic.StreamTypeSubtitle,
+}
+
+// getStatus builds the status from observed properties and the playback time requested at the moment
+func (c *ApiClient) getStatus(timePos float64, moment timeutil.Range) basic.Status {
+	status := basic.Status{
+		Moment:    moment,
+		LengthSec: c.getLengthSec(),
+		Rate:      1,
+		FileName:  c.getStringProp(propFilename),
+	}
+	if speed, ok := getProp[float64](c, propSpeed); ok {
+		status.Rate = speed
+	}
+	if status.LengthSec > 0 {
+		status.Position = timePos / float64(status.LengthSec)
+	}
+	isIdle, _ := getProp[bool](c, propIdleActive)
+	isPaused, _ := getProp[bool](c, propPause)
+	switch {
+	case isIdle:
+		status.State = basic.PlaybackStateStopped
+	case isPaused:
+		status.State = basic.PlaybackStatePaused
+	default:
+		status.State = basic.PlaybackStatePlaying
+	}
+	if audioDelay, ok := getProp[float64](c, propAudioDelay); ok {
+		status.AudioDelay = time.Duration(audioDelay * float64(time.Second))
+	}
+	if volume, ok := getProp[float64](c, propVolume); ok {
+		status.Volume = volume / mpvVolumeScale
+	}
+	tracks, _ := getProp[[]track](c, propTrackList)
+	for _, t := range tracks {
+		if streamType, ok := streamTypes[t.Type]; ok {
+			status.Streams = append(status.Streams, basic.Stream{
+				ID:       t.ID,
+				Type:     streamType,
+				Language: t.Lang,
+				Codec:    t.Codec,
+			})
+		}
+	}
+	return status
+}
@@ -2,21 +2,31 @@ package protocols
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
 	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
 	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic/httpjson"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic/luapush"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic/rc"
 )
 
 const ApiProtocolHttpJson ApiProtocol = "http-json"
 
+// ApiProtocolLuaPush is http-json with status change events pushed by the bundled Lua interface script.
+// Attached players are polled only
+const ApiProtocolLuaPush ApiProtocol = "lua-push"
+
+// ApiProtocolRc is VLC RC interface. It's less precise than http-json, use it if VLC web interface doesn't work
+const ApiProtocolRc ApiProtocol = "rc"
+
 type ApiProtocol string
 
 var ErrUnsupportedApiProtocol = errors.New("unsupported api protocol")
 
 func (p ApiProtocol) Validate() error {
-	if p != ApiProtocolHttpJson {
-		return ErrUnsupportedApiProtocol
+	if p != ApiProtocolHttpJson && p != ApiProtocolLuaPush && p != ApiProtocolRc {
+		return fmt.Errorf("%w: '%s'", ErrUnsupportedApiProtocol, p)
 	}
 	return nil
 }
@@ -25,6 +35,25 @@ func NewLocalBasicApiClient(protocol ApiProtocol, logger logging.Logger) (basic.
 	switch protocol {
 	case ApiProtocolHttpJson:
 		return httpjson.NewLocalBasicApiClient(logger)
+	case ApiProtocolLuaPush:
+		return luapush.NewLocalApiClient(logger)
+	case ApiProtocolRc:
+		return rc.NewLocalApiClient(logger)
+	default:
+		return nil, ErrUnsupportedApiProtocol
+	}
+}
+
+func NewRemoteBasicApiClient(
+	protocol ApiProtocol,
+	connectionInfo httpjson.ConnectionInfo,
+	logger logging.Logger,
+) (basic.ApiClient, error) {
+	switch protocol {
+	case ApiProtocolHttpJson, ApiProtocolLuaPush:
+		return httpjson.NewRemoteBasicApiClient(connectionInfo, logger), nil
+	case ApiProtocolRc:
+		return rc.NewRemoteApiClient("tcp", connectionInfo.GetAddress(), logger), nil
 	default:
 		return nil, ErrUnsupportedApiProtocol
 	}
@@ -0,0 +1,155 @@
+package rc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	osutil "github.com/cardinalby/vlc-sync-play/pkg/util/os"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+)
+
+var ErrNotConnected = errors.New("not connected to VLC rc interface")
+var ErrDisconnected = errors.New("disconnected from VLC rc interface")
+var ErrFormingRequest = errors.New("failed to form a request")
+var ErrUnsupportedCommand = errors.New("unsupported command")
+
+const (
+	rcCmdStatus    = "status"
+	rcCmdGetTime   = "get_time"
+	rcCmdGetLength = "get_length"
+	rcCmdAudio     = "atrack"
+	rcCmdSubtitle  = "strack"
+)
+
+// ApiClient is a client for the VLC RC (remote control) interface. It's available in VLC builds without
+// the web interface. RC reports the playback time in whole seconds and doesn't report the playback rate,
+// so the sync is less precise than with the HTTP JSON API.
+// See: https://wiki.videolan.org/Documentation:Modules/rc/
+type ApiClient struct {
+	network string
+	address string
+	logger  logging.Logger
+	// mu guards the connection (RC interface can't match responses to concurrent requests) and the fields below
+	mu   sync.Mutex
+	conn *connection
+	// fileURI is the URI of the current input, rate and seekPosition are reset when it changes
+	fileURI string
+	// rate is the last rate set by the client since RC doesn't report it
+	rate float64
+	// seekPosition is the position of the last seek. It's more precise than the time reported by RC
+	seekPosition float64
+}
+
+// NewLocalApiClient creates a client for VLC to launch with RC interface on a free localhost port
+func NewLocalApiClient(logger logging.Logger) (*ApiClient, error) {
+	host, port, err := osutil.GetFreePort()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get free port: %w", err)
+	}
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	logger.Info("Connection to VLC rc interface: %s", address)
+	return newApiClient("tcp", address, logger), nil
+}
+
+// NewRemoteApiClient creates a client for VLC started by someone else with RC interface enabled:
+// "tcp" network for --rc-host and "unix" for --rc-unix
+func NewRemoteApiClient(network, address string, logger logging.Logger) *ApiClient {
+	logger.Info("Connection to remote VLC rc interface: %s %s", network, address)
+	return newApiClient(network, address, logger)
+}
+
+func newApiClient(network, address string, logger logging.Logger) *ApiClient {
+	return &ApiClient{
+		network:      network,
+		address:      address,
+		logger:       logger,
+		rate:         1,
+		seekPosition: -1,
+	}
+}
+
+func (c *ApiClient) GetStatus(ctx context.Context) (basic.Status, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.getStatus(ctx)
+}
+
+func (c *ApiClient) SendStatusCmd(ctx context.Context, cmd basic.Command) (basic.Status, error) {
+	c.logger.Info("CMD %s %v", c.address, cmd)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rcCommand, err := c.toRcCommand(ctx, cmd)
+	if err != nil {
+		if !errors.Is(err, ErrUnsupportedCommand) {
+			return basic.Status{}, err
+		}
+		// VLC ignores inapplicable commands as well
+		c.logger.Err("%s", err.Error())
+	}
+	if rcCommand != "" {
+		if _, err := c.request(ctx, rcCommand); err != nil {
+			return basic.Status{}, err
+		}
+		c.onCommandSent(cmd)
+	}
+	return c.getStatus(ctx)
+}
+
+func (c *ApiClient) GetCurrentFileUri(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	responses, err := c.request(ctx, rcCmdStatus)
+	if err != nil {
+		return "", err
+	}
+	fileURI, _, _ := parseStatus(responses[0].lines)
+	c.logger.Info("Current playlist item: %v", fileURI)
+	return fileURI, nil
+}
+
+func (c *ApiClient) IsRecoverableErr(err error) bool {
+	return !errors.Is(err, ErrFormingRequest)
+}
+
+func (c *ApiClient) GetLaunchArgs() []string {
+	args := []string{"--extraintf=rc"}
+	if c.network == "unix" {
+		args = append(args, "--rc-unix", c.address)
+	} else {
+		args = append(args, "--rc-host", c.address)
+	}
+	if runtime.GOOS == "windows" {
+		// don't open the console window
+		args = append(args, "--rc-quiet")
+	}
+	return args
+}
+
+// request sends the commands, reconnecting if there is no connection. Should be called with mu locked
+func (c *ApiClient) request(ctx context.Context, commands ...string) ([]response, error) {
+	if c.conn == nil {
+		dialer := net.Dialer{}
+		netConn, err := dialer.DialContext(ctx, c.network, c.address)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrNotConnected, err)
+		}
+		if c.conn, err = newConnection(ctx, netConn); err != nil {
+			_ = netConn.Close()
+			return nil, err
+		}
+	}
+	responses, err := c.conn.request(ctx, commands...)
+	if err != nil {
+		c.conn.close()
+		c.conn = nil
+	}
+	return responses, err
+}
@@ -0,0 +1,75 @@
+package rc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+)
+
+// toRcCommand maps the command to RC command. Returns empty command if nothing should be sent.
+// Should be called with mu locked
+func (c *ApiClient) toRcCommand(ctx context.Context, cmd basic.Command) (string, error) {
+	switch cmd.GetName() {
+	case basic.CmdNamePause, basic.CmdNameResume:
+		// "pause" toggles the pause, check the state first as VLC HTTP API does
+		status, err := c.getStatus(ctx)
+		if err != nil {
+			return "", err
+		}
+		isPaused := status.State == basic.PlaybackStatePaused
+		if (cmd.GetName() == basic.CmdNamePause && status.State == basic.PlaybackStatePlaying) ||
+			(cmd.GetName() == basic.CmdNameResume && isPaused) {
+			return "pause", nil
+		}
+		return "", nil
+	case basic.CmdNamePauseResume:
+		return "pause", nil
+	case basic.CmdNameStop:
+		return "stop", nil
+	case basic.CmdNamePlayFile:
+		return "add " + cmd[basic.KeyInput], nil
+	case basic.CmdNameSeek:
+		// position in percents is more precise than seconds
+		if _, err := cmd.GetSeekPosition(); err != nil {
+			return "", fmt.Errorf("%w: %w", ErrFormingRequest, err)
+		}
+		return "seek " + cmd[basic.KeyVal], nil
+	case basic.CmdNameRate:
+		if _, err := cmd.GetFloatVal(); err != nil {
+			return "", fmt.Errorf("%w: %w", ErrFormingRequest, err)
+		}
+		return "rate " + cmd[basic.KeyVal], nil
+	case basic.CmdNameVolume:
+		if _, err := cmd.GetIntVal(); err != nil {
+			return "", fmt.Errorf("%w: %w", ErrFormingRequest, err)
+		}
+		return "volume " + cmd[basic.KeyVal], nil
+	case basic.CmdNameAudioTrack:
+		if _, err := cmd.GetIntVal(); err != nil {
+			return "", fmt.Errorf("%w: %w", ErrFormingRequest, err)
+		}
+		return "atrack " + cmd[basic.KeyVal], nil
+	case basic.CmdNameSubtitleTrack:
+		if _, err := cmd.GetIntVal(); err != nil {
+			return "", fmt.Errorf("%w: %w", ErrFormingRequest, err)
+		}
+		return "strack " + cmd[basic.KeyVal], nil
+	default:
+		// audio delay and external subtitles can't be set by RC
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedCommand, cmd.GetName())
+	}
+}
+
+// onCommandSent remembers the values RC doesn't report. Should be called with mu locked
+func (c *ApiClient) onCommandSent(cmd basic.Command) {
+	switch cmd.GetName() {
+	case basic.CmdNameRate:
+		c.rate, _ = cmd.GetFloatVal()
+	case basic.CmdNameSeek:
+		c.seekPosition, _ = cmd.GetSeekPosition()
+	case basic.CmdNamePlayFile, basic.CmdNameStop:
+		c.rate = 1
+		c.seekPosition = -1
+	}
+}
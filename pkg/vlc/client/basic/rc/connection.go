@@ -0,0 +1,118 @@
+package rc
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
+)
+
+// endMarker is sent after each command. VLC responds to it with "Unknown command `vsp-end'..." line that
+// ends the response of the previous command. The RC interface has no other reliable response terminator:
+// the "> " prompt is not printed in some modes and asynchronous "status change" lines can come at any time
+const endMarker = "vsp-end"
+
+const promptPrefix = "> "
+
+// response is the output of one command
+type response struct {
+	lines []string
+	// moment is the time range between sending the commands and receiving the first line of the response
+	moment timeutil.Range
+}
+
+// connection is a connection to VLC RC interface. It's not safe for concurrent use
+type connection struct {
+	netConn net.Conn
+	reader  *bufio.Reader
+}
+
+func newConnection(ctx context.Context, netConn net.Conn) (*connection, error) {
+	conn := &connection{
+		netConn: netConn,
+		reader:  bufio.NewReader(netConn),
+	}
+	// skip the greeting
+	if _, err := conn.request(ctx); err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (c *connection) close() {
+	_ = c.netConn.Close()
+}
+
+// request sends the commands at once and returns their responses in the same order.
+// The connection should be closed after an error since the rest of the responses can't be matched
+func (c *connection) request(ctx context.Context, commands ...string) ([]response, error) {
+	stop := context.AfterFunc(ctx, func() {
+		_ = c.netConn.SetDeadline(time.Now())
+	})
+	defer stop()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = c.netConn.SetDeadline(deadline)
+	} else {
+		_ = c.netConn.SetDeadline(time.Time{})
+	}
+
+	var sb strings.Builder
+	for _, command := range commands {
+		sb.WriteString(command + "\n")
+		sb.WriteString(endMarker + "\n")
+	}
+	// an extra marker to skip the output printed before the first command
+	if len(commands) == 0 {
+		sb.WriteString(endMarker + "\n")
+	}
+
+	sentAt := time.Now()
+	if _, err := c.netConn.Write([]byte(sb.String())); err != nil {
+		return nil, c.wrapErr(ctx, err)
+	}
+
+	responses := make([]response, 0, len(commands))
+	for len(responses) < max(len(commands), 1) {
+		res := response{moment: timeutil.Range{Min: sentAt}}
+		for {
+			line, err := c.reader.ReadString('\n')
+			if err != nil {
+				return nil, c.wrapErr(ctx, err)
+			}
+			if res.moment.Max.IsZero() {
+				res.moment.Max = time.Now()
+			}
+			line = trimPrompts(strings.TrimRight(line, "\r\n"))
+			if strings.Contains(line, endMarker) {
+				break
+			}
+			if line != "" {
+				res.lines = append(res.lines, line)
+			}
+		}
+		responses = append(responses, res)
+	}
+	if len(commands) == 0 {
+		return nil, nil
+	}
+	return responses, nil
+}
+
+func (c *connection) wrapErr(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return fmt.Errorf("%w: %w", ErrDisconnected, err)
+}
+
+// trimPrompts removes the prompts printed before the line
+func trimPrompts(line string) string {
+	for strings.HasPrefix(line, promptPrefix) {
+		line = line[len(promptPrefix):]
+	}
+	return strings.TrimSpace(line)
+}
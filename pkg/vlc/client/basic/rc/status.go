@@ -0,0 +1,142 @@
+package rc
+
+import (
+	"context"
+	"math"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	urlutil "github.com/cardinalby/vlc-sync-play/pkg/url"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+)
+
+// lengthRoundingSec is added to the length reported in whole seconds to estimate the real length
+const lengthRoundingSec = 0.5
+
+var (
+	statusInputRegexp  = regexp.MustCompile(`^\( new input: (.*) \)$`)
+	statusVolumeRegexp = regexp.MustCompile(`^\( audio volume: (\d+) \)$`)
+	statusStateRegexp  = regexp.MustCompile(`^\( state (\w+) \)$`)
+	// trackRegexp matches "| 2 - Track 2 - [English] *" lines of atrack and strack output
+	trackRegexp         = regexp.MustCompile(`^\| (-?\d+) - (.*?)( \*)?$`)
+	trackLanguageRegexp = regexp.MustCompile(`\[([^]]+)]`)
+)
+
+var playbackStates = map[string]basic.PlaybackState{
+	"playing": basic.PlaybackStatePlaying,
+	"paused":  basic.PlaybackStatePaused,
+}
+
+// getStatus requests the status. The playback time is requested first to make its moment precise.
+// Should be called with mu locked
+func (c *ApiClient) getStatus(ctx context.Context) (basic.Status, error) {
+	responses, err := c.request(ctx, rcCmdGetTime, rcCmdStatus, rcCmdGetLength, rcCmdAudio, rcCmdSubtitle)
+	if err != nil {
+		return basic.Status{}, err
+	}
+	timeRes, statusRes, lengthRes, audioRes, subtitleRes := responses[0], responses[1], responses[2],
+		responses[3], responses[4]
+
+	fileURI, state, volume := parseStatus(statusRes.lines)
+	if fileURI != c.fileURI {
+		c.fileURI = fileURI
+		c.rate = 1
+		c.seekPosition = -1
+	}
+	status := basic.Status{
+		Moment: timeRes.moment,
+		Rate:   c.rate,
+		State:  state,
+		Volume: volume,
+	}
+	if fileURI == "" {
+		return status, nil
+	}
+	status.FileName = getFileName(fileURI)
+	status.LengthSec = parseIntResponse(lengthRes)
+	status.Position = c.getPosition(parseIntResponse(timeRes), status.LengthSec, state)
+	status.Streams = append(
+		parseTracks(audioRes.lines, basic.StreamTypeAudio),
+		parseTracks(subtitleRes.lines, basic.StreamTypeSubtitle)...,
+	)
+	return status, nil
+}
+
+// getPosition estimates the position (from 0 to 1 of the real length, as VLC reports it in the HTTP API)
+// by the time and length in whole seconds
+func (c *ApiClient) getPosition(timeSec, lengthSec int, state basic.PlaybackState) float64 {
+	if lengthSec == 0 {
+		return 0
+	}
+	length := float64(lengthSec) + lengthRoundingSec
+	// the middle of the reported second
+	position := (float64(timeSec) + 0.5) / length
+	if state == basic.PlaybackStatePaused && c.seekPosition >= 0 &&
+		math.Floor(c.seekPosition*length) == float64(timeSec) {
+		// the player is paused at the seek position (probably)
+		position = c.seekPosition
+	}
+	return math.Min(position, 1)
+}
+
+// parseStatus parses "status" command output. Lines of asynchronous "status change" messages
+// don't match the patterns
+func parseStatus(lines []string) (fileURI string, state basic.PlaybackState, volume float64) {
+	state = basic.PlaybackStateStopped
+	for _, line := range lines {
+		if match := statusInputRegexp.FindStringSubmatch(line); match != nil {
+			fileURI = match[1]
+		} else if match := statusVolumeRegexp.FindStringSubmatch(line); match != nil {
+			vlcVolume, _ := strconv.Atoi(match[1])
+			volume = float64(vlcVolume) / basic.VolumeScale
+		} else if match := statusStateRegexp.FindStringSubmatch(line); match != nil {
+			if playbackState, ok := playbackStates[match[1]]; ok {
+				state = playbackState
+			} else {
+				state = basic.PlaybackStateStopped
+			}
+		}
+	}
+	return fileURI, state, volume
+}
+
+// parseIntResponse returns the last number of the response. Other lines are asynchronous messages.
+// get_time and get_length responses are empty while the input is opening
+func parseIntResponse(res response) int {
+	for i := len(res.lines) - 1; i >= 0; i-- {
+		if value, err := strconv.Atoi(res.lines[i]); err == nil {
+			return value
+		}
+	}
+	return 0
+}
+
+// parseTracks parses atrack and strack output. "Disable" item is skipped
+func parseTracks(lines []string, streamType basic.StreamType) []basic.Stream {
+	var streams []basic.Stream
+	for _, line := range lines {
+		match := trackRegexp.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		id, err := strconv.Atoi(match[1])
+		if err != nil || id < 0 {
+			continue
+		}
+		stream := basic.Stream{ID: id, Type: streamType}
+		if languageMatch := trackLanguageRegexp.FindStringSubmatch(match[2]); languageMatch != nil {
+			stream.Language = languageMatch[1]
+		}
+		streams = append(streams, stream)
+	}
+	return streams
+}
+
+func getFileName(fileURI string) string {
+	if filePath, ok := urlutil.ToFilePath(fileURI); ok {
+		return path.Base(strings.ReplaceAll(filePath, "\\", "/"))
+	}
+	return path.Base(fileURI)
+}
@@ -0,0 +1,273 @@
+package rc
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/stretchr/testify/require"
+)
+
+const testFileURI = "file:///movies/movie.mkv"
+
+// fakeRcOutputs are outputs of RC commands by the command
+type fakeRcOutputs map[string][]string
+
+// serveFakeRc responds to the commands like VLC RC interface does: the greeting is printed before
+// the first output, unknown command message (for the end marker) is followed by the prompt
+func serveFakeRc(netConn net.Conn, outputs fakeRcOutputs) {
+	reader := bufio.NewReader(netConn)
+	isFirstLine := true
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		var sb strings.Builder
+		if isFirstLine {
+			sb.WriteString("VLC media player 3.0.18 Vetinari\nCommand Line Interface initialized. Type `help' for help.\n> ")
+			isFirstLine = false
+		}
+		command := strings.TrimSpace(line)
+		if command == endMarker {
+			sb.WriteString("Unknown command `" + endMarker + "'. Type `help' for help.\n> ")
+		} else {
+			for _, outLine := range outputs[command] {
+				sb.WriteString(outLine + "\n")
+			}
+		}
+		if _, err := netConn.Write([]byte(sb.String())); err != nil {
+			return
+		}
+	}
+}
+
+func newTestApiClient(t *testing.T, outputs fakeRcOutputs) *ApiClient {
+	clientConn, serverConn := net.Pipe()
+	t.Cleanup(func() {
+		_ = clientConn.Close()
+		_ = serverConn.Close()
+	})
+	go serveFakeRc(serverConn, outputs)
+
+	client := newApiClient("tcp", "fake", logging.NewNopLogger())
+	conn, err := newConnection(context.Background(), clientConn)
+	require.NoError(t, err)
+	client.conn = conn
+	return client
+}
+
+func TestParseStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		lines   []string
+		fileURI string
+		state   basic.PlaybackState
+		volume  float64
+	}{
+		{
+			name:    "playing",
+			lines:   []string{"( new input: " + testFileURI + " )", "( audio volume: 256 )", "( state playing )"},
+			fileURI: testFileURI,
+			state:   basic.PlaybackStatePlaying,
+			volume:  1,
+		},
+		{
+			name:    "paused",
+			lines:   []string{"( new input: " + testFileURI + " )", "( audio volume: 128 )", "( state paused )"},
+			fileURI: testFileURI,
+			state:   basic.PlaybackStatePaused,
+			volume:  0.5,
+		},
+		{
+			name:   "stopped without input",
+			lines:  []string{"( audio volume: 256 )", "( state stopped )"},
+			state:  basic.PlaybackStateStopped,
+			volume: 1,
+		},
+		{
+			name:    "unknown state",
+			lines:   []string{"( new input: " + testFileURI + " )", "( state opening )"},
+			fileURI: testFileURI,
+			state:   basic.PlaybackStateStopped,
+		},
+		{
+			name: "status change messages and garbled lines",
+			lines: []string{
+				"status change: ( play state: 3 ): Play",
+				"( new input: " + testFileURI + " )",
+				"( audio volume: abc )",
+				"( state playing",
+				"garbage",
+				"( state paused )",
+			},
+			fileURI: testFileURI,
+			state:   basic.PlaybackStatePaused,
+		},
+		{
+			name:  "empty",
+			state: basic.PlaybackStateStopped,
+		},
+	}
+	for _, test := range tests {
+		fileURI, state, volume := parseStatus(test.lines)
+		require.Equal(t, test.fileURI, fileURI, test.name)
+		require.Equal(t, test.state, state, test.name)
+		require.Equal(t, test.volume, volume, test.name)
+	}
+}
+
+func TestParseIntResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		lines []string
+		value int
+	}{
+		{name: "number", lines: []string{"42"}, value: 42},
+		{name: "opening input", lines: nil, value: 0},
+		{name: "status change message", lines: []string{"status change: ( time: 41s )", "42"}, value: 42},
+		{name: "last number", lines: []string{"41", "42"}, value: 42},
+		{name: "garbled", lines: []string{"42s", "four"}, value: 0},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.value, parseIntResponse(response{lines: test.lines}), test.name)
+	}
+}
+
+func TestParseTracks(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{
+		"+----[ Audio Track ]",
+		"| -1 - Disable",
+		"| 1 - Track 1 - [English]",
+		"| 2 - Track 2 - [Русский] *",
+		"| x - Track 3",
+		"+----[ end of Audio Track ]",
+	}
+	require.Equal(t, []basic.Stream{
+		{ID: 1, Type: basic.StreamTypeAudio, Language: "English"},
+		{ID: 2, Type: basic.StreamTypeAudio, Language: "Русский"},
+	}, parseTracks(lines, basic.StreamTypeAudio))
+}
+
+func TestTrimPrompts(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "42", trimPrompts("42"))
+	require.Equal(t, "42", trimPrompts("> 42"))
+	require.Equal(t, "42", trimPrompts("> > 42 "))
+	require.Equal(t, "", trimPrompts("> "))
+}
+
+func TestApiClientGetStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		outputs fakeRcOutputs
+		status  basic.Status
+	}{
+		{
+			name: "playing",
+			outputs: fakeRcOutputs{
+				rcCmdStatus: {"( new input: " + testFileURI + " )", "( audio volume: 256 )", "( state playing )"},
+				// the prompt isn't printed before asynchronous messages
+				rcCmdGetTime:   {"status change: ( time: 29s )", "> 30"},
+				rcCmdGetLength: {"100"},
+				rcCmdAudio:     {"+----[ Audio Track ]", "| 1 - Track 1 - [English] *", "+----[ end of Audio Track ]"},
+			},
+			status: basic.Status{
+				LengthSec: 100,
+				Rate:      1,
+				State:     basic.PlaybackStatePlaying,
+				Position:  30.5 / 100.5,
+				FileName:  "movie.mkv",
+				Streams:   []basic.Stream{{ID: 1, Type: basic.StreamTypeAudio, Language: "English"}},
+				Volume:    1,
+			},
+		},
+		{
+			name: "opening input",
+			outputs: fakeRcOutputs{
+				rcCmdStatus: {"( new input: " + testFileURI + " )", "( audio volume: 256 )", "( state opening )"},
+			},
+			status: basic.Status{
+				Rate:     1,
+				State:    basic.PlaybackStateStopped,
+				FileName: "movie.mkv",
+				Volume:   1,
+			},
+		},
+		{
+			name: "stopped without input",
+			outputs: fakeRcOutputs{
+				rcCmdStatus:    {"( audio volume: 256 )", "( state stopped )"},
+				rcCmdGetTime:   {"0"},
+				rcCmdGetLength: {"0"},
+			},
+			status: basic.Status{
+				Rate:   1,
+				State:  basic.PlaybackStateStopped,
+				Volume: 1,
+			},
+		},
+	}
+	for _, test := range tests {
+		client := newTestApiClient(t, test.outputs)
+		status, err := client.GetStatus(context.Background())
+		require.NoError(t, err, test.name)
+		require.False(t, status.Moment.Min.IsZero(), test.name)
+		require.False(t, status.Moment.Max.Before(status.Moment.Min), test.name)
+		status.Moment = test.status.Moment
+		require.InDelta(t, test.status.Position, status.Position, 0.0001, test.name)
+		status.Position = test.status.Position
+		require.Equal(t, test.status, status, test.name)
+	}
+}
+
+func TestApiClientSeekPositionWhenPaused(t *testing.T) {
+	t.Parallel()
+
+	client := newTestApiClient(t, fakeRcOutputs{
+		rcCmdStatus:    {"( new input: " + testFileURI + " )", "( state paused )"},
+		rcCmdGetTime:   {"30"},
+		rcCmdGetLength: {"100"},
+	})
+	// the first status remembers the input, seek position is reset when it changes
+	_, err := client.GetStatus(context.Background())
+	require.NoError(t, err)
+	status, err := client.SendStatusCmd(context.Background(), basic.SeekCmd(0.3))
+	require.NoError(t, err)
+	require.Equal(t, 0.3, status.Position)
+
+	client.mu.Lock()
+	client.seekPosition = 0.9
+	client.mu.Unlock()
+	// the reported time doesn't match the seek position
+	status, err = client.GetStatus(context.Background())
+	require.NoError(t, err)
+	require.InDelta(t, 30.5/100.5, status.Position, 0.0001)
+}
+
+func TestApiClientReconnectsAfterDisconnection(t *testing.T) {
+	t.Parallel()
+
+	client := newTestApiClient(t, fakeRcOutputs{})
+	client.conn.close()
+	_, err := client.GetStatus(context.Background())
+	require.ErrorIs(t, err, ErrDisconnected)
+	require.Nil(t, client.conn)
+
+	// the address can't be dialed
+	_, err = client.GetStatus(context.Background())
+	require.ErrorIs(t, err, ErrNotConnected)
+}
@@ -19,7 +19,23 @@ type Status struct {
 	State     PlaybackState
 	Position  float64
 	FileName  string
-	Moment    timeutil.Range
+	Streams   []Stream
+	// AudioDelay is audio delay of the current input
+	AudioDelay time.Duration
+	// Volume is 1 for 100%
+	Volume float64
+	Moment timeutil.Range
+}
+
+// GetStreams returns streams of the given type
+func (s Status) GetStreams(streamType StreamType) []Stream {
+	var res []Stream
+	for _, stream := range s.Streams {
+		if stream.Type == streamType {
+			res = append(res, stream)
+		}
+	}
+	return res
 }
 
 func (s Status) GetPbTime() time.Duration {
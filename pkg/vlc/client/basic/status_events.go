@@ -0,0 +1,23 @@
+package basic
+
+import (
+	"context"
+	"time"
+)
+
+// StatusEvent is a status change pushed by the player. It's a hint to request the full status
+type StatusEvent struct {
+	State    PlaybackState
+	FileURI  string
+	Position float64
+	Rate     float64
+	// ReceivedAt is the time the event was received
+	ReceivedAt time.Time
+}
+
+// StatusEventsSource is implemented by API clients that can push status changes in addition to polling
+type StatusEventsSource interface {
+	// ListenStatusEvents connects to the player and sends status change events to the channel until ctx is done
+	// or the connection is lost. The current status is sent right after connecting. Returns the reason
+	ListenStatusEvents(ctx context.Context, events chan<- StatusEvent) error
+}
@@ -0,0 +1,17 @@
+package basic
+
+type StreamType string
+
+const StreamTypeVideo StreamType = "Video"
+const StreamTypeAudio StreamType = "Audio"
+const StreamTypeSubtitle StreamType = "Subtitle"
+
+// Stream is an elementary stream (track) of the opened file
+type Stream struct {
+	// ID is an ID used to select the stream as a track
+	ID   int
+	Type StreamType
+	// Language is a language name or a code (depends on the file), can be empty
+	Language string
+	Codec    string
+}
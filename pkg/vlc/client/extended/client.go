@@ -8,6 +8,7 @@ import (
 	urlutil "github.com/cardinalby/vlc-sync-play/pkg/url"
 	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
 	mathutil "github.com/cardinalby/vlc-sync-play/pkg/util/math"
+	"github.com/cardinalby/vlc-sync-play/pkg/util/rx"
 	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
 	typeutil "github.com/cardinalby/vlc-sync-play/pkg/util/type"
 	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
@@ -23,13 +24,22 @@ type lastStatusPart struct {
 	FileURI   string
 }
 
+// SentCommand is a command accepted by the player. Moment is the moment of the response
+type SentCommand struct {
+	Command basic.Command
+	Moment  timeutil.Range
+}
+
 type Client struct {
 	api                      basic.ApiClient
 	mu                       sync.Mutex
+	respTimeMu               sync.Mutex
 	statusRespTime           *mathutil.AvgAcc[time.Duration]
 	lastStatusPart           typeutil.Optional[lastStatusPart]
 	getInstanceFinishedError func() error
 	isInstanceFinishedError  func(error) bool
+	clock                    timeutil.Clock
+	sentCommands             *rx.Subject[SentCommand]
 	logger                   logging.Logger
 }
 
@@ -37,6 +47,7 @@ func NewClient(
 	api basic.ApiClient,
 	getInstanceFinishedError func() error,
 	isInstanceFinishedError func(error) bool,
+	clock timeutil.Clock,
 	logger logging.Logger,
 ) *Client {
 	return &Client{
@@ -44,6 +55,8 @@ func NewClient(
 		statusRespTime:           mathutil.NewAvgAcc[time.Duration](respTimeSamplesCount),
 		getInstanceFinishedError: getInstanceFinishedError,
 		isInstanceFinishedError:  isInstanceFinishedError,
+		clock:                    clock,
+		sentCommands:             rx.NewSubject[SentCommand](),
 		logger:                   logger,
 	}
 }
@@ -82,7 +95,7 @@ func (c *Client) SendCmdGroup(
 		if err := updateRes(statusEx, err); err != nil {
 			return nil, err
 		}
-		clarificationStartedAt := time.Now()
+		clarificationStartedAt := c.clock.Now()
 		// Ensure that file is opened. It can be not reported as opened in the first request.
 		// Also, VLC may go crazy if you send next commands immediately
 		for !urlutil.EqualIgnoreSchema(statusEx.FileURI, targetFileURI) {
@@ -91,7 +104,7 @@ func (c *Client) SendCmdGroup(
 				return nil, err
 			}
 		}
-		c.logger.Info("File opened in %s", time.Since(clarificationStartedAt).String())
+		c.logger.Info("File opened in %s", c.clock.Now().Sub(clarificationStartedAt).String())
 	}
 
 	errGr, ctx := errgroup.WithContext(ctx)
@@ -108,6 +121,36 @@ func (c *Client) SendCmdGroup(
 		})
 	}
 
+	if isNotStopped && group.AudioTrack.HasValue {
+		errGr.Go(func() error {
+			return updateRes(c.sendStatusCmd(ctx, group.GetAudioTrackCmd(), rule))
+		})
+	}
+
+	if isNotStopped && group.SubtitleTrack.HasValue {
+		errGr.Go(func() error {
+			return updateRes(c.sendStatusCmd(ctx, group.GetSubtitleTrackCmd(), rule))
+		})
+	}
+
+	if isNotStopped && group.AddSubtitle.HasValue {
+		errGr.Go(func() error {
+			return updateRes(c.sendStatusCmd(ctx, group.GetAddSubtitleCmd(), rule))
+		})
+	}
+
+	if isNotStopped && group.AudioDelay.HasValue {
+		errGr.Go(func() error {
+			return updateRes(c.sendStatusCmd(ctx, group.GetAudioDelayCmd(), rule))
+		})
+	}
+
+	if cmd := group.GetVolumeCmd(); cmd != nil {
+		errGr.Go(func() error {
+			return updateRes(c.sendStatusCmd(ctx, cmd, rule))
+		})
+	}
+
 	if cmd := group.GetStateCmd(); cmd != nil {
 		errGr.Go(func() error {
 			return updateRes(c.sendStatusCmd(ctx, cmd, rule))
@@ -118,12 +161,31 @@ func (c *Client) SendCmdGroup(
 	return res, err
 }
 
+// GetStatusRespTime returns the average round-trip time of the last status requests
+func (c *Client) GetStatusRespTime() (time.Duration, bool) {
+	c.respTimeMu.Lock()
+	defer c.respTimeMu.Unlock()
+
+	return c.statusRespTime.Avg()
+}
+
+// SubscribeSentCommands subscribes to the commands accepted by the player. The callback should not block
+func (c *Client) SubscribeSentCommands(callback func(command SentCommand)) rx.Subscription {
+	return c.sentCommands.Subscribe(callback)
+}
+
+// GetStatusEventsSource returns the API client if it can push status changes
+func (c *Client) GetStatusEventsSource() (basic.StatusEventsSource, bool) {
+	source, ok := c.api.(basic.StatusEventsSource)
+	return source, ok
+}
+
 func (c *Client) getCmdExpectedExecutionTime() time.Time {
-	if avg, ok := c.statusRespTime.Avg(); ok {
-		return time.Now().Add(avg / 2)
+	if avg, ok := c.GetStatusRespTime(); ok {
+		return c.clock.Now().Add(avg / 2)
 	}
 	// should not happen
-	return time.Now()
+	return c.clock.Now()
 }
 
 func (c *Client) addFileURIToStatus(
@@ -134,7 +196,9 @@ func (c *Client) addFileURIToStatus(
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	c.respTimeMu.Lock()
 	c.statusRespTime.Add(status.Moment.Length())
+	c.respTimeMu.Unlock()
 
 	fileURI := c.lastStatusPart.Value.FileURI
 
@@ -198,6 +262,7 @@ func (c *Client) sendStatusCmd(
 	}, rule); err != nil {
 		return statusEx, err
 	}
+	c.sentCommands.Next(SentCommand{Command: cmd, Moment: status.Moment})
 	return c.addFileURIToStatus(ctx, status, rule)
 }
 
@@ -210,7 +275,7 @@ func (c *Client) apiCall(ctx context.Context, apiAction func() error, rule repet
 		if !c.IsRecoverableErr(err) || !rule.Interval.HasValue {
 			return err
 		}
-		if err := timeutil.SleepCtx(ctx, rule.Interval.Value); err != nil {
+		if err := timeutil.SleepCtx(ctx, c.clock, rule.Interval.Value); err != nil {
 			return err
 		}
 	}
@@ -14,6 +14,15 @@ type CmdGroup struct {
 	Seek     typeutil.Optional[ExpectedPositionGetter]
 	Rate     typeutil.Optional[float64]
 	State    typeutil.Optional[basic.PlaybackState]
+	// AudioTrack is an ID of the audio stream to select
+	AudioTrack typeutil.Optional[int]
+	// SubtitleTrack is an ID of the subtitles stream to select or basic.SubtitleTrackOff
+	SubtitleTrack typeutil.Optional[int]
+	// AddSubtitle is a path of external subtitles file to load
+	AddSubtitle typeutil.Optional[string]
+	AudioDelay  typeutil.Optional[time.Duration]
+	// Volume is 1 for 100%
+	Volume typeutil.Optional[float64]
 }
 
 func (g CmdGroup) GetOpenFileCmd() basic.Command {
@@ -52,6 +61,43 @@ func (g CmdGroup) GetStateCmd() basic.Command {
 	return nil
 }
 
+func (g CmdGroup) GetAudioTrackCmd() basic.Command {
+	if !g.AudioTrack.HasValue {
+		return nil
+	}
+	return basic.AudioTrackCmd(g.AudioTrack.Value)
+}
+
+func (g CmdGroup) GetSubtitleTrackCmd() basic.Command {
+	if !g.SubtitleTrack.HasValue {
+		return nil
+	}
+	return basic.SubtitleTrackCmd(g.SubtitleTrack.Value)
+}
+
+func (g CmdGroup) GetAddSubtitleCmd() basic.Command {
+	if !g.AddSubtitle.HasValue {
+		return nil
+	}
+	return basic.AddSubtitleCmd(g.AddSubtitle.Value)
+}
+
+func (g CmdGroup) GetAudioDelayCmd() basic.Command {
+	if !g.AudioDelay.HasValue {
+		return nil
+	}
+	return basic.AudioDelayCmd(g.AudioDelay.Value)
+}
+
+func (g CmdGroup) GetVolumeCmd() basic.Command {
+	if !g.Volume.HasValue {
+		return nil
+	}
+	return basic.VolumeCmd(g.Volume.Value)
+}
+
 func (g CmdGroup) HasAny() bool {
-	return g.OpenFile.HasValue || g.Seek.HasValue || g.Rate.HasValue || g.State.HasValue
+	return g.OpenFile.HasValue || g.Seek.HasValue || g.Rate.HasValue || g.State.HasValue ||
+		g.AudioTrack.HasValue || g.SubtitleTrack.HasValue || g.AddSubtitle.HasValue || g.AudioDelay.HasValue ||
+		g.Volume.HasValue
 }
@@ -8,6 +8,13 @@ const (
 	WaitForAutoSeekAfterFileOpenedDuration = 1000 * time.Millisecond
 	CommandsRepeatInterval                 = 50 * time.Millisecond
 	WaitForShutdownAfterStopDuration       = 500 * time.Millisecond
+	WaitForStreamsInfoDuration             = 3000 * time.Millisecond
+	AttachTimeout                          = 10 * time.Second
+	AttachedConnectionCheckInterval        = 1000 * time.Millisecond
+	AttachedDisconnectTimeout              = 5000 * time.Millisecond
+	StatusEventsReconnectInterval          = 1000 * time.Millisecond
+	// StatusEventsPollingInterval is the min polling interval while status events are received
+	StatusEventsPollingInterval = 1000 * time.Millisecond
 
 	SkipFollowerUpdatesBeforePollingIntervalsNumber = 1.5
 )
@@ -0,0 +1,105 @@
+package instance
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic/httpjson"
+)
+
+// PlayerType is a media player application that can be synced
+type PlayerType string
+
+const (
+	PlayerTypeVlc PlayerType = "vlc"
+	PlayerTypeMpv PlayerType = "mpv"
+)
+
+var ErrUnsupportedPlayerType = errors.New("unsupported player type")
+var ErrInvalidAttachTarget = errors.New("invalid attach target")
+
+// ParsePlayerType parses a player type name. Empty string means VLC
+func ParsePlayerType(str string) (PlayerType, error) {
+	switch playerType := PlayerType(strings.ToLower(strings.TrimSpace(str))); playerType {
+	case "", PlayerTypeVlc:
+		return PlayerTypeVlc, nil
+	case PlayerTypeMpv:
+		return playerType, nil
+	default:
+		return "", fmt.Errorf("%w: '%s'", ErrUnsupportedPlayerType, str)
+	}
+}
+
+// OrDefault returns VLC for the empty player type
+func (t PlayerType) OrDefault() PlayerType {
+	if t == "" {
+		return PlayerTypeVlc
+	}
+	return t
+}
+
+// Backend launches players of one type and creates API clients for them. API clients translate
+// basic.Command values to the player API and report basic.Status of the player
+type Backend interface {
+	GetPlayerType() PlayerType
+	// GetBinPath returns the path of the player executable
+	GetBinPath() (string, error)
+	// NewLocalApiClient creates a client for a player to launch. Its launch args are added to the player args
+	NewLocalApiClient(logger logging.Logger) (basic.ApiClient, error)
+	// NewRemoteApiClient creates a client for the player started by someone else
+	NewRemoteApiClient(target AttachTarget, logger logging.Logger) (basic.ApiClient, error)
+	// GetLaunchArgs returns the player args besides the API client ones. They include the file
+	// if LaunchesWithFile returns true
+	GetLaunchArgs(options LaunchOptions) []string
+	// LaunchesWithFile returns false if the file should be opened by a command after the player is launched
+	LaunchesWithFile() bool
+	// HasStdErrEvents returns true if the player reports events parsed by OutputParser to stderr
+	HasStdErrEvents() bool
+}
+
+// AttachTarget is a player started by someone else with its API enabled
+type AttachTarget struct {
+	Player PlayerType
+	// Vlc is the connection to VLC http interface, set for PlayerTypeVlc
+	Vlc httpjson.ConnectionInfo
+	// IpcSocket is the path of mpv --input-ipc-server socket, set for PlayerTypeMpv.
+	// For PlayerTypeVlc it's the path of --rc-unix socket of VLC RC interface (instead of Vlc)
+	IpcSocket string
+}
+
+// GetNetworkAddress returns the address to check the connection to the player
+func (t AttachTarget) GetNetworkAddress() (network, address string) {
+	if t.IpcSocket != "" {
+		return "unix", t.IpcSocket
+	}
+	return "tcp", t.Vlc.GetAddress()
+}
+
+// String returns the target in the format accepted by ParseAttachTarget
+func (t AttachTarget) String() string {
+	if t.IpcSocket != "" {
+		return string(t.Player.OrDefault()) + ":" + t.IpcSocket
+	}
+	return t.Vlc.Format()
+}
+
+// ParseAttachTarget parses "mpv:/path/to/socket" for mpv, "password@host:port" for VLC and
+// "vlc:/path/to/socket" for VLC RC interface Unix socket
+func ParseAttachTarget(str string) (AttachTarget, error) {
+	for _, playerType := range []PlayerType{PlayerTypeMpv, PlayerTypeVlc} {
+		if socketPath, isSocket := strings.CutPrefix(str, string(playerType)+":"); isSocket {
+			if socketPath == "" {
+				return AttachTarget{}, fmt.Errorf("%w '%s': empty socket path", ErrInvalidAttachTarget, str)
+			}
+			return AttachTarget{Player: playerType, IpcSocket: socketPath}, nil
+		}
+	}
+	connectionInfo, err := httpjson.ParseConnectionInfo(str)
+	if err != nil {
+		return AttachTarget{}, err
+	}
+	return AttachTarget{Player: PlayerTypeVlc, Vlc: connectionInfo}, nil
+}
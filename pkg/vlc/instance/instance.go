@@ -4,24 +4,46 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"os/exec"
+	"sync/atomic"
+	"time"
 
 	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
 	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
 	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/timings"
 	"golang.org/x/sync/errgroup"
 )
 
 var ErrInstanceFinished = errors.New("instance finished")
 var ErrInstanceFailed = fmt.Errorf("%w with error", ErrInstanceFinished)
+var ErrInstanceDisconnected = fmt.Errorf("%w: disconnected", ErrInstanceFinished)
 
 type Instance struct {
-	ID              uint
-	Client          *extended.Client
+	ID     uint
+	Client *extended.Client
+	// Cmd is nil for attached instances
 	Cmd             *exec.Cmd
 	stdErrParser    *OutputParser
 	logger          logging.Logger
 	internalWaitErr chan error
+	// attached is set for instances started by someone else
+	attached *attachedConnection
+	// external is set for instances of players that are not processes (e.g. fake ones in tests)
+	external *externalPlayer
+}
+
+type externalPlayer struct {
+	stop func() error
+	done <-chan struct{}
+}
+
+type attachedConnection struct {
+	target      AttachTarget
+	detach      context.CancelFunc
+	isConnected atomic.Bool
 }
 
 func newInstance(
@@ -44,31 +66,127 @@ func newInstance(
 		func(err error) bool {
 			return errors.Is(err, ErrInstanceFinished)
 		},
+		timeutil.RealClock,
 		logger,
 	)
 	inst.startInternalWait()
 	return inst
 }
 
+// newAttachedInstance creates an instance for the player started by someone else. It's considered finished
+// when the player is not reachable at the address or when the instance is stopped (the player keeps running)
+func newAttachedInstance(
+	id uint,
+	api basic.ApiClient,
+	target AttachTarget,
+	logger logging.Logger,
+) *Instance {
+	inst := &Instance{
+		ID:              id,
+		stdErrParser:    NewOutputParser(nil, EventsToParse{}),
+		logger:          logger,
+		internalWaitErr: make(chan error, 1),
+		attached: &attachedConnection{
+			target: target,
+		},
+	}
+	inst.attached.isConnected.Store(true)
+	inst.Client = extended.NewClient(
+		api,
+		inst.GetFinishedError,
+		func(err error) bool {
+			return errors.Is(err, ErrInstanceFinished)
+		},
+		timeutil.RealClock,
+		logger,
+	)
+	inst.startConnectionWatch()
+	return inst
+}
+
+// NewExternalInstance creates an instance of the player that is not a process started by the launcher,
+// e.g. a fake player in tests. stop is called to stop the player, the instance finishes when done is closed.
+// The client uses the clock the player is driven by
+func NewExternalInstance(
+	id uint,
+	api basic.ApiClient,
+	stop func() error,
+	done <-chan struct{},
+	clock timeutil.Clock,
+	logger logging.Logger,
+) *Instance {
+	inst := &Instance{
+		ID:              id,
+		stdErrParser:    NewOutputParser(nil, EventsToParse{}),
+		logger:          logger,
+		internalWaitErr: make(chan error, 1),
+		external: &externalPlayer{
+			stop: stop,
+			done: done,
+		},
+	}
+	inst.Client = extended.NewClient(
+		api,
+		inst.GetFinishedError,
+		func(err error) bool {
+			return errors.Is(err, ErrInstanceFinished)
+		},
+		clock,
+		logger,
+	)
+	go func() {
+		<-done
+		inst.internalWaitErr <- ErrInstanceFinished
+		close(inst.internalWaitErr)
+	}()
+	return inst
+}
+
+func (i Instance) IsAttached() bool {
+	return i.attached != nil
+}
+
 func (i Instance) GetPID() int {
-	if i.Cmd.Process == nil {
+	if i.Cmd == nil || i.Cmd.Process == nil {
 		return 0
 	}
 	return i.Cmd.Process.Pid
 }
 
 func (i Instance) IsRunning() bool {
+	if i.attached != nil {
+		return i.attached.isConnected.Load()
+	}
+	if i.external != nil {
+		select {
+		case <-i.external.done:
+			return false
+		default:
+			return true
+		}
+	}
 	return i.Cmd.Process != nil
 }
 
 func (i Instance) GetFinishedError() error {
 	if !i.IsRunning() {
+		if i.attached != nil {
+			return ErrInstanceDisconnected
+		}
 		return ErrInstanceFinished
 	}
 	return nil
 }
 
+// Stop kills the launched player process or detaches from the attached one
 func (i Instance) Stop() error {
+	if i.attached != nil {
+		i.attached.detach()
+		return nil
+	}
+	if i.external != nil {
+		return i.external.stop()
+	}
 	if i.IsRunning() {
 		return i.Cmd.Process.Kill()
 	}
@@ -113,3 +231,40 @@ func (i Instance) startInternalWait() {
 		close(i.internalWaitErr)
 	}()
 }
+
+func (i Instance) startConnectionWatch() {
+	ctx, cancel := context.WithCancel(context.Background())
+	i.attached.detach = cancel
+	go func() {
+		err := i.watchConnection(ctx)
+		i.attached.isConnected.Store(false)
+		i.internalWaitErr <- err
+		close(i.internalWaitErr)
+	}()
+}
+
+// watchConnection returns ErrInstanceDisconnected if the player is not reachable for
+// timings.AttachedDisconnectTimeout
+func (i Instance) watchConnection(ctx context.Context) error {
+	ticker := time.NewTicker(timings.AttachedConnectionCheckInterval)
+	defer ticker.Stop()
+	dialer := net.Dialer{Timeout: timings.AttachedConnectionCheckInterval}
+	network, address := i.attached.target.GetNetworkAddress()
+	lastConnectedAt := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ErrInstanceFinished
+		case <-ticker.C:
+			conn, err := dialer.DialContext(ctx, network, address)
+			if err == nil {
+				_ = conn.Close()
+				lastConnectedAt = time.Now()
+			} else if time.Since(lastConnectedAt) > timings.AttachedDisconnectTimeout {
+				i.logger.Err("%s is not reachable: %s", address, err.Error())
+				return ErrInstanceDisconnected
+			}
+		}
+	}
+}
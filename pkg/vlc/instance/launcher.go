@@ -6,12 +6,10 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
 
-	"github.com/cardinalby/vlc-sync-play/internal/app/static_features"
 	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
 	typeutil "github.com/cardinalby/vlc-sync-play/pkg/util/type"
-	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
-	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic/protocols"
 	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
 	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended/repetition"
 	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/timings"
@@ -22,84 +20,129 @@ const IDNone = 0
 type LaunchOptions struct {
 	FileURI typeutil.Optional[string]
 	NoVideo bool
+	// Player is the type of the player to launch, empty means VLC
+	Player PlayerType
 }
 
 type Launcher interface {
 	Launch(ctx context.Context, options LaunchOptions) (*Instance, error)
+	// Attach connects to the player started by someone else with its API enabled.
+	// options.NoVideo and options.Player are ignored
+	Attach(ctx context.Context, target AttachTarget, options LaunchOptions) (*Instance, error)
 }
 
+// NewLauncher creates a launcher of players of the backends types
 func NewLauncher(
-	vlcPath string,
-	apiProtocol protocols.ApiProtocol,
+	backends []Backend,
 	logger logging.Logger,
 ) Launcher {
+	backendsMap := make(map[PlayerType]Backend, len(backends))
+	for _, backend := range backends {
+		backendsMap[backend.GetPlayerType()] = backend
+	}
 	return &launcher{
-		nextId:      IDNone + 1,
-		vlcPath:     vlcPath,
-		apiProtocol: apiProtocol,
-		logger:      logger,
+		nextId:   IDNone + 1,
+		backends: backendsMap,
+		logger:   logger,
 	}
 }
 
 type launcher struct {
-	nextId      uint
-	vlcPath     string
-	apiProtocol protocols.ApiProtocol
-	logger      logging.Logger
+	nextIdMu sync.Mutex
+	nextId   uint
+	backends map[PlayerType]Backend
+	logger   logging.Logger
 }
 
 func (l *launcher) Launch(ctx context.Context, options LaunchOptions) (inst *Instance, err error) {
-	apiClient, err := protocols.NewLocalBasicApiClient(l.apiProtocol, l.logger)
+	backend, err := l.getBackend(options.Player)
+	if err != nil {
+		return nil, err
+	}
+	binPath, err := backend.GetBinPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find %s: %w", backend.GetPlayerType(), err)
+	}
+	apiClient, err := backend.NewLocalApiClient(l.logger)
 	if err != nil {
 		return nil, err
 	}
-	args := l.getArgs(apiClient, options)
+	args := append(apiClient.GetLaunchArgs(), backend.GetLaunchArgs(options)...)
 
-	cmd, outputParser, err := l.startCmd(args)
+	cmd, outputParser, err := l.startCmd(binPath, args, backend.HasStdErrEvents())
 	if err != nil {
 		return nil, err
 	}
 
 	inst = newInstance(
-		l.nextId,
+		l.getNextID(),
 		apiClient,
 		cmd,
 		outputParser,
 		l.logger,
 	)
-	l.nextId++
 
-	if err := l.waitUntilReady(ctx, inst, options.FileURI.Value); err != nil {
+	fileURI := options.FileURI.Value
+	if backend.LaunchesWithFile() {
+		fileURI = ""
+	}
+	if err := l.waitUntilReady(ctx, inst, fileURI); err != nil {
 		return nil, err
 	}
 
 	return inst, err
 }
 
-func (l *launcher) getArgs(apiClient basic.ApiClient, options LaunchOptions) []string {
-	args := apiClient.GetLaunchArgs()
-	if options.NoVideo {
-		args = append(args, "--no-video")
+func (l *launcher) Attach(
+	ctx context.Context,
+	target AttachTarget,
+	options LaunchOptions,
+) (*Instance, error) {
+	backend, err := l.getBackend(target.Player)
+	if err != nil {
+		return nil, err
 	}
-	if static_features.ClickPause {
-		args = append(args, "--verbose", "2")
+	apiClient, err := backend.NewRemoteApiClient(target, l.logger)
+	if err != nil {
+		return nil, err
+	}
+	inst := newAttachedInstance(l.getNextID(), apiClient, target, l.logger)
+
+	attachCtx, cancel := context.WithTimeout(ctx, timings.AttachTimeout)
+	defer cancel()
+	if err := l.waitUntilReady(attachCtx, inst, options.FileURI.Value); err != nil {
+		_ = inst.Stop()
+		return nil, fmt.Errorf("failed to attach to %s: %w", target.String(), err)
 	}
-	if static_features.LaunchWithFile && options.FileURI.HasValue {
-		args = append(args, options.FileURI.Value)
+	return inst, nil
+}
+
+func (l *launcher) getBackend(playerType PlayerType) (Backend, error) {
+	backend, ok := l.backends[playerType.OrDefault()]
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", ErrUnsupportedPlayerType, playerType)
 	}
-	return args
+	return backend, nil
+}
+
+func (l *launcher) getNextID() uint {
+	l.nextIdMu.Lock()
+	defer l.nextIdMu.Unlock()
+	id := l.nextId
+	l.nextId++
+	return id
 }
 
-func (l *launcher) startCmd(args []string) (*exec.Cmd, *OutputParser, error) {
-	l.logger.Info("Launching %s %s", l.vlcPath, strings.Join(args, " "))
-	cmd := exec.Command(l.vlcPath, args...)
+func (l *launcher) startCmd(binPath string, args []string, hasStdErrEvents bool) (*exec.Cmd, *OutputParser, error) {
+	l.logger.Info("Launching %s %s", binPath, strings.Join(args, " "))
+	cmd := exec.Command(binPath, args...)
 	if workingDir, err := os.Getwd(); err == nil {
 		cmd.Dir = workingDir
 	}
 	cmd.Env = os.Environ()
 
 	var outputParser *OutputParser
-	if static_features.ClickPause {
+	if hasStdErrEvents {
 		stderrPipe, err := cmd.StderrPipe()
 		if err != nil {
 			return nil, nil, fmt.Errorf("error creating stderr pipe: %w", err)
@@ -109,7 +152,7 @@ func (l *launcher) startCmd(args []string) (*exec.Cmd, *OutputParser, error) {
 		outputParser = NewOutputParser(nil, EventsToParse{})
 	}
 	if err := cmd.Start(); err != nil {
-		return nil, nil, fmt.Errorf("error starting vlc process: %w", err)
+		return nil, nil, fmt.Errorf("error starting player process: %w", err)
 	}
 	return cmd, outputParser, nil
 }
@@ -127,8 +170,7 @@ func (l *launcher) waitUntilReady(
 	}
 	l.logger.Info("* online")
 
-	//goland:noinspection GoBoolExpressions
-	if fileURI != "" && !static_features.LaunchWithFile {
+	if fileURI != "" {
 		l.logger.Info("* waiting open file cmd to complete")
 		if _, err := inst.Client.SendCmdGroup(
 			ctx,
@@ -0,0 +1,63 @@
+package instance
+
+import (
+	"os/exec"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic/mpvipc"
+)
+
+const defaultMpvBinName = "mpv"
+
+type mpvBackend struct {
+	mpvPath string
+}
+
+// NewMpvBackend creates a backend launching mpv. Empty mpvPath means mpv found in PATH
+func NewMpvBackend(mpvPath string) Backend {
+	return &mpvBackend{
+		mpvPath: mpvPath,
+	}
+}
+
+func (b *mpvBackend) GetPlayerType() PlayerType {
+	return PlayerTypeMpv
+}
+
+func (b *mpvBackend) GetBinPath() (string, error) {
+	if b.mpvPath != "" {
+		return b.mpvPath, nil
+	}
+	return exec.LookPath(defaultMpvBinName)
+}
+
+func (b *mpvBackend) NewLocalApiClient(logger logging.Logger) (basic.ApiClient, error) {
+	return mpvipc.NewLocalApiClient(logger), nil
+}
+
+func (b *mpvBackend) NewRemoteApiClient(target AttachTarget, logger logging.Logger) (basic.ApiClient, error) {
+	return mpvipc.NewRemoteApiClient(target.IpcSocket, logger), nil
+}
+
+func (b *mpvBackend) GetLaunchArgs(options LaunchOptions) []string {
+	// idle and keep-open make mpv behave like VLC: it keeps running without a file and after the end of it
+	args := []string{"--idle=yes", "--keep-open=yes"}
+	if options.NoVideo {
+		args = append(args, "--vid=no")
+	} else {
+		args = append(args, "--force-window=yes")
+	}
+	if options.FileURI.HasValue {
+		args = append(args, options.FileURI.Value)
+	}
+	return args
+}
+
+func (b *mpvBackend) LaunchesWithFile() bool {
+	return true
+}
+
+func (b *mpvBackend) HasStdErrEvents() bool {
+	return false
+}
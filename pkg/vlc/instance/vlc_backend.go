@@ -0,0 +1,70 @@
+package instance
+
+import (
+	"fmt"
+
+	"github.com/cardinalby/vlc-sync-play/internal/app/static_features"
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic/protocols"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic/rc"
+)
+
+type vlcBackend struct {
+	vlcPath     string
+	apiProtocol protocols.ApiProtocol
+}
+
+func NewVlcBackend(vlcPath string, apiProtocol protocols.ApiProtocol) Backend {
+	return &vlcBackend{
+		vlcPath:     vlcPath,
+		apiProtocol: apiProtocol,
+	}
+}
+
+func (b *vlcBackend) GetPlayerType() PlayerType {
+	return PlayerTypeVlc
+}
+
+func (b *vlcBackend) GetBinPath() (string, error) {
+	return b.vlcPath, nil
+}
+
+func (b *vlcBackend) NewLocalApiClient(logger logging.Logger) (basic.ApiClient, error) {
+	return protocols.NewLocalBasicApiClient(b.apiProtocol, logger)
+}
+
+func (b *vlcBackend) NewRemoteApiClient(target AttachTarget, logger logging.Logger) (basic.ApiClient, error) {
+	if target.IpcSocket != "" {
+		if b.apiProtocol != protocols.ApiProtocolRc {
+			return nil, fmt.Errorf(
+				"%w '%s': VLC socket requires '%s' api protocol",
+				ErrInvalidAttachTarget, target, protocols.ApiProtocolRc,
+			)
+		}
+		return rc.NewRemoteApiClient("unix", target.IpcSocket, logger), nil
+	}
+	return protocols.NewRemoteBasicApiClient(b.apiProtocol, target.Vlc, logger)
+}
+
+func (b *vlcBackend) GetLaunchArgs(options LaunchOptions) []string {
+	var args []string
+	if options.NoVideo {
+		args = append(args, "--no-video")
+	}
+	if static_features.ClickPause {
+		args = append(args, "--verbose", "2")
+	}
+	if static_features.LaunchWithFile && options.FileURI.HasValue {
+		args = append(args, options.FileURI.Value)
+	}
+	return args
+}
+
+func (b *vlcBackend) LaunchesWithFile() bool {
+	return static_features.LaunchWithFile
+}
+
+func (b *vlcBackend) HasStdErrEvents() bool {
+	return static_features.ClickPause
+}
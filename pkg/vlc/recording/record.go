@@ -0,0 +1,122 @@
+package recording
+
+import (
+	"time"
+
+	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+)
+
+type RecordType string
+
+const (
+	// RecordTypeStatus is a polled status. Update is set if the state found an update made by a user
+	RecordTypeStatus RecordType = "status"
+	// RecordTypeCmdStatus is a status received in response to the commands
+	RecordTypeCmdStatus RecordType = "cmd-status"
+	// RecordTypeCommand is a command accepted by the player
+	RecordTypeCommand RecordType = "cmd"
+	// RecordTypeRateNudge is a start (Nudge is set) or a finish of the drift correction rate nudge
+	RecordTypeRateNudge RecordType = "nudge"
+	// RecordTypeDecision is the syncer decision to accept or to skip an update
+	RecordTypeDecision RecordType = "decision"
+	// RecordTypeEvent is a syncer event. Events are not replayed, they help to read the recording
+	RecordTypeEvent RecordType = "event"
+)
+
+// Record is a line of the recording. Field names are short to keep recordings compact
+type Record struct {
+	Type RecordType `json:"t"`
+	// At is the time of recording
+	At       Time          `json:"at"`
+	PlayerID uint          `json:"p,omitempty"`
+	Status   *Status       `json:"s,omitempty"`
+	Update   string        `json:"u,omitempty"`
+	Command  basic.Command `json:"c,omitempty"`
+	Moment   *Moment       `json:"m,omitempty"`
+	Nudge    *Nudge        `json:"n,omitempty"`
+	Decision *Decision     `json:"d,omitempty"`
+	Event    *Event        `json:"e,omitempty"`
+}
+
+// Time is recorded as Unix time in nanoseconds. Zero time.Time is recorded as 0
+type Time int64
+
+func newTime(t time.Time) Time {
+	if t.IsZero() {
+		return 0
+	}
+	return Time(t.UnixNano())
+}
+
+func (t Time) ToTime() time.Time {
+	if t == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(t))
+}
+
+// Moment is timeutil.Range recorded as [min, max]
+type Moment [2]Time
+
+func newMoment(moment timeutil.Range) Moment {
+	return Moment{newTime(moment.Min), newTime(moment.Max)}
+}
+
+func (m Moment) ToRange() timeutil.Range {
+	return timeutil.Range{Min: m[0].ToTime(), Max: m[1].ToTime()}
+}
+
+// Status contains basic.StatusEx fields the state works with
+type Status struct {
+	FileURI   string              `json:"uri,omitempty"`
+	State     basic.PlaybackState `json:"st"`
+	Position  float64             `json:"pos"`
+	LengthSec int                 `json:"len"`
+	Rate      float64             `json:"rate"`
+	Moment    Moment              `json:"m"`
+}
+
+func newStatus(status basic.StatusEx) *Status {
+	return &Status{
+		FileURI:   status.FileURI,
+		State:     status.State,
+		Position:  status.Position,
+		LengthSec: status.LengthSec,
+		Rate:      status.Rate,
+		Moment:    newMoment(status.Moment),
+	}
+}
+
+func (s *Status) ToStatusEx() basic.StatusEx {
+	return basic.StatusEx{
+		Status: basic.Status{
+			LengthSec: s.LengthSec,
+			Rate:      s.Rate,
+			State:     s.State,
+			Position:  s.Position,
+			Moment:    s.Moment.ToRange(),
+		},
+		FileURI: s.FileURI,
+	}
+}
+
+type Nudge struct {
+	BaseRate   float64 `json:"base"`
+	NudgedRate float64 `json:"nudged"`
+}
+
+// Decision is syncer.UpdateDecision
+type Decision struct {
+	LastSyncedFromID           uint   `json:"from,omitempty"`
+	AcceptFollowerUpdatesAfter Time   `json:"after"`
+	UpdateMoment               Moment `json:"m"`
+	Accepted                   bool   `json:"ok"`
+}
+
+type Event struct {
+	Type   string `json:"type"`
+	Origin string `json:"origin,omitempty"`
+	Update string `json:"u,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
@@ -0,0 +1,140 @@
+package recording
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
+	typeutil "github.com/cardinalby/vlc-sync-play/pkg/util/type"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/state"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
+)
+
+// Recorder is syncer.Recorder writing JSONL records. Use Replay to replay the recording
+type Recorder struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	closer  io.Closer
+	clock   timeutil.Clock
+	// isFailed is set after the first write error to log it once
+	isFailed bool
+	logger   logging.Logger
+}
+
+func NewRecorder(w io.Writer, clock timeutil.Clock, logger logging.Logger) *Recorder {
+	return &Recorder{
+		encoder: json.NewEncoder(w),
+		clock:   clock,
+		logger:  logger,
+	}
+}
+
+// CreateFileRecorder creates (or truncates) the file to record to. Close should be called to close the file
+func CreateFileRecorder(path string, logger logging.Logger) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	recorder := NewRecorder(file, timeutil.RealClock, logger)
+	recorder.closer = file
+	return recorder, nil
+}
+
+func (r *Recorder) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closer.Close()
+}
+
+func (r *Recorder) RecordStatus(playerID uint, status basic.StatusEx, update *state.Update) {
+	record := Record{
+		Type:     RecordTypeStatus,
+		PlayerID: playerID,
+		Status:   newStatus(status),
+	}
+	if update != nil {
+		record.Update = update.ChangedProps.String()
+	}
+	r.write(record)
+}
+
+func (r *Recorder) RecordCmdStatus(playerID uint, status basic.StatusEx) {
+	r.write(Record{
+		Type:     RecordTypeCmdStatus,
+		PlayerID: playerID,
+		Status:   newStatus(status),
+	})
+}
+
+func (r *Recorder) RecordCommand(playerID uint, command extended.SentCommand) {
+	moment := newMoment(command.Moment)
+	r.write(Record{
+		Type:     RecordTypeCommand,
+		PlayerID: playerID,
+		Command:  command.Command,
+		Moment:   &moment,
+	})
+}
+
+func (r *Recorder) RecordRateNudge(playerID uint, nudge typeutil.Optional[syncer.RateNudge]) {
+	record := Record{
+		Type:     RecordTypeRateNudge,
+		PlayerID: playerID,
+	}
+	if nudge.HasValue {
+		record.Nudge = &Nudge{
+			BaseRate:   nudge.Value.BaseRate,
+			NudgedRate: nudge.Value.NudgedRate,
+		}
+	}
+	r.write(record)
+}
+
+func (r *Recorder) RecordDecision(playerID uint, decision syncer.UpdateDecision) {
+	r.write(Record{
+		Type:     RecordTypeDecision,
+		PlayerID: playerID,
+		Decision: &Decision{
+			LastSyncedFromID:           decision.LastSyncedFromID,
+			AcceptFollowerUpdatesAfter: newTime(decision.AcceptFollowerUpdatesAfter),
+			UpdateMoment:               newMoment(decision.UpdateMoment),
+			Accepted:                   decision.Accepted,
+		},
+	})
+}
+
+// RecordEvent records the syncer event. Subscribe it with syncer.Syncer.SubscribeEvents
+func (r *Recorder) RecordEvent(event syncer.Event) {
+	record := Record{
+		Type:     RecordTypeEvent,
+		PlayerID: event.PlayerID,
+		Event: &Event{
+			Type:   string(event.Type),
+			Origin: string(event.Origin),
+			Reason: event.Reason,
+		},
+	}
+	if event.Update.HasValue {
+		record.Event.Update = event.Update.Value.ChangedProps.String()
+	}
+	r.write(record)
+}
+
+func (r *Recorder) write(record Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record.At = newTime(r.clock.Now())
+	if err := r.encoder.Encode(record); err != nil && !r.isFailed {
+		r.isFailed = true
+		r.logger.Err("error writing the recording: %s", err.Error())
+	}
+}
@@ -0,0 +1,165 @@
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/util/rx"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/state"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
+)
+
+var ErrInvalidRecord = errors.New("invalid record")
+
+// maxRecordSize limits the length of a recording line
+const maxRecordSize = 1024 * 1024
+
+// Difference is a decision made differently by the replay
+type Difference struct {
+	// Line is the line number of the record in the recording
+	Line     int
+	PlayerID uint
+	Recorded string
+	Replayed string
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("line %d P[%d]: recorded %s, replayed %s", d.Line, d.PlayerID, d.Recorded, d.Replayed)
+}
+
+// Report is the result of Replay
+type Report struct {
+	Statuses    int
+	Commands    int
+	Decisions   int
+	Differences []Difference
+}
+
+type replayer struct {
+	states    map[uint]*state.State
+	estimator rx.Observable[state.PositionEstimator]
+	report    Report
+	logger    logging.Logger
+}
+
+// Replay feeds the recorded statuses to state.State of each player and the recorded syncer state to the
+// syncer decision logic. Returns the decisions that differ from the recorded ones. The estimator can differ
+// from the one used in the session to compare them
+func Replay(r io.Reader, estimator state.PositionEstimator, logger logging.Logger) (Report, error) {
+	rp := replayer{
+		states:    make(map[uint]*state.State),
+		estimator: rx.NewValue(estimator),
+		logger:    logger,
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxRecordSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return rp.report, fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := rp.replay(line, &record); err != nil {
+			return rp.report, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return rp.report, scanner.Err()
+}
+
+func (rp *replayer) replay(line int, record *Record) error {
+	switch record.Type {
+	case RecordTypeStatus:
+		if record.Status == nil {
+			return fmt.Errorf("%w: status is missing", ErrInvalidRecord)
+		}
+		rp.report.Statuses++
+		if replayed := rp.replayStatus(record.PlayerID, record.Status); replayed != record.Update {
+			rp.addDifference(line, record.PlayerID, formatUpdate(record.Update), formatUpdate(replayed))
+		}
+	case RecordTypeCmdStatus:
+		if record.Status == nil {
+			return fmt.Errorf("%w: status is missing", ErrInvalidRecord)
+		}
+		status := record.Status.ToStatusEx()
+		rp.getState(record.PlayerID).ApplyNewStatus(&status)
+	case RecordTypeCommand:
+		rp.report.Commands++
+	case RecordTypeRateNudge:
+		if record.Nudge != nil {
+			rp.getState(record.PlayerID).StartRateNudge(record.Nudge.BaseRate, record.Nudge.NudgedRate)
+		} else {
+			rp.getState(record.PlayerID).FinishRateNudge()
+		}
+	case RecordTypeDecision:
+		if record.Decision == nil {
+			return fmt.Errorf("%w: decision is missing", ErrInvalidRecord)
+		}
+		rp.report.Decisions++
+		decision := record.Decision
+		accepted := syncer.CanAcceptUpdate(
+			record.PlayerID,
+			decision.LastSyncedFromID,
+			decision.AcceptFollowerUpdatesAfter.ToTime(),
+			decision.UpdateMoment.ToRange(),
+		)
+		if accepted != decision.Accepted {
+			rp.addDifference(line, record.PlayerID, formatDecision(decision.Accepted), formatDecision(accepted))
+		}
+	case RecordTypeEvent:
+	default:
+		rp.logger.Err("line %d: unknown record type %s", line, record.Type)
+	}
+	return nil
+}
+
+// replayStatus returns changed props of the update as syncer.PollingClient does. Empty if there is no update
+func (rp *replayer) replayStatus(playerID uint, recordedStatus *Status) string {
+	playerState := rp.getState(playerID)
+	status := recordedStatus.ToStatusEx()
+	update, err := playerState.GetUpdate(&status)
+	playerState.ApplyNewStatus(&status)
+	if err != nil || !update.ChangedProps.HasAny() || update.IsNatural {
+		return ""
+	}
+	return update.ChangedProps.String()
+}
+
+func (rp *replayer) getState(playerID uint) *state.State {
+	playerState, ok := rp.states[playerID]
+	if !ok {
+		playerState = state.NewState(rp.estimator, rp.logger.WithPrefix(fmt.Sprintf("P[%d]", playerID)))
+		rp.states[playerID] = playerState
+	}
+	return playerState
+}
+
+func (rp *replayer) addDifference(line int, playerID uint, recorded, replayed string) {
+	rp.report.Differences = append(rp.report.Differences, Difference{
+		Line:     line,
+		PlayerID: playerID,
+		Recorded: recorded,
+		Replayed: replayed,
+	})
+}
+
+func formatUpdate(update string) string {
+	if update == "" {
+		return "no update"
+	}
+	return fmt.Sprintf("update [%s]", update)
+}
+
+func formatDecision(accepted bool) string {
+	if accepted {
+		return "accepted"
+	}
+	return "skipped"
+}
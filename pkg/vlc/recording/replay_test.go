@@ -0,0 +1,79 @@
+package recording
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/util/rx"
+	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/state"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/syncer"
+	"github.com/stretchr/testify/require"
+)
+
+const testLengthSec = 3600
+
+// recordSession records statuses of a player that plays and then is seeked by a user, updates are found
+// as syncer.PollingClient does
+func recordSession(t *testing.T) string {
+	clock := timeutil.NewVirtualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	var buf bytes.Buffer
+	recorder := NewRecorder(&buf, clock, logging.NewNopLogger())
+	playerState := state.NewState(rx.NewValue(state.PositionEstimatorRange), logging.NewNopLogger())
+
+	record := func(position float64) {
+		status := basic.StatusEx{
+			Status: basic.Status{
+				LengthSec: testLengthSec,
+				Rate:      1,
+				State:     basic.PlaybackStatePlaying,
+				Position:  position,
+				Moment:    timeutil.NewRangeWithLen(clock.Now(), 10*time.Millisecond),
+			},
+			FileURI: "file:///movie.mkv",
+		}
+		update, err := playerState.GetUpdate(&status)
+		require.NoError(t, err)
+		playerState.ApplyNewStatus(&status)
+		if update.ChangedProps.HasAny() && !update.IsNatural {
+			recorder.RecordStatus(1, status, &update)
+			recorder.RecordDecision(1, syncer.UpdateDecision{
+				LastSyncedFromID:           2,
+				AcceptFollowerUpdatesAfter: clock.Now().Add(-time.Second),
+				UpdateMoment:               status.Moment,
+				Accepted:                   true,
+			})
+		} else {
+			recorder.RecordStatus(1, status, nil)
+		}
+		clock.Advance(time.Second)
+	}
+	for i := 0; i < 5; i++ {
+		record(float64(i) / testLengthSec)
+	}
+	record(0.5)
+	record(0.5 + 1.0/testLengthSec)
+	return buf.String()
+}
+
+func TestReplayMatchesRecording(t *testing.T) {
+	report, err := Replay(strings.NewReader(recordSession(t)), state.PositionEstimatorRange, logging.NewNopLogger())
+	require.NoError(t, err)
+	require.Equal(t, 7, report.Statuses)
+	// opening the file takes 2 statuses, then the seek
+	require.Equal(t, 3, report.Decisions)
+	require.Empty(t, report.Differences)
+}
+
+func TestReplayFindsDifferentDecision(t *testing.T) {
+	recording := strings.Replace(recordSession(t), `"ok":true`, `"ok":false`, 1)
+	report, err := Replay(strings.NewReader(recording), state.PositionEstimatorRange, logging.NewNopLogger())
+	require.NoError(t, err)
+	require.Len(t, report.Differences, 1)
+	require.Equal(t, "skipped", report.Differences[0].Recorded)
+	require.Equal(t, "accepted", report.Differences[0].Replayed)
+}
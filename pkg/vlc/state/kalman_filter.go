@@ -0,0 +1,142 @@
+package state
+
+import (
+	"math"
+	"time"
+
+	mathutil "github.com/cardinalby/vlc-sync-play/pkg/util/math"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+)
+
+const (
+	// kalmanInitSpeedVariance is the variance of the speed at the start. Players follow the rate closely
+	kalmanInitSpeedVariance = 1e-4
+	// kalmanSpeedNoise is the speed variance growth per second
+	kalmanSpeedNoise = 1e-6
+	// kalmanConfidenceSigmas is the half-length of the confidence interval in standard deviations
+	kalmanConfidenceSigmas = 3
+)
+
+// kalmanFilter tracks the playback time and the ratio of the actual playback speed to the player rate.
+// It's fed by the playing statuses with changed position: VLC reports the position with a lag
+// and the moment it changes is the most informative one
+type kalmanFilter struct {
+	isSet bool
+	// at is the moment of the last status the filter has been advanced to
+	at time.Time
+	// rate is the player rate at `at`
+	rate float64
+	// position is the last measured status position
+	position float64
+	// pbTime is the estimated playback time at `at` in seconds
+	pbTime float64
+	// speed is the estimated ratio of the actual playback speed to the player rate
+	speed float64
+	// cov is the covariance matrix of (pbTime, speed)
+	cov [2][2]float64
+}
+
+// pbTimeEstimate is the estimated playback time with its confidence interval
+type pbTimeEstimate struct {
+	pbTime     time.Duration
+	confidence mathutil.Range[time.Duration]
+}
+
+func (f *kalmanFilter) reset() {
+	*f = kalmanFilter{}
+}
+
+// measure feeds the status playback time to the filter. The filter starts over if the status is not consistent
+// with the estimation
+func (f *kalmanFilter) measure(status *basic.StatusEx) {
+	measured, variance := getPbTimeMeasurement(status)
+	at := status.Moment.Center()
+	if !f.isSet || !f.isConsistent(status) {
+		*f = kalmanFilter{
+			isSet:    true,
+			at:       at,
+			rate:     status.Rate,
+			position: status.Position,
+			pbTime:   measured,
+			speed:    1,
+			cov:      [2][2]float64{{variance, 0}, {0, kalmanInitSpeedVariance}},
+		}
+		return
+	}
+	predicted, cov := f.predict(at, status.Rate)
+	innovationVariance := cov[0][0] + variance
+	pbTimeGain := cov[0][0] / innovationVariance
+	speedGain := cov[1][0] / innovationVariance
+	innovation := measured - predicted
+
+	f.at = at
+	f.rate = status.Rate
+	f.position = status.Position
+	f.pbTime = predicted + pbTimeGain*innovation
+	f.speed += speedGain * innovation
+	f.cov = [2][2]float64{
+		{(1 - pbTimeGain) * cov[0][0], (1 - pbTimeGain) * cov[0][1]},
+		{(1 - pbTimeGain) * cov[0][1], cov[1][1] - speedGain*cov[0][1]},
+	}
+}
+
+// advance moves the estimation to the status moment without measuring. It's used when the rate changes
+// while the position hasn't been updated yet
+func (f *kalmanFilter) advance(status *basic.StatusEx) {
+	at := status.Moment.Center()
+	f.pbTime, f.cov = f.predict(at, status.Rate)
+	f.at = at
+	f.rate = status.Rate
+}
+
+// isConsistent returns true if the status playback time is within the confidence interval of the estimation
+func (f *kalmanFilter) isConsistent(status *basic.StatusEx) bool {
+	predicted, cov := f.predict(status.Moment.Center(), status.Rate)
+	measured, variance := getPbTimeMeasurement(status)
+	return math.Abs(measured-predicted) <= kalmanConfidenceSigmas*math.Sqrt(cov[0][0]+variance)
+}
+
+func (f *kalmanFilter) estimate(at time.Time) pbTimeEstimate {
+	predicted, cov := f.predict(at, f.rate)
+	halfLen := kalmanConfidenceSigmas * math.Sqrt(cov[0][0])
+	return pbTimeEstimate{
+		pbTime: secondsToDuration(predicted),
+		confidence: mathutil.Range[time.Duration]{
+			Min: secondsToDuration(predicted - halfLen),
+			Max: secondsToDuration(predicted + halfLen),
+		},
+	}
+}
+
+// predict returns the playback time (in seconds) at the moment and the covariance matrix. rate is the player rate
+// at the moment
+func (f *kalmanFilter) predict(at time.Time, rate float64) (float64, [2][2]float64) {
+	dt := at.Sub(f.at).Seconds()
+	// the rate could change at any moment in between
+	avgRate := (f.rate + rate) / 2
+	rateChangeVariance := math.Pow((rate-f.rate)*dt, 2) / 12
+	// transition matrix is [[1, k], [0, 1]]
+	k := avgRate * dt
+	c := &f.cov
+	var cov [2][2]float64
+	cov[0][0] = c[0][0] + 2*k*c[0][1] + k*k*c[1][1] + rateChangeVariance
+	cov[0][1] = c[0][1] + k*c[1][1]
+	cov[1][0] = cov[0][1]
+	cov[1][1] = c[1][1] + kalmanSpeedNoise*math.Abs(dt)
+	return f.pbTime + f.speed*k, cov
+}
+
+// getPbTimeMeasurement returns the expected playback time of the status (in seconds) and its variance.
+// The lag of the status position and the request round-trip are treated as uniformly distributed.
+// The lag variance is widened by vlcPositionErrorK the same way as the ranges are
+func getPbTimeMeasurement(status *basic.StatusEx) (float64, float64) {
+	maxLag := vlcMaxPlaybackStepSeconds * max(status.Rate, 1.0)
+	lagRangeLength := maxLag * vlcPositionErrorK
+	momentLength := status.Moment.Length().Seconds() * status.Rate
+	return status.GetPbTime().Seconds() + maxLag/2,
+		(lagRangeLength*lagRangeLength + momentLength*momentLength) / 12
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
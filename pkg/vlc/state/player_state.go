@@ -3,12 +3,14 @@ package state
 import (
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
 	mathutil "github.com/cardinalby/vlc-sync-play/pkg/util/math"
+	"github.com/cardinalby/vlc-sync-play/pkg/util/rx"
 	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
 	typeutil "github.com/cardinalby/vlc-sync-play/pkg/util/type"
 	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
@@ -22,19 +24,36 @@ type playbackBase struct {
 	rate     float64
 }
 
+// rateNudge is a temporary rate change made by the drift correction. It should not be reported
+// as a rate change of the player
+type rateNudge struct {
+	baseRate   float64
+	nudgedRate float64
+	// isFinished is set when the base rate is restored. The nudge is reset by the first status
+	// with not nudged rate
+	isFinished bool
+}
+
 type State struct {
 	mu             sync.RWMutex
 	fileJustOpened bool
 	pbBase         typeutil.Optional[playbackBase]
 	prev           typeutil.Optional[basic.StatusEx]
-	logger         logging.Logger
+	rateNudge      typeutil.Optional[rateNudge]
+	// kalman is fed regardless of the estimator to allow switching it at any moment
+	kalman    kalmanFilter
+	estimator rx.Observable[PositionEstimator]
+	logger    logging.Logger
 }
 
+const rateEpsilon = 1e-4
+
 var errOlderThenPrevious = errors.New("new status is older than prev status")
 
-func NewState(logger logging.Logger) *State {
+func NewState(estimator rx.Observable[PositionEstimator], logger logging.Logger) *State {
 	return &State{
-		logger: logger,
+		estimator: estimator,
+		logger:    logger,
 	}
 }
 
@@ -76,6 +95,43 @@ func (s *State) GetUpdate(new *basic.StatusEx) (Update, error) {
 	return s.getUpdate(new)
 }
 
+// GetLastStatus returns the last applied status
+func (s *State) GetLastStatus() (basic.StatusEx, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.prev.Value, s.prev.HasValue
+}
+
+// StartRateNudge marks changes between baseRate and nudgedRate as not caused by a user until FinishRateNudge
+// is called
+func (s *State) StartRateNudge(baseRate float64, nudgedRate float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rateNudge.Set(rateNudge{
+		baseRate:   baseRate,
+		nudgedRate: nudgedRate,
+	})
+}
+
+// FinishRateNudge should be called after the command restoring the base rate has been executed.
+// The status of the command can be rejected as outdated, so the nudge is reset by the next status
+// with not nudged rate
+func (s *State) FinishRateNudge() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.rateNudge.HasValue {
+		return
+	}
+	if s.prev.HasValue && !isSameRate(s.prev.Value.Rate, s.rateNudge.Value.nudgedRate) {
+		s.rateNudge.Reset()
+		return
+	}
+	s.rateNudge.Value.isFinished = true
+}
+
 func (s *State) GetPauseOrResumeCommand() extended.CmdGroup {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -124,7 +180,7 @@ func (s *State) GetSyncCommands(props ChangedProps) extended.CmdGroup {
 	}
 
 	if props.HasRate() {
-		cmdGr.Rate.Set(prev.Rate)
+		cmdGr.Rate.Set(s.getBaseRate(prev.Rate))
 	}
 
 	if props.HasState() {
@@ -164,6 +220,17 @@ func (s *State) GetExpectedPosition() extended.ExpectedPositionGetter {
 	}()
 
 	prevMoment := prev.Moment
+	if s.isKalmanEstimator() {
+		// the filter is used if it's more confident than the ranges
+		kalman := s.kalman
+		length := float64(prev.GetLength())
+		if kalman.estimate(prevMoment.Center()).confidence.DivF(length).Length() < prevPositionRange.Length() {
+			return func(atMoment time.Time) float64 {
+				return mathutil.Clamp(float64(kalman.estimate(atMoment).pbTime)/length, 0, 1)
+			}
+		}
+	}
+
 	durationToPosMultiplier := prev.Rate / float64(prev.GetLength())
 
 	return func(atMoment time.Time) float64 {
@@ -190,13 +257,18 @@ func (s *State) applyNewStatus(new *basic.StatusEx) {
 		s.prev.Set(*new)
 		s.fileJustOpened = true
 		s.pbBase.Reset()
+		s.kalman.reset()
 		return
 	}
 
 	s.fileJustOpened = false
 	s.prev.Set(*new)
+	if s.rateNudge.HasValue && s.rateNudge.Value.isFinished && !isSameRate(new.Rate, s.rateNudge.Value.nudgedRate) {
+		s.rateNudge.Reset()
+	}
 
 	isPlaying := new.State == basic.PlaybackStatePlaying
+	s.applyToKalman(new, isPlaying)
 	if s.pbBase.HasValue && !isPlaying {
 		s.pbBase.Reset()
 	} else if isPlaying && (!s.pbBase.HasValue ||
@@ -212,6 +284,21 @@ func (s *State) applyNewStatus(new *basic.StatusEx) {
 	}
 }
 
+func (s *State) applyToKalman(new *basic.StatusEx, isPlaying bool) {
+	switch {
+	case !isPlaying:
+		s.kalman.reset()
+	case !s.kalman.isSet || s.kalman.position != new.Position:
+		s.kalman.measure(new)
+	case s.kalman.rate != new.Rate:
+		s.kalman.advance(new)
+	}
+}
+
+func (s *State) isKalmanEstimator() bool {
+	return s.kalman.isSet && s.estimator.GetValue() == PositionEstimatorKalman
+}
+
 func (s *State) getUpdate(new *basic.StatusEx) (Update, error) {
 	if s.prev.HasValue {
 		return s.getUpdateFromPrev(new)
@@ -245,7 +332,7 @@ func (s *State) getUpdateFromPrev(new *basic.StatusEx) (Update, error) {
 	}
 
 	upd.ChangedProps.SetState(new.State != prev.State)
-	upd.ChangedProps.SetRate(prev.Rate != new.Rate)
+	upd.ChangedProps.SetRate(prev.Rate != new.Rate && !s.isRateNudgeChange(prev.Rate, new.Rate))
 
 	naturalPositionChange := false
 	if new.Position != prev.Position {
@@ -254,14 +341,10 @@ func (s *State) getUpdateFromPrev(new *basic.StatusEx) (Update, error) {
 			pbBase.HasValue &&
 			new.Position >= prev.Position {
 			// can be a natural playback
-			actualPbTimeDelta := getActualPlaybackTimeDeltaFromPbBase(&pbBase.Value, new)
-			expectedPbTimeDelta := getExpectedPlaybackTimeDeltaFromPbBase(&pbBase.Value, prev, new)
-			if expectedPbTimeDelta.HasIntersection(actualPbTimeDelta) {
-				naturalPositionChange = true
+			if s.isKalmanEstimator() {
+				naturalPositionChange = s.isNaturalByKalman(new)
 			} else {
-				s.logger.Info(
-					"NOT NATURAL: expected pb time delta: %s, actual: %s",
-					expectedPbTimeDelta, actualPbTimeDelta)
+				naturalPositionChange = s.isNaturalByPbBase(prev, new)
 			}
 		}
 	}
@@ -273,6 +356,52 @@ func (s *State) getUpdateFromPrev(new *basic.StatusEx) (Update, error) {
 	return upd, nil
 }
 
+func (s *State) isNaturalByKalman(new *basic.StatusEx) bool {
+	if s.kalman.isConsistent(new) {
+		return true
+	}
+	estimate := s.kalman.estimate(new.Moment.Center())
+	s.logger.Info(
+		"NOT NATURAL: estimated pb time: %s (%s), actual: %s",
+		estimate.pbTime, estimate.confidence, new.GetPbTime())
+	return false
+}
+
+func (s *State) isNaturalByPbBase(prev *basic.StatusEx, new *basic.StatusEx) bool {
+	pbBase := &s.pbBase.Value
+	actualPbTimeDelta := getActualPlaybackTimeDeltaFromPbBase(pbBase, new)
+	expectedPbTimeDelta := getExpectedPlaybackTimeDeltaFromPbBase(pbBase, prev, new)
+	if expectedPbTimeDelta.HasIntersection(actualPbTimeDelta) {
+		return true
+	}
+	s.logger.Info(
+		"NOT NATURAL: expected pb time delta: %s, actual: %s",
+		expectedPbTimeDelta, actualPbTimeDelta)
+	return false
+}
+
+// getBaseRate returns the rate the player has without the drift correction nudge
+func (s *State) getBaseRate(rate float64) float64 {
+	if s.rateNudge.HasValue && isSameRate(rate, s.rateNudge.Value.nudgedRate) {
+		return s.rateNudge.Value.baseRate
+	}
+	return rate
+}
+
+func (s *State) isRateNudgeChange(prevRate, newRate float64) bool {
+	if !s.rateNudge.HasValue {
+		return false
+	}
+	nudge := &s.rateNudge.Value
+	return (isSameRate(prevRate, nudge.baseRate) && isSameRate(newRate, nudge.nudgedRate)) ||
+		(isSameRate(prevRate, nudge.nudgedRate) && isSameRate(newRate, nudge.baseRate))
+}
+
+// isSameRate compares rates sent in commands and rates reported by VLC (that stores them as float32)
+func isSameRate(rate1, rate2 float64) bool {
+	return math.Abs(rate1-rate2) < rateEpsilon
+}
+
 func (s *State) getInitStatusUpdate(new *basic.StatusEx) Update {
 	upd := Update{
 		Status: *new,
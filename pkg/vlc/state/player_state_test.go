@@ -0,0 +1,215 @@
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/util/rx"
+	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testFileURI   = "file:///movies/movie.mkv"
+	testLengthSec = 3600
+	// testRespTime is the round-trip time of the status requests
+	testRespTime = 10 * time.Millisecond
+)
+
+var testEstimators = []PositionEstimator{PositionEstimatorRange, PositionEstimatorKalman}
+
+// testPlayer produces statuses of the player playing from the start
+type testPlayer struct {
+	clock  *timeutil.VirtualClock
+	state  *State
+	pbTime time.Duration
+	rate   float64
+	// lag is subtracted from the reported position as VLC reports it with a lag
+	lag time.Duration
+}
+
+func newTestPlayer(estimator PositionEstimator) *testPlayer {
+	return &testPlayer{
+		clock: timeutil.NewVirtualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+		state: NewState(rx.NewValue(estimator), logging.NewNopLogger()),
+		rate:  1,
+	}
+}
+
+// play advances the clock and the playback time
+func (p *testPlayer) play(d time.Duration) {
+	p.clock.Advance(d)
+	p.pbTime += time.Duration(float64(d) * p.rate)
+}
+
+func (p *testPlayer) getStatus(state basic.PlaybackState) basic.StatusEx {
+	requestedAt := p.clock.Now()
+	return basic.StatusEx{
+		Status: basic.Status{
+			LengthSec: testLengthSec,
+			Rate:      p.rate,
+			State:     state,
+			Position:  float64(p.pbTime-p.lag) / float64(testLengthSec*time.Second),
+			Moment:    timeutil.NewRangeWithLen(requestedAt, testRespTime),
+		},
+		FileURI: testFileURI,
+	}
+}
+
+// applyStatus applies the status of the player and returns the update
+func (p *testPlayer) applyStatus(t *testing.T, state basic.PlaybackState) Update {
+	status := p.getStatus(state)
+	update, err := p.state.ApplyNewStatusAndGetUpdate(&status)
+	require.NoError(t, err)
+	// the next request starts after the response
+	if state == basic.PlaybackStatePlaying {
+		p.play(testRespTime)
+	} else {
+		p.clock.Advance(testRespTime)
+	}
+	return update
+}
+
+// startPlaying applies statuses of the opened file
+func (p *testPlayer) startPlaying(t *testing.T) {
+	update := p.applyStatus(t, basic.PlaybackStatePlaying)
+	require.True(t, update.ChangedProps.HasFileURI())
+	p.play(100 * time.Millisecond)
+	update = p.applyStatus(t, basic.PlaybackStatePlaying)
+	require.True(t, update.ChangedProps.HasPosition() && update.ChangedProps.HasState())
+}
+
+func TestStateNaturalPlayback(t *testing.T) {
+	t.Parallel()
+
+	for _, estimator := range testEstimators {
+		p := newTestPlayer(estimator)
+		p.startPlaying(t)
+		for i := 0; i < 20; i++ {
+			p.play(500 * time.Millisecond)
+			update := p.applyStatus(t, basic.PlaybackStatePlaying)
+			require.True(t, update.IsNatural, "%s: status %d", estimator, i)
+		}
+	}
+}
+
+func TestStateSeek(t *testing.T) {
+	t.Parallel()
+
+	for _, estimator := range testEstimators {
+		p := newTestPlayer(estimator)
+		p.startPlaying(t)
+		p.play(500 * time.Millisecond)
+		p.pbTime += 5 * time.Second
+		update := p.applyStatus(t, basic.PlaybackStatePlaying)
+		require.False(t, update.IsNatural, estimator)
+		require.True(t, update.ChangedProps.HasPosition(), estimator)
+		require.False(t, update.ChangedProps.HasState(), estimator)
+
+		// natural playback after the seek
+		p.play(500 * time.Millisecond)
+		update = p.applyStatus(t, basic.PlaybackStatePlaying)
+		require.True(t, update.IsNatural, estimator)
+	}
+}
+
+func TestStateSeekBack(t *testing.T) {
+	t.Parallel()
+
+	for _, estimator := range testEstimators {
+		p := newTestPlayer(estimator)
+		p.startPlaying(t)
+		p.play(10 * time.Second)
+		p.applyStatus(t, basic.PlaybackStatePlaying)
+		p.play(500 * time.Millisecond)
+		p.pbTime -= 5 * time.Second
+		update := p.applyStatus(t, basic.PlaybackStatePlaying)
+		require.False(t, update.IsNatural, estimator)
+		require.True(t, update.ChangedProps.HasPosition(), estimator)
+	}
+}
+
+func TestStateKalmanLaggingPosition(t *testing.T) {
+	t.Parallel()
+
+	p := newTestPlayer(PositionEstimatorKalman)
+	p.startPlaying(t)
+	// position updates of VLC lag behind the playback by up to a step
+	lags := []time.Duration{0, 300 * time.Millisecond, 100 * time.Millisecond, 450 * time.Millisecond, 200 * time.Millisecond}
+	for i := 0; i < 50; i++ {
+		p.lag = lags[i%len(lags)]
+		p.play(time.Duration(400+i%3*100) * time.Millisecond)
+		update := p.applyStatus(t, basic.PlaybackStatePlaying)
+		require.True(t, update.IsNatural, "status %d", i)
+	}
+
+	// the filter is more confident than a single status
+	kalmanEstimate := p.state.kalman.estimate(p.clock.Now())
+	require.Less(t, kalmanEstimate.confidence.Length(), newPositionDurationRange(0, 1).Length())
+	require.True(t, kalmanEstimate.confidence.Contains(p.pbTime))
+	estimate := p.state.GetExpectedPosition()(p.clock.Now())
+	require.InDelta(t, kalmanEstimate.pbTime.Seconds(), estimate*testLengthSec, 1e-6)
+
+	p.play(300 * time.Millisecond)
+	p.pbTime += 2 * time.Second
+	update := p.applyStatus(t, basic.PlaybackStatePlaying)
+	require.False(t, update.IsNatural)
+}
+
+func TestStatePausedSeek(t *testing.T) {
+	t.Parallel()
+
+	p := newTestPlayer(PositionEstimatorRange)
+	p.startPlaying(t)
+	update := p.applyStatus(t, basic.PlaybackStatePaused)
+	require.False(t, update.IsNatural)
+	require.True(t, update.ChangedProps.HasState())
+
+	p.clock.Advance(time.Second)
+	update = p.applyStatus(t, basic.PlaybackStatePaused)
+	require.False(t, update.ChangedProps.HasAny())
+
+	p.pbTime += 100 * time.Millisecond
+	update = p.applyStatus(t, basic.PlaybackStatePaused)
+	require.False(t, update.IsNatural)
+	require.True(t, update.ChangedProps.HasPosition())
+}
+
+func TestStateRejectsOlderStatus(t *testing.T) {
+	t.Parallel()
+
+	p := newTestPlayer(PositionEstimatorRange)
+	p.startPlaying(t)
+	older := p.getStatus(basic.PlaybackStatePlaying)
+	p.play(time.Second)
+	p.applyStatus(t, basic.PlaybackStatePlaying)
+
+	_, err := p.state.GetUpdate(&older)
+	require.ErrorIs(t, err, errOlderThenPrevious)
+}
+
+func TestStateRateNudge(t *testing.T) {
+	t.Parallel()
+
+	p := newTestPlayer(PositionEstimatorRange)
+	p.startPlaying(t)
+	p.state.StartRateNudge(1, 0.99)
+	p.rate = 0.99
+	p.play(500 * time.Millisecond)
+	update := p.applyStatus(t, basic.PlaybackStatePlaying)
+	require.False(t, update.ChangedProps.HasRate())
+
+	// the status of the command restoring the rate has been rejected as outdated
+	p.state.FinishRateNudge()
+	p.rate = 1
+	p.play(500 * time.Millisecond)
+	update = p.applyStatus(t, basic.PlaybackStatePlaying)
+	require.False(t, update.ChangedProps.HasRate())
+
+	p.rate = 0.99
+	p.play(500 * time.Millisecond)
+	update = p.applyStatus(t, basic.PlaybackStatePlaying)
+	require.True(t, update.ChangedProps.HasRate())
+}
@@ -0,0 +1,26 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PositionEstimator is the method of estimating the playback position between statuses and telling
+// a natural playback from a seek
+type PositionEstimator string
+
+// PositionEstimatorRange narrows down the position by intersecting error ranges of the recent statuses
+const PositionEstimatorRange PositionEstimator = "range"
+
+// PositionEstimatorKalman tracks the playback time and the actual playback speed with a Kalman filter.
+// It's less sensitive to coarse position updates and noisy round-trips
+const PositionEstimatorKalman PositionEstimator = "kalman"
+
+var ErrUnsupportedPositionEstimator = errors.New("unsupported position estimator")
+
+func (e PositionEstimator) Validate() error {
+	if e != PositionEstimatorRange && e != PositionEstimatorKalman {
+		return fmt.Errorf("%w: '%s'", ErrUnsupportedPositionEstimator, e)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package syncer
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/instance"
+)
+
+// attachInstances connects to players started by someone else. They occupy the first slots and count
+// towards the instances number. Unreachable instances are skipped: launched instances take their places
+func (s *Syncer) attachInstances(ctx context.Context, file openedFile) {
+	targets := s.settings.GetAttachTargets()
+	wg := sync.WaitGroup{}
+	for _, target := range targets {
+		wg.Add(1)
+		go func(target instance.AttachTarget) {
+			defer wg.Done()
+			err := s.addPlayer(ctx, file, func(options instance.LaunchOptions) (*instance.Instance, error) {
+				s.logger.Info("Attaching to %s", target.String())
+				return s.instanceLauncher.Attach(ctx, target, options)
+			})
+			if err != nil {
+				s.logger.Err("%s", err.Error())
+			}
+		}(target)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,247 @@
+package syncer
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	mathutil "github.com/cardinalby/vlc-sync-play/pkg/util/math"
+	typeutil "github.com/cardinalby/vlc-sync-play/pkg/util/type"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended/repetition"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/state"
+	"golang.org/x/exp/slices"
+)
+
+// externalSourceID is set as lastSyncedFromID when players are synced by external commands.
+// Updates of all players are skipped for followersSkipUpdatesDuration then
+const externalSourceID = math.MaxUint
+
+var ErrNoPlayers = errors.New("no players")
+var ErrPlayerNotFound = errors.New("player not found")
+var ErrNoFileOpened = errors.New("no file opened")
+
+// PlayerInfo is a snapshot of a player
+type PlayerInfo struct {
+	ID   uint
+	Slot int
+	// PID is 0 for attached players
+	PID        int
+	IsAttached bool
+	IsLeader   bool
+	Status     typeutil.Optional[basic.StatusEx]
+	LastUpdate typeutil.Optional[state.Update]
+	// LeaderOffset is how much the player is ahead of the leader. Not set for the leader
+	LeaderOffset typeutil.Optional[time.Duration]
+	// ResponseTime is the average round-trip time of the player API
+	ResponseTime typeutil.Optional[time.Duration]
+}
+
+// GetPlayers returns running players ordered by slots
+func (s *Syncer) GetPlayers() []PlayerInfo {
+	s.syncingMu.Lock()
+	leader := s.getLeader()
+	s.syncingMu.Unlock()
+
+	var res []PlayerInfo
+	now := s.clock.Now()
+	s.players.Iterate(func(pl *player) bool {
+		info := PlayerInfo{
+			ID:         pl.GetID(),
+			Slot:       pl.GetSlot(),
+			PID:        pl.instance.GetPID(),
+			IsAttached: pl.instance.IsAttached(),
+			IsLeader:   pl == leader,
+			LastUpdate: pl.GetLastUpdate(),
+		}
+		if status, ok := pl.client.state.GetLastStatus(); ok {
+			info.Status.Set(status)
+		}
+		if leader != nil && pl != leader {
+			if offset, ok := s.getLeaderOffset(leader, pl, now); ok {
+				info.LeaderOffset.Set(offset)
+			}
+		}
+		if respTime, ok := pl.client.GetStatusRespTime(); ok {
+			info.ResponseTime.Set(respTime)
+		}
+		res = append(res, info)
+		return true
+	})
+	slices.SortFunc(res, func(a, b PlayerInfo) int {
+		return a.Slot - b.Slot
+	})
+	return res
+}
+
+// GetLeaderStatus returns the last status of the player other players are synced to
+func (s *Syncer) GetLeaderStatus() (basic.StatusEx, bool) {
+	s.syncingMu.Lock()
+	leader := s.getLeader()
+	s.syncingMu.Unlock()
+
+	if leader == nil {
+		return basic.StatusEx{}, false
+	}
+	return leader.client.state.GetLastStatus()
+}
+
+// SendCommands syncs all players to commands of the app controls. Updates caused by them are skipped
+func (s *Syncer) SendCommands(ctx context.Context, commands extended.CmdGroup) {
+	s.syncingMu.Lock()
+	defer s.syncingMu.Unlock()
+
+	s.sendExternalCommands(ctx, commands, CommandsOriginExternal)
+}
+
+// SendPeerCommands syncs all players to commands received from a remote peer. They are emitted
+// with CommandsOriginPeer origin to not send them back. OpenFile command opens the file by the same URI
+func (s *Syncer) SendPeerCommands(ctx context.Context, commands extended.CmdGroup) {
+	s.syncingMu.Lock()
+	defer s.syncingMu.Unlock()
+
+	if commands.OpenFile.HasValue {
+		if err := s.openFile(ctx, commands.OpenFile.Value, CommandsOriginPeer); err != nil {
+			s.logger.Err("Error opening %s received from peer: %s", commands.OpenFile.Value, err.Error())
+		}
+		commands.OpenFile.Reset()
+	}
+	if commands.Seek.HasValue || commands.Rate.HasValue || commands.State.HasValue {
+		s.sendExternalCommands(ctx, commands, CommandsOriginPeer)
+	}
+}
+
+// SeekTo seeks all players to the playback time of the leader file
+func (s *Syncer) SeekTo(ctx context.Context, pbTime time.Duration) error {
+	s.syncingMu.Lock()
+	defer s.syncingMu.Unlock()
+
+	leader := s.getLeader()
+	if leader == nil {
+		return ErrNoPlayers
+	}
+	status, ok := leader.client.state.GetLastStatus()
+	if !ok || status.LengthSec == 0 {
+		return ErrNoFileOpened
+	}
+	s.seekTo(ctx, status, pbTime)
+	return nil
+}
+
+// SeekBy seeks all players forward by the offset of the leader playback time (backward if negative)
+func (s *Syncer) SeekBy(ctx context.Context, offset time.Duration) error {
+	s.syncingMu.Lock()
+	defer s.syncingMu.Unlock()
+
+	leader := s.getLeader()
+	if leader == nil {
+		return ErrNoPlayers
+	}
+	status, ok := leader.client.state.GetLastStatus()
+	positionGetter := leader.client.state.GetExpectedPosition()
+	if !ok || status.LengthSec == 0 || positionGetter == nil {
+		return ErrNoFileOpened
+	}
+	pbTime := time.Duration(positionGetter(s.clock.Now())*float64(status.GetLength())) + offset
+	s.seekTo(ctx, status, mathutil.Clamp(pbTime, 0, status.GetLength()))
+	return nil
+}
+
+func (s *Syncer) seekTo(ctx context.Context, status basic.StatusEx, pbTime time.Duration) {
+	seekedAt := s.clock.Now()
+	length := float64(status.GetLength())
+	rate := 0.0
+	if status.State == basic.PlaybackStatePlaying {
+		rate = status.Rate
+	}
+	s.sendExternalCommands(ctx, extended.CmdGroup{
+		Seek: typeutil.NewOptional[extended.ExpectedPositionGetter](func(atMoment time.Time) float64 {
+			expectedPbTime := float64(pbTime) + float64(atMoment.Sub(seekedAt))*rate
+			return mathutil.Clamp(expectedPbTime/length, 0, 1)
+		}),
+	}, CommandsOriginExternal)
+}
+
+// OpenFile opens the file in all players (or the files mapped to it)
+func (s *Syncer) OpenFile(ctx context.Context, fileURI string) error {
+	s.syncingMu.Lock()
+	defer s.syncingMu.Unlock()
+
+	return s.openFile(ctx, fileURI, CommandsOriginExternal)
+}
+
+func (s *Syncer) openFile(ctx context.Context, fileURI string, origin CommandsOrigin) error {
+	leader := s.getLeader()
+	if leader == nil {
+		return ErrNoPlayers
+	}
+	s.logger.Info("-- Opening %s by %s command", fileURI, origin)
+	s.driftController.cancelNudges()
+	s.state.lastSyncedFromID = externalSourceID
+	s.state.openedFile.SetValue(openedFile{
+		uri:  fileURI,
+		slot: leader.GetSlot(),
+	})
+	s.onFileOpened(ctx, nil)
+	s.emitExternalSyncedEvent(extended.CmdGroup{OpenFile: typeutil.NewOptional(fileURI)}, origin)
+	return nil
+}
+
+// ResyncFrom syncs position, state and rate of all players to the player
+func (s *Syncer) ResyncFrom(ctx context.Context, id uint) error {
+	s.syncingMu.Lock()
+	defer s.syncingMu.Unlock()
+
+	src := s.players.Get(id)
+	if src == nil {
+		return ErrPlayerNotFound
+	}
+	status, ok := src.client.state.GetLastStatus()
+	if !ok || status.LengthSec == 0 {
+		return ErrNoFileOpened
+	}
+	update := state.Update{Status: status}
+	update.ChangedProps.SetPosition(true)
+	update.ChangedProps.SetState(true)
+	update.ChangedProps.SetRate(true)
+
+	s.state.lastSyncedFromID = src.GetID()
+	s.syncPlayers(ctx, &playerUpdate{player: src, update: update})
+	return nil
+}
+
+// StopPlayer closes the launched player or detaches from the attached one
+func (s *Syncer) StopPlayer(id uint) error {
+	pl := s.players.Get(id)
+	if pl == nil {
+		return ErrPlayerNotFound
+	}
+	return pl.instance.Stop()
+}
+
+// SetPlayerVolume sets the volume of the player only, volume is not synced. 1 is 100%
+func (s *Syncer) SetPlayerVolume(ctx context.Context, id uint, volume float64) error {
+	pl := s.players.Get(id)
+	if pl == nil {
+		return ErrPlayerNotFound
+	}
+	_, err := pl.SendCmdGroup(ctx, extended.CmdGroup{Volume: typeutil.NewOptional(volume)}, repetition.Single())
+	return err
+}
+
+func (s *Syncer) sendExternalCommands(ctx context.Context, commands extended.CmdGroup, origin CommandsOrigin) {
+	src := s.getLeader()
+	if src == nil {
+		return
+	}
+	s.logger.Info("-- Syncing caused by external commands")
+	s.driftController.cancelNudges()
+
+	s.state.lastSyncedFromID = externalSourceID
+	s.state.acceptFollowerUpdatesAfter = s.clock.Now().Add(s.followersSkipUpdatesDuration)
+	s.sendAllPlayersCommands(ctx, src, commands)
+	s.state.acceptFollowerUpdatesAfter = s.clock.Now().Add(s.followersSkipUpdatesDuration)
+	s.emitExternalSyncedEvent(commands, origin)
+}
@@ -0,0 +1,303 @@
+package syncer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	mathutil "github.com/cardinalby/vlc-sync-play/pkg/util/math"
+	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
+	typeutil "github.com/cardinalby/vlc-sync-play/pkg/util/type"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended/repetition"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/timings"
+)
+
+const (
+	// driftCheckInterval is how often followers offsets are checked
+	driftCheckInterval = time.Second
+	// driftStartThreshold is an offset starting the correction of a follower
+	driftStartThreshold = 40 * time.Millisecond
+	// driftStopThreshold is an offset considered as "in sync" for a follower being corrected.
+	// The gap between start and stop thresholds prevents oscillation
+	driftStopThreshold = 10 * time.Millisecond
+	// driftSettleDuration is a pause after a nudge to let the status of the follower be updated
+	driftSettleDuration = 2 * time.Second
+	// driftNudgeTargetDuration is the desired duration of a nudge. The rate delta is chosen to fit it
+	driftNudgeTargetDuration = 5 * time.Second
+	driftMinRateDelta        = 0.01
+	driftMaxRateDelta        = 0.05
+)
+
+type driftAction int
+
+const (
+	driftActionNone driftAction = iota
+	driftActionSeek
+	driftActionNudge
+)
+
+type driftCorrection struct {
+	// isCorrecting is true while the follower offset hasn't got below driftStopThreshold
+	isCorrecting bool
+	// nudgeCancel is set while the nudge is in progress
+	nudgeCancel context.CancelFunc
+	settledAt   time.Time
+}
+
+// driftController corrects small offsets of the followers by temporarily changing their playback rate.
+// Offsets exceeding the seek threshold are corrected by seeking
+type driftController struct {
+	mu          sync.Mutex
+	corrections map[*player]*driftCorrection
+	clock       timeutil.Clock
+}
+
+func newDriftController(clock timeutil.Clock) *driftController {
+	return &driftController{
+		corrections: make(map[*player]*driftCorrection),
+		clock:       clock,
+	}
+}
+
+// cancelNudges stops all nudges in progress. Rates of the players get restored
+func (dc *driftController) cancelNudges() {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	for _, correction := range dc.corrections {
+		if correction.nudgeCancel != nil {
+			correction.nudgeCancel()
+		}
+	}
+}
+
+func (dc *driftController) forget(pl *player) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	if correction, ok := dc.corrections[pl]; ok && correction.nudgeCancel != nil {
+		correction.nudgeCancel()
+	}
+	delete(dc.corrections, pl)
+}
+
+// isCorrecting returns true if any of the followers is being corrected
+func (dc *driftController) isCorrecting() bool {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	for _, correction := range dc.corrections {
+		if correction.isCorrecting || correction.nudgeCancel != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// getAction returns the action correcting the offset of the follower and updates its correction state.
+// driftActionNone is returned while the follower is being nudged or settling after the previous action
+func (dc *driftController) getAction(
+	pl *player,
+	offset time.Duration,
+	seekThreshold time.Duration,
+) (driftAction, *driftCorrection) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	correction, ok := dc.corrections[pl]
+	if !ok {
+		correction = &driftCorrection{}
+		dc.corrections[pl] = correction
+	}
+	now := dc.clock.Now()
+	if correction.nudgeCancel != nil || now.Before(correction.settledAt) {
+		return driftActionNone, correction
+	}
+	absOffset := offset.Abs()
+
+	switch {
+	case absOffset >= seekThreshold:
+		correction.isCorrecting = false
+		correction.settledAt = now.Add(driftSettleDuration)
+		return driftActionSeek, correction
+	case absOffset >= driftStartThreshold || (correction.isCorrecting && absOffset >= driftStopThreshold):
+		correction.isCorrecting = true
+		return driftActionNudge, correction
+	default:
+		correction.isCorrecting = false
+		return driftActionNone, correction
+	}
+}
+
+func (s *Syncer) startDriftCorrection(ctx context.Context) {
+	ticker := s.clock.NewTicker(driftCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			if s.settings.GetDriftCorrection().GetValue() {
+				s.correctDrift(ctx)
+			}
+		}
+	}
+}
+
+func (s *Syncer) correctDrift(ctx context.Context) {
+	s.syncingMu.Lock()
+	if s.clock.Now().Before(s.state.acceptFollowerUpdatesAfter) {
+		s.syncingMu.Unlock()
+		return
+	}
+	leader := s.getLeader()
+	s.syncingMu.Unlock()
+	if leader == nil {
+		return
+	}
+
+	leaderStatus, ok := leader.client.state.GetLastStatus()
+	if !ok || leaderStatus.State != basic.PlaybackStatePlaying || leaderStatus.LengthSec == 0 {
+		return
+	}
+	seekThreshold := s.settings.GetDriftSeekThreshold().GetValue()
+
+	s.players.Iterate(func(pl *player) bool {
+		if pl == leader {
+			return true
+		}
+		status, ok := pl.client.state.GetLastStatus()
+		if !ok || status.State != basic.PlaybackStatePlaying || status.LengthSec == 0 {
+			return true
+		}
+		transform, ok := s.getPlayersTransform(leader, &leaderStatus, pl, &status)
+		if !ok {
+			return true
+		}
+		offset, ok := s.getLeaderOffset(leader, pl, s.clock.Now())
+		if !ok {
+			return true
+		}
+		switch action, correction := s.driftController.getAction(pl, offset, seekThreshold); action {
+		case driftActionSeek:
+			s.logger.Info("P[%d]: drift %v exceeds seek threshold", pl.GetID(), offset)
+			go s.seekDriftedPlayer(ctx, leader, pl)
+		case driftActionNudge:
+			s.startRateNudge(ctx, pl, correction, offset, transform.ApplyRate(leaderStatus.Rate))
+		}
+		return true
+	})
+}
+
+// getLeaderOffset returns how much the playback time of the player is ahead of the leader at the moment
+// (taking file mapping into account)
+func (s *Syncer) getLeaderOffset(leader *player, pl *player, at time.Time) (time.Duration, bool) {
+	leaderStatus, ok := leader.client.state.GetLastStatus()
+	if !ok || leaderStatus.LengthSec == 0 {
+		return 0, false
+	}
+	status, ok := pl.client.state.GetLastStatus()
+	if !ok || status.LengthSec == 0 {
+		return 0, false
+	}
+	transform, ok := s.getPlayersTransform(leader, &leaderStatus, pl, &status)
+	if !ok {
+		return 0, false
+	}
+	leaderPosition := leader.client.state.GetExpectedPosition()
+	position := pl.client.state.GetExpectedPosition()
+	if leaderPosition == nil || position == nil {
+		return 0, false
+	}
+	leaderPbTime := time.Duration(leaderPosition(at) * float64(leaderStatus.GetLength()))
+	return time.Duration(position(at)*float64(status.GetLength())) - transform.Apply(leaderPbTime), true
+}
+
+func (s *Syncer) seekDriftedPlayer(ctx context.Context, leader *player, pl *player) {
+	s.syncingMu.Lock()
+	defer s.syncingMu.Unlock()
+
+	positionGetter := leader.client.state.GetExpectedPosition()
+	if positionGetter == nil {
+		return
+	}
+	_, _ = s.sendPlayerCommands(
+		ctx,
+		leader,
+		pl,
+		extended.CmdGroup{
+			Seek: typeutil.NewOptional(positionGetter),
+		},
+		repetition.Single(),
+	)
+}
+
+func (s *Syncer) startRateNudge(
+	ctx context.Context,
+	pl *player,
+	correction *driftCorrection,
+	offset time.Duration,
+	baseRate float64,
+) {
+	rateDelta := mathutil.Clamp(
+		offset.Abs().Seconds()/driftNudgeTargetDuration.Seconds(),
+		driftMinRateDelta,
+		driftMaxRateDelta,
+	)
+	if offset > 0 {
+		// the follower is ahead
+		rateDelta = -rateDelta
+	}
+	nudgedRate := baseRate * (1 + rateDelta)
+	nudgeDuration := time.Duration(float64(offset.Abs()) / (rateDelta * baseRate)).Abs()
+
+	nudgeCtx, cancel := context.WithCancel(ctx)
+	s.driftController.mu.Lock()
+	correction.nudgeCancel = cancel
+	s.driftController.mu.Unlock()
+
+	s.logger.Info("P[%d]: drift %v, nudging rate to %f for %v", pl.GetID(), offset, nudgedRate, nudgeDuration)
+
+	go func() {
+		defer func() {
+			cancel()
+			s.driftController.mu.Lock()
+			correction.nudgeCancel = nil
+			correction.settledAt = s.clock.Now().Add(driftSettleDuration)
+			s.driftController.mu.Unlock()
+		}()
+
+		pl.client.StartRateNudge(RateNudge{BaseRate: baseRate, NudgedRate: nudgedRate})
+		defer pl.client.FinishRateNudge()
+
+		if _, err := pl.SendCmdGroup(
+			nudgeCtx,
+			extended.CmdGroup{Rate: typeutil.NewOptional(nudgedRate)},
+			repetition.Single(),
+		); err == nil {
+			_ = timeutil.SleepCtx(nudgeCtx, s.clock, nudgeDuration)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		s.restoreNudgedRate(ctx, pl, baseRate)
+	}()
+}
+
+// restoreNudgedRate sets the rate of the leader to the player (it could be changed during the nudge)
+func (s *Syncer) restoreNudgedRate(ctx context.Context, pl *player, baseRate float64) {
+	s.syncingMu.Lock()
+	defer s.syncingMu.Unlock()
+
+	commands := extended.CmdGroup{Rate: typeutil.NewOptional(baseRate)}
+	if leader := s.getLeader(); leader != nil && leader != pl {
+		if leaderStatus, ok := leader.client.state.GetLastStatus(); ok {
+			commands.Rate.Set(leaderStatus.Rate)
+			_, _ = s.sendPlayerCommands(ctx, leader, pl, commands, repetition.WithInterval(timings.CommandsRepeatInterval))
+			return
+		}
+	}
+	_, _ = pl.SendCmdGroup(ctx, commands, repetition.WithInterval(timings.CommandsRepeatInterval))
+}
@@ -0,0 +1,95 @@
+package syncer
+
+import (
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/util/rx"
+	typeutil "github.com/cardinalby/vlc-sync-play/pkg/util/type"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/instance"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/state"
+)
+
+type EventType string
+
+const (
+	// EventTypeUpdateAccepted is emitted when a player update made by a user is going to be synced
+	EventTypeUpdateAccepted EventType = "update-accepted"
+	// EventTypeUpdateSkipped is emitted when a player update is ignored or reverted
+	EventTypeUpdateSkipped EventType = "update-skipped"
+	// EventTypeSynced is emitted when players got synced to the change made by a user in one of them
+	// or to external commands
+	EventTypeSynced EventType = "synced"
+	// EventTypeInstanceLaunched is emitted when a player is launched or attached
+	EventTypeInstanceLaunched EventType = "instance-launched"
+	// EventTypeInstanceFinished is emitted when a player is closed or disconnected
+	EventTypeInstanceFinished EventType = "instance-finished"
+)
+
+// CommandsOrigin is the source of the commands players are synced to
+type CommandsOrigin string
+
+const (
+	// CommandsOriginPlayer means a user changed one of the players
+	CommandsOriginPlayer CommandsOrigin = "player"
+	// CommandsOriginExternal means commands of the app controls (HTTP API, ctl, TUI, MPRIS, tray)
+	CommandsOriginExternal CommandsOrigin = "external"
+	// CommandsOriginPeer means commands received from a remote peer
+	CommandsOriginPeer CommandsOrigin = "peer"
+)
+
+type Event struct {
+	Type EventType
+	Time time.Time
+	// PlayerID is instance.IDNone for EventTypeSynced caused by external commands
+	PlayerID uint
+	// Update is set for update events and for EventTypeSynced caused by an update
+	Update typeutil.Optional[state.Update]
+	// Commands are set for EventTypeSynced. They sync other players to the source player
+	Commands extended.CmdGroup
+	// Origin is set for EventTypeSynced
+	Origin CommandsOrigin
+	// Reason is set for EventTypeUpdateSkipped
+	Reason string
+}
+
+// SubscribeEvents subscribes to the syncer events. The callback is called while syncing and should not block
+func (s *Syncer) SubscribeEvents(callback func(event Event)) rx.Subscription {
+	return s.events.Subscribe(callback)
+}
+
+func (s *Syncer) emitEvent(event Event) {
+	event.Time = s.clock.Now()
+	s.events.Next(event)
+}
+
+func (s *Syncer) emitSyncedEvent(src *player, update *state.Update, commands extended.CmdGroup) {
+	event := Event{
+		Type:     EventTypeSynced,
+		PlayerID: src.GetID(),
+		Commands: commands,
+		Origin:   CommandsOriginPlayer,
+	}
+	if update != nil {
+		event.Update.Set(*update)
+	}
+	s.emitEvent(event)
+}
+
+func (s *Syncer) emitExternalSyncedEvent(commands extended.CmdGroup, origin CommandsOrigin) {
+	s.emitEvent(Event{
+		Type:     EventTypeSynced,
+		PlayerID: instance.IDNone,
+		Commands: commands,
+		Origin:   origin,
+	})
+}
+
+func (s *Syncer) emitUpdateEvent(eventType EventType, plUpdate *playerUpdate, reason string) {
+	s.emitEvent(Event{
+		Type:     eventType,
+		PlayerID: plUpdate.player.GetID(),
+		Update:   typeutil.NewOptional(plUpdate.update),
+		Reason:   reason,
+	})
+}
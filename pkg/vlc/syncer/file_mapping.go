@@ -0,0 +1,169 @@
+package syncer
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/filemap"
+	urlutil "github.com/cardinalby/vlc-sync-play/pkg/url"
+	mathutil "github.com/cardinalby/vlc-sync-play/pkg/util/math"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended/repetition"
+)
+
+// mappedRateEpsilon is a tolerance of comparing mapped rates with rates reported by VLC (stored as float32)
+const mappedRateEpsilon = 1e-4
+
+// openedFile is the file opened by a user and the slot of the player it was opened in
+type openedFile struct {
+	uri  string
+	slot int
+}
+
+func (s *Syncer) getFileMapper() filemap.Mapper {
+	instancesSettings := s.settings.GetInstancesSettings().GetValue()
+	slotRules := make([]filemap.SlotRule, len(instancesSettings))
+	for i, instanceSettings := range instancesSettings {
+		slotRules[i] = filemap.SlotRule{
+			Suffix:    instanceSettings.FileSuffix,
+			Transform: instanceSettings.TimeTransform,
+		}
+	}
+	return filemap.Mapper{
+		FileSets:  s.settings.GetFileSets().GetValue(),
+		SlotRules: slotRules,
+	}
+}
+
+// getFileMapping returns a file to open in the dstSlot instead of the fileURI opened in the srcSlot.
+// Returns false if the same file should be opened
+func (s *Syncer) getFileMapping(fileURI string, srcSlot, dstSlot int) (filemap.Mapping, bool) {
+	path, ok := urlutil.ToFilePath(fileURI)
+	if !ok || fileURI == "" {
+		return filemap.Mapping{}, false
+	}
+	return s.getFileMapper().Map(path, srcSlot, dstSlot)
+}
+
+func (s *Syncer) mapFileURI(fileURI string, srcSlot, dstSlot int) string {
+	if mapping, ok := s.getFileMapping(fileURI, srcSlot, dstSlot); ok {
+		return urlutil.FromFilePath(mapping.Path)
+	}
+	return fileURI
+}
+
+// getPlayersTransform returns the transform of the src playback time to the dst playback time.
+// Returns false if dst has neither the same nor the mapped file opened
+func (s *Syncer) getPlayersTransform(
+	src *player,
+	srcStatus *basic.StatusEx,
+	dst *player,
+	dstStatus *basic.StatusEx,
+) (filemap.PairTransform, bool) {
+	if mapping, ok := s.getFileMapping(srcStatus.FileURI, src.GetSlot(), dst.GetSlot()); ok {
+		return mapping.Transform, urlutil.EqualIgnoreSchema(dstStatus.FileURI, urlutil.FromFilePath(mapping.Path))
+	}
+	return filemap.PairTransform{}, urlutil.EqualIgnoreSchema(dstStatus.FileURI, srcStatus.FileURI)
+}
+
+// sendPlayerCommands sends commands made from the src player state to the dst player that can have
+// another file opened
+func (s *Syncer) sendPlayerCommands(
+	ctx context.Context,
+	src *player,
+	dst *player,
+	commands extended.CmdGroup,
+	rule repetition.Rule,
+) (*basic.StatusEx, error) {
+	commands, isMapped := s.mapCommands(src, dst, commands)
+	if isMapped && commands.OpenFile.HasValue && commands.Seek.HasValue {
+		// the mapped position can be calculated only after the length of the mapped file is known
+		seekCommands := extended.CmdGroup{Seek: commands.Seek}
+		commands.Seek.Reset()
+		if _, err := dst.SendCmdGroup(ctx, commands, rule); err != nil {
+			return nil, err
+		}
+		commands = seekCommands
+	}
+	return dst.SendCmdGroup(ctx, commands, rule)
+}
+
+// mapCommands maps commands made from the src player state to the file opened in the dst player
+func (s *Syncer) mapCommands(
+	src *player,
+	dst *player,
+	commands extended.CmdGroup,
+) (res extended.CmdGroup, isMapped bool) {
+	srcFileURI := commands.OpenFile.Value
+	if !commands.OpenFile.HasValue {
+		srcStatus, ok := src.client.state.GetLastStatus()
+		if !ok {
+			return commands, false
+		}
+		srcFileURI = srcStatus.FileURI
+	}
+	mapping, ok := s.getFileMapping(srcFileURI, src.GetSlot(), dst.GetSlot())
+	if !ok {
+		return commands, false
+	}
+	if commands.OpenFile.HasValue {
+		commands.OpenFile.Set(urlutil.FromFilePath(mapping.Path))
+	}
+	if commands.Seek.HasValue {
+		commands.Seek.Set(s.mapPositionGetter(commands.Seek.Value, src, dst, mapping))
+	}
+	if commands.Rate.HasValue {
+		commands.Rate.Set(mapping.Transform.ApplyRate(commands.Rate.Value))
+	}
+	return commands, true
+}
+
+// mapPositionGetter converts the position in the src file to the position in the mapped dst file.
+// Falls back to the src position if the mapped file is not opened in dst yet
+func (s *Syncer) mapPositionGetter(
+	getter extended.ExpectedPositionGetter,
+	src *player,
+	dst *player,
+	mapping filemap.Mapping,
+) extended.ExpectedPositionGetter {
+	srcStatus, ok := src.client.state.GetLastStatus()
+	if !ok || srcStatus.LengthSec == 0 {
+		return getter
+	}
+	srcLength := srcStatus.GetLength()
+	dstFileURI := urlutil.FromFilePath(mapping.Path)
+
+	return func(atMoment time.Time) float64 {
+		dstStatus, ok := dst.client.state.GetLastStatus()
+		if !ok || dstStatus.LengthSec == 0 || !urlutil.EqualIgnoreSchema(dstStatus.FileURI, dstFileURI) {
+			return getter(atMoment)
+		}
+		srcPbTime := time.Duration(getter(atMoment) * float64(srcLength))
+		dstPbTime := mapping.Transform.Apply(srcPbTime)
+		return mathutil.Clamp(float64(dstPbTime)/float64(dstStatus.GetLength()), 0, 1)
+	}
+}
+
+// mapStatus converts the status of the src player to the status dst player should have
+// to be in sync. It's used to compare the statuses of players having different files opened
+func (s *Syncer) mapStatus(srcStatus basic.StatusEx, src *player, dst *player) basic.StatusEx {
+	mapping, ok := s.getFileMapping(srcStatus.FileURI, src.GetSlot(), dst.GetSlot())
+	if !ok {
+		return srcStatus
+	}
+	dstStatus, hasDstStatus := dst.client.state.GetLastStatus()
+	srcStatus.FileURI = urlutil.FromFilePath(mapping.Path)
+	srcStatus.Rate = mapping.Transform.ApplyRate(srcStatus.Rate)
+	if hasDstStatus {
+		// avoid false differences caused by URI encoding and float rounding
+		if urlutil.EqualIgnoreSchema(dstStatus.FileURI, srcStatus.FileURI) {
+			srcStatus.FileURI = dstStatus.FileURI
+		}
+		if math.Abs(dstStatus.Rate-srcStatus.Rate) < mappedRateEpsilon {
+			srcStatus.Rate = dstStatus.Rate
+		}
+	}
+	return srcStatus
+}
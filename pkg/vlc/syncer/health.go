@@ -0,0 +1,81 @@
+package syncer
+
+import (
+	"time"
+
+	typeutil "github.com/cardinalby/vlc-sync-play/pkg/util/type"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+)
+
+// healthDegradedDuration is how long the syncer is considered degraded after a recoverable API error
+const healthDegradedDuration = 5 * time.Second
+
+type HealthState string
+
+const (
+	// HealthIdle means there are no players or no file is opened
+	HealthIdle HealthState = "idle"
+	// HealthInSync means the players play the file with offsets below the drift threshold
+	HealthInSync HealthState = "in sync"
+	// HealthCorrecting means the players are being synced or the followers drift from the leader
+	HealthCorrecting HealthState = "correcting"
+	// HealthDegraded means some of the players had recoverable API errors recently
+	HealthDegraded HealthState = "degraded"
+)
+
+// Health is a snapshot of the sync health
+type Health struct {
+	State HealthState
+	// MaxOffset is the max playback time difference between the players.
+	// Not set if less than 2 players have the file opened
+	MaxOffset typeutil.Optional[time.Duration]
+}
+
+// GetHealth returns the current sync health. Degraded state takes precedence over correcting
+func (s *Syncer) GetHealth() Health {
+	s.syncingMu.Lock()
+	leader := s.getLeader()
+	isSyncing := s.clock.Now().Before(s.state.acceptFollowerUpdatesAfter)
+	s.syncingMu.Unlock()
+
+	if leader == nil {
+		return Health{State: HealthIdle}
+	}
+	leaderStatus, ok := leader.client.state.GetLastStatus()
+	if !ok || leaderStatus.LengthSec == 0 || leaderStatus.State == basic.PlaybackStateStopped {
+		return Health{State: HealthIdle}
+	}
+
+	var health Health
+	var minOffset, maxOffset time.Duration
+	hasOffsets, hasRecentErrors := false, false
+	now := s.clock.Now()
+	s.players.Iterate(func(pl *player) bool {
+		if errTime, ok := pl.client.GetLastRecoverableErrTime(); ok && now.Sub(errTime) < healthDegradedDuration {
+			hasRecentErrors = true
+		}
+		if pl == leader {
+			return true
+		}
+		if offset, ok := s.getLeaderOffset(leader, pl, now); ok {
+			// the leader has 0 offset
+			minOffset = min(minOffset, offset)
+			maxOffset = max(maxOffset, offset)
+			hasOffsets = true
+		}
+		return true
+	})
+	if hasOffsets {
+		health.MaxOffset.Set(maxOffset - minOffset)
+	}
+
+	switch {
+	case hasRecentErrors:
+		health.State = HealthDegraded
+	case isSyncing || s.driftController.isCorrecting() || (hasOffsets && maxOffset-minOffset >= driftStartThreshold):
+		health.State = HealthCorrecting
+	default:
+		health.State = HealthInSync
+	}
+	return health
+}
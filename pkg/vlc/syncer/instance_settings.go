@@ -0,0 +1,109 @@
+package syncer
+
+import (
+	"context"
+
+	urlutil "github.com/cardinalby/vlc-sync-play/pkg/url"
+	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
+	typeutil "github.com/cardinalby/vlc-sync-play/pkg/util/type"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended/repetition"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/timings"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/tracks"
+)
+
+// applyInstanceSettings selects tracks configured for the player instance slot once the streams of the
+// opened file are known and sets the audio delay. Should be called every time a file is opened in the player.
+// Tracks and delay of the session being restored take precedence over the settings
+func (s *Syncer) applyInstanceSettings(ctx context.Context, pl *player, fileURI string) {
+	instanceSettings := GetInstanceSettings(s.settings.GetInstancesSettings().GetValue(), pl.GetSlot())
+	playerSession, isRestoring := s.getRestoringPlayerSession(pl.GetSlot())
+	if len(instanceSettings.AudioLanguages) == 0 &&
+		instanceSettings.Subtitles.IsEmpty() &&
+		instanceSettings.AudioDelay == 0 &&
+		!isRestoring {
+		return
+	}
+
+	status, ok := s.waitForStreams(ctx, pl, fileURI)
+	if !ok {
+		if ctx.Err() != nil {
+			return
+		}
+		s.logger.Info("P[%d]: no streams info for %s", pl.GetID(), fileURI)
+	}
+
+	commands := extended.CmdGroup{}
+	if stream, ok := tracks.SelectByLanguage(
+		status.GetStreams(basic.StreamTypeAudio),
+		instanceSettings.AudioLanguages,
+		pl.GetSlot(),
+	); ok {
+		s.logger.Info("P[%d]: selecting audio track %d (%s)", pl.GetID(), stream.ID, stream.Language)
+		commands.AudioTrack.Set(stream.ID)
+	}
+	setSubtitlesCommands(&commands, instanceSettings.Subtitles, status.GetStreams(basic.StreamTypeSubtitle))
+	if instanceSettings.AudioDelay != 0 {
+		commands.AudioDelay.Set(instanceSettings.AudioDelay)
+	}
+	if isRestoring {
+		applyPlayerSession(&commands, playerSession)
+	}
+
+	if !commands.HasAny() {
+		return
+	}
+	_, _ = pl.SendCmdGroup(ctx, commands, repetition.WithInterval(timings.CommandsRepeatInterval))
+}
+
+func setSubtitlesCommands(commands *extended.CmdGroup, subtitles Subtitles, streams []basic.Stream) {
+	switch {
+	case subtitles.Off:
+		commands.SubtitleTrack.Set(basic.SubtitleTrackOff)
+	case subtitles.FilePath != "":
+		commands.AddSubtitle.Set(subtitles.FilePath)
+	case len(subtitles.Languages) > 0:
+		if stream, ok := tracks.FindByLanguage(streams, subtitles.Languages); ok {
+			commands.SubtitleTrack.Set(stream.ID)
+		}
+	}
+}
+
+// onInstancesSettingsChanged applies changed audio delays to the running players to let users calibrate it
+// while listening
+func (s *Syncer) onInstancesSettingsChanged(ctx context.Context, instancesSettings []InstanceSettings) {
+	s.players.Iterate(func(pl *player) bool {
+		audioDelay := GetInstanceSettings(instancesSettings, pl.GetSlot()).AudioDelay
+		status, ok := pl.client.state.GetLastStatus()
+		if !ok || status.State == basic.PlaybackStateStopped || status.AudioDelay == audioDelay {
+			return true
+		}
+		s.logger.Info("P[%d]: setting audio delay %v", pl.GetID(), audioDelay)
+		go func() {
+			_, _ = pl.SendCmdGroup(
+				ctx,
+				extended.CmdGroup{AudioDelay: typeutil.NewOptional(audioDelay)},
+				repetition.Single(),
+			)
+		}()
+		return true
+	})
+}
+
+// waitForStreams waits until the polled status of the player contains streams of the opened file
+func (s *Syncer) waitForStreams(ctx context.Context, pl *player, fileURI string) (basic.StatusEx, bool) {
+	deadline := s.clock.Now().Add(timings.WaitForStreamsInfoDuration)
+	for {
+		status, ok := pl.client.state.GetLastStatus()
+		if ok && len(status.Streams) > 0 && urlutil.EqualIgnoreSchema(status.FileURI, fileURI) {
+			return status, true
+		}
+		if s.clock.Now().After(deadline) {
+			return basic.StatusEx{}, false
+		}
+		if err := timeutil.SleepCtx(ctx, s.clock, s.settings.GetPollingInterval().GetValue()); err != nil {
+			return basic.StatusEx{}, false
+		}
+	}
+}
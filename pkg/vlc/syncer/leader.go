@@ -0,0 +1,91 @@
+package syncer
+
+import (
+	"context"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended/repetition"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/timings"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/instance"
+)
+
+// getLeader returns the player other players are synced to: the pinned leader (in leader mode),
+// the source of the last sync or the player with the lowest ID
+func (s *Syncer) getLeader() *player {
+	if pinnedLeader := s.getPinnedLeader(); pinnedLeader != nil {
+		return pinnedLeader
+	}
+	var leader *player
+	s.players.Iterate(func(pl *player) bool {
+		if pl.GetID() == s.state.lastSyncedFromID {
+			leader = pl
+			return false
+		}
+		if leader == nil || pl.GetID() < leader.GetID() {
+			leader = pl
+		}
+		return true
+	})
+	return leader
+}
+
+// getPinnedLeader returns the player set as a leader in leader mode or nil if leader mode is off
+// or the leader is not running
+func (s *Syncer) getPinnedLeader() *player {
+	leaderID := s.settings.GetLeaderID().GetValue()
+	if leaderID == instance.IDNone {
+		return nil
+	}
+	return s.players.Get(leaderID)
+}
+
+func (s *Syncer) onLeaderChanged(leaderID uint) {
+	s.syncingMu.Lock()
+	defer s.syncingMu.Unlock()
+
+	if leaderID != instance.IDNone && s.players.Get(leaderID) != nil {
+		s.logger.Info("P[%d]: became a leader", leaderID)
+		s.state.lastSyncedFromID = leaderID
+	}
+}
+
+// checkIsLockedOut returns true if the update is made in a follower in leader mode.
+// Such updates are reverted to the leader state instead of being propagated
+func (s *Syncer) checkIsLockedOut(plUpdate *playerUpdate) (leader *player, isLockedOut bool) {
+	leader = s.getPinnedLeader()
+	if leader == nil || leader == plUpdate.player {
+		return nil, false
+	}
+	leaderStatus, ok := leader.client.state.GetLastStatus()
+	if !ok || (plUpdate.update.ChangedProps.HasFileURI() && leaderStatus.FileURI == "") {
+		// nothing to revert to, let the follower open the file in the leader
+		return nil, false
+	}
+	return leader, true
+}
+
+func (s *Syncer) revertFollowerUpdate(ctx context.Context, plUpdate *playerUpdate, leader *player) {
+	s.logger.Info(
+		"P[%d]: reverting follower update to the leader P[%d] state: %s",
+		plUpdate.player.GetID(), leader.GetID(), plUpdate.update.String(),
+	)
+	s.driftController.cancelNudges()
+	props := plUpdate.update.ChangedProps
+	if props.HasFileURI() {
+		// restore the position and the state of the leader in the reopened file
+		props.SetAll(true)
+	}
+	commands := leader.client.state.GetSyncCommands(props)
+	if !commands.HasAny() {
+		return
+	}
+	_, _ = s.sendPlayerCommands(
+		ctx, leader, plUpdate.player, commands, repetition.WithInterval(timings.CommandsRepeatInterval),
+	)
+	if commands.OpenFile.HasValue {
+		fileURI := s.mapFileURI(commands.OpenFile.Value, leader.GetSlot(), plUpdate.player.GetSlot())
+		go s.applyInstanceSettings(ctx, plUpdate.player, fileURI)
+	}
+
+	s.state.lastSyncedFromID = leader.GetID()
+	s.state.acceptFollowerUpdatesAfter = s.clock.Now().Add(s.followersSkipUpdatesDuration)
+}
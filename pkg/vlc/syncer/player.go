@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
-	typeutil "github.com/cardinalby/vlc-sync-play/pkg/util/rx"
+	"github.com/cardinalby/vlc-sync-play/pkg/util/rx"
+	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
+	typeutil "github.com/cardinalby/vlc-sync-play/pkg/util/type"
 	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
 	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
 	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended/repetition"
@@ -34,29 +37,55 @@ func (pu *playerUpdate) GetSyncCommands() extended.CmdGroup {
 }
 
 type playerSettings struct {
-	pollingInterval typeutil.Observable[time.Duration]
-	stdErrEvents    typeutil.Observable[instance.EventsToParse]
+	pollingInterval   rx.Observable[time.Duration]
+	positionEstimator rx.Observable[state.PositionEstimator]
+	stdErrEvents      rx.Observable[instance.EventsToParse]
 }
 
 type player struct {
 	instance *instance.Instance
 	client   *PollingClient
 	settings playerSettings
+	// slot is an index of the player among running players. Per-instance settings are bound to slots
+	slot         int
+	lastUpdateMu sync.RWMutex
+	lastUpdate   typeutil.Optional[state.Update]
+	tracksMu     sync.Mutex
+	// tracks are selected by the syncer in the opened file. VLC status doesn't contain selected tracks
+	tracks selectedTracks
+	clock  timeutil.Clock
+}
+
+type selectedTracks struct {
+	// fileURI is the file the tracks are selected in
+	fileURI       string
+	audioTrack    typeutil.Optional[int]
+	subtitleTrack typeutil.Optional[int]
+	subtitleFile  string
 }
 
 func newPlayer(
 	instance *instance.Instance,
+	slot int,
 	settings playerSettings,
+	clock timeutil.Clock,
+	recorder Recorder,
 	parentLogger logging.Logger,
 ) *player {
 	return &player{
 		instance: instance,
 		client: newClient(
+			instance.ID,
 			instance.Client,
 			settings.pollingInterval,
+			settings.positionEstimator,
+			clock,
+			recorder,
 			parentLogger.WithPrefix(fmt.Sprintf("P[%d]", instance.ID)),
 		),
 		settings: settings,
+		slot:     slot,
+		clock:    clock,
 	}
 }
 
@@ -94,7 +123,49 @@ func (pl *player) SendCmdGroup(
 	cmdGroup extended.CmdGroup,
 	rule repetition.Rule,
 ) (statusEx *basic.StatusEx, err error) {
-	return pl.client.SendCmdGroup(ctx, cmdGroup, rule)
+	statusEx, err = pl.client.SendCmdGroup(ctx, cmdGroup, rule)
+	if err == nil {
+		pl.onCommandsSent(cmdGroup)
+	}
+	return statusEx, err
+}
+
+func (pl *player) onCommandsSent(cmdGroup extended.CmdGroup) {
+	if !cmdGroup.AudioTrack.HasValue && !cmdGroup.SubtitleTrack.HasValue && !cmdGroup.AddSubtitle.HasValue {
+		return
+	}
+	status, ok := pl.client.state.GetLastStatus()
+	if !ok {
+		return
+	}
+	pl.tracksMu.Lock()
+	defer pl.tracksMu.Unlock()
+
+	if pl.tracks.fileURI != status.FileURI {
+		pl.tracks = selectedTracks{fileURI: status.FileURI}
+	}
+	if cmdGroup.AudioTrack.HasValue {
+		pl.tracks.audioTrack = cmdGroup.AudioTrack
+	}
+	if cmdGroup.SubtitleTrack.HasValue {
+		pl.tracks.subtitleTrack = cmdGroup.SubtitleTrack
+		pl.tracks.subtitleFile = ""
+	}
+	if cmdGroup.AddSubtitle.HasValue {
+		pl.tracks.subtitleFile = cmdGroup.AddSubtitle.Value
+		pl.tracks.subtitleTrack.Reset()
+	}
+}
+
+// getSelectedTracks returns tracks selected by the syncer in the file
+func (pl *player) getSelectedTracks(fileURI string) selectedTracks {
+	pl.tracksMu.Lock()
+	defer pl.tracksMu.Unlock()
+
+	if pl.tracks.fileURI != fileURI {
+		return selectedTracks{fileURI: fileURI}
+	}
+	return pl.tracks
 }
 
 func (pl *player) onUpdate(stateUpdate state.Update, notify func(playerUpdate)) {
@@ -102,20 +173,31 @@ func (pl *player) onUpdate(stateUpdate state.Update, notify func(playerUpdate))
 		// "stopped" state can be caused by player instance shutdown (reproduces mainly on Windows).
 		// If player is not shut down soon, send update as normal, skip update otherwise
 		// to avoid stopping all players.
-		timer := time.NewTimer(timings.WaitForShutdownAfterStopDuration)
+		timer := pl.clock.NewTimer(timings.WaitForShutdownAfterStopDuration)
 		defer timer.Stop()
 		select {
-		case <-timer.C:
+		case <-timer.C():
 		case <-pl.instance.Finished():
 			return
 		}
 	}
+	pl.lastUpdateMu.Lock()
+	pl.lastUpdate.Set(stateUpdate)
+	pl.lastUpdateMu.Unlock()
+
 	notify(playerUpdate{
 		player: pl,
 		update: stateUpdate,
 	})
 }
 
+// GetLastUpdate returns the last not natural update of the player
+func (pl *player) GetLastUpdate() typeutil.Optional[state.Update] {
+	pl.lastUpdateMu.RLock()
+	defer pl.lastUpdateMu.RUnlock()
+	return pl.lastUpdate
+}
+
 func (pl *player) IsRecoverableErr(err error) bool {
 	return pl.client.IsRecoverableErr(err)
 }
@@ -123,3 +205,7 @@ func (pl *player) IsRecoverableErr(err error) bool {
 func (pl *player) GetID() uint {
 	return pl.instance.ID
 }
+
+func (pl *player) GetSlot() int {
+	return pl.slot
+}
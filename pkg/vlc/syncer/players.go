@@ -10,19 +10,22 @@ import (
 )
 
 type players struct {
-	items        map[*player]struct{}
-	mu           sync.RWMutex
-	waitCtx      context.Context
-	waitErrGroup *errgroup.Group
-	onUpdate     func(playerUpdate)
-	onEvent      func(playerEvent)
-	onFinish     func(*player)
+	items map[*player]struct{}
+	// reservedSlots are slots of the players being launched
+	reservedSlots map[int]bool
+	mu            sync.RWMutex
+	waitCtx       context.Context
+	waitErrGroup  *errgroup.Group
+	onUpdate      func(playerUpdate)
+	onEvent       func(playerEvent)
+	onFinish      func(*player)
 }
 
 func newPlayers() *players {
 	return &players{
-		items: make(map[*player]struct{}),
-		mu:    sync.RWMutex{},
+		items:         make(map[*player]struct{}),
+		reservedSlots: make(map[int]bool),
+		mu:            sync.RWMutex{},
 	}
 }
 
@@ -35,12 +38,53 @@ func (pls *players) Len() int {
 func (pls *players) Add(item *player) {
 	pls.mu.Lock()
 	defer pls.mu.Unlock()
+	delete(pls.reservedSlots, item.slot)
 	pls.items[item] = struct{}{}
 	if pls.waitErrGroup != nil {
 		pls.startWaitingForPlayer(item)
 	}
 }
 
+// ReserveSlot returns the lowest slot index not occupied by the players and reserves it
+// for a player being launched
+func (pls *players) ReserveSlot() int {
+	pls.mu.Lock()
+	defer pls.mu.Unlock()
+
+	occupied := make(map[int]bool, len(pls.items)+len(pls.reservedSlots))
+	for pl := range pls.items {
+		occupied[pl.slot] = true
+	}
+	for slot := range pls.reservedSlots {
+		occupied[slot] = true
+	}
+	slot := 0
+	for occupied[slot] {
+		slot++
+	}
+	pls.reservedSlots[slot] = true
+	return slot
+}
+
+// ReleaseSlot releases the slot reserved for a player that failed to launch
+func (pls *players) ReleaseSlot(slot int) {
+	pls.mu.Lock()
+	defer pls.mu.Unlock()
+	delete(pls.reservedSlots, slot)
+}
+
+// Get returns the player with the given ID or nil
+func (pls *players) Get(id uint) *player {
+	pls.mu.RLock()
+	defer pls.mu.RUnlock()
+	for pl := range pls.items {
+		if pl.GetID() == id {
+			return pl
+		}
+	}
+	return nil
+}
+
 func (pls *players) Iterate(yield func(*player) (next bool)) {
 	pls.mu.RLock()
 	players := make([]*player, 0, len(pls.items))
@@ -2,11 +2,13 @@ package syncer
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
-	typeutil "github.com/cardinalby/vlc-sync-play/pkg/util/rx"
+	"github.com/cardinalby/vlc-sync-play/pkg/util/rx"
 	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
+	typeutil "github.com/cardinalby/vlc-sync-play/pkg/util/type"
 	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
 	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
 	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended/repetition"
@@ -15,26 +17,49 @@ import (
 )
 
 type PollingClient struct {
+	id              uint
 	client          *extended.Client
-	pollingInterval typeutil.Observable[time.Duration]
+	pollingInterval rx.Observable[time.Duration]
 	state           *state.State
+	clock           timeutil.Clock
+	recorder        Recorder
 	logger          logging.Logger
+	// lastRecoverableErrAt is UnixNano time of the last recoverable API error, 0 if there were none
+	lastRecoverableErrAt atomic.Int64
+	// hasStatusEvents is true while the player pushes status events, polling is a fallback then
+	hasStatusEvents atomic.Bool
 }
 
 func newClient(
+	id uint,
 	client *extended.Client,
-	pollingInterval typeutil.Observable[time.Duration],
+	pollingInterval rx.Observable[time.Duration],
+	positionEstimator rx.Observable[state.PositionEstimator],
+	clock timeutil.Clock,
+	recorder Recorder,
 	logger logging.Logger,
 ) *PollingClient {
-	return &PollingClient{
+	c := &PollingClient{
+		id:              id,
 		client:          client,
 		pollingInterval: pollingInterval,
-		state:           state.NewState(logger),
+		state:           state.NewState(positionEstimator, logger),
+		clock:           clock,
+		recorder:        recorder,
 		logger:          logger,
 	}
+	// the subscription lives as long as the client
+	client.SubscribeSentCommands(func(command extended.SentCommand) {
+		recorder.RecordCommand(id, command)
+	})
+	return c
 }
 
 func (c *PollingClient) StartPolling(ctx context.Context, onUpdate func(state.Update)) error {
+	events := make(chan basic.StatusEvent, 1)
+	if source, ok := c.client.GetStatusEventsSource(); ok {
+		go c.listenStatusEvents(ctx, source, events)
+	}
 	for {
 		newStatus, err := c.client.GetStatusEx(ctx, repetition.Single())
 		if err == nil {
@@ -44,14 +69,75 @@ func (c *PollingClient) StartPolling(ctx context.Context, onUpdate func(state.Up
 		} else if !c.IsRecoverableErr(err) {
 			// Not recoverable
 			return err
+		} else {
+			c.onRecoverableErr()
 		}
-		if err := timeutil.SleepCtx(ctx, c.pollingInterval.GetValue()); err != nil {
+		if err := c.waitForNextPoll(ctx, events); err != nil {
 			// Not recoverable
 			return err
 		}
 	}
 }
 
+// waitForNextPoll waits for the polling interval or a status event
+func (c *PollingClient) waitForNextPoll(ctx context.Context, events <-chan basic.StatusEvent) error {
+	interval := c.pollingInterval.GetValue()
+	if c.hasStatusEvents.Load() {
+		interval = max(interval, timings.StatusEventsPollingInterval)
+	}
+	timer := c.clock.NewTimer(interval)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C():
+	case event := <-events:
+		c.logger.Info("Status event: %s %s %f", event.State, event.FileURI, event.Position)
+	}
+	return nil
+}
+
+// listenStatusEvents receives status events from the source, reconnecting if the connection is lost.
+// Polling interval is restored while there is no connection
+func (c *PollingClient) listenStatusEvents(
+	ctx context.Context,
+	source basic.StatusEventsSource,
+	events chan<- basic.StatusEvent,
+) {
+	sourceEvents := make(chan basic.StatusEvent, 1)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-sourceEvents:
+				c.hasStatusEvents.Store(true)
+				select {
+				case events <- event:
+				default:
+				}
+			}
+		}
+	}()
+
+	isFirstErr := true
+	for {
+		err := source.ListenStatusEvents(ctx, sourceEvents)
+		c.hasStatusEvents.Store(false)
+		if ctx.Err() != nil {
+			return
+		}
+		if isFirstErr {
+			c.logger.Err("Status events are unavailable, polling: %s", err.Error())
+			isFirstErr = false
+		}
+		if timeutil.SleepCtx(ctx, c.clock, timings.StatusEventsReconnectInterval) != nil {
+			return
+		}
+	}
+}
+
 func (c *PollingClient) SendCmdGroup(
 	ctx context.Context,
 	group extended.CmdGroup,
@@ -59,17 +145,50 @@ func (c *PollingClient) SendCmdGroup(
 ) (statusEx *basic.StatusEx, err error) {
 	c.logger.Info("SendCmdGroup")
 	res, err := c.client.SendCmdGroup(ctx, group, rule)
+	if err != nil && c.IsRecoverableErr(err) {
+		c.onRecoverableErr()
+	}
 	if res != nil {
 		c.logger.Info("apply Cmd status: %s", res.String())
+		c.recorder.RecordCmdStatus(c.id, *res)
 		c.state.ApplyNewStatus(res)
 	}
 	return res, err
 }
 
+// StartRateNudge marks the rate changes made by the drift correction. See state.State.StartRateNudge
+func (c *PollingClient) StartRateNudge(nudge RateNudge) {
+	c.recorder.RecordRateNudge(c.id, typeutil.NewOptional(nudge))
+	c.state.StartRateNudge(nudge.BaseRate, nudge.NudgedRate)
+}
+
+// FinishRateNudge should be called after the status of the command restoring the base rate has been applied
+func (c *PollingClient) FinishRateNudge() {
+	c.recorder.RecordRateNudge(c.id, typeutil.Optional[RateNudge]{})
+	c.state.FinishRateNudge()
+}
+
+func (c *PollingClient) GetStatusRespTime() (time.Duration, bool) {
+	return c.client.GetStatusRespTime()
+}
+
 func (c *PollingClient) IsRecoverableErr(err error) bool {
 	return c.client.IsRecoverableErr(err)
 }
 
+// GetLastRecoverableErrTime returns the time of the last recoverable API error
+func (c *PollingClient) GetLastRecoverableErrTime() (time.Time, bool) {
+	nanos := c.lastRecoverableErrAt.Load()
+	if nanos == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, nanos), true
+}
+
+func (c *PollingClient) onRecoverableErr() {
+	c.lastRecoverableErrAt.Store(c.clock.Now().UnixNano())
+}
+
 func (c *PollingClient) onNewStatus(
 	ctx context.Context,
 	newStatus basic.StatusEx,
@@ -80,17 +199,20 @@ func (c *PollingClient) onNewStatus(
 	c.state.ApplyNewStatus(&newStatus)
 	if err != nil || !update.ChangedProps.HasAny() || update.IsNatural {
 		// ignore errors or no changes
+		c.recorder.RecordStatus(c.id, newStatus, nil)
 		return nil
 	}
+	c.recorder.RecordStatus(c.id, newStatus, &update)
 
 	c.logger.Info("Update: %s. \nOld: %s, \nNew: %s", &update, oldStateStr, c.state)
 	onUpdate(update)
 
 	if update.ChangedProps.HasFileURI() {
-		durationSinceFileOpened := time.Since(newStatus.Moment.Center())
+		durationSinceFileOpened := c.clock.Now().Sub(newStatus.Moment.Center())
 		// Wait for auto-seek after file opened
 		if err := timeutil.SleepCtx(
 			ctx,
+			c.clock,
 			timings.WaitForAutoSeekAfterFileOpenedDuration-durationSinceFileOpened,
 		); err != nil {
 			return err
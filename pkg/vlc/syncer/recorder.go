@@ -0,0 +1,68 @@
+package syncer
+
+import (
+	"time"
+
+	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
+	typeutil "github.com/cardinalby/vlc-sync-play/pkg/util/type"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/instance"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/state"
+)
+
+// Recorder records everything the sync decisions are based on, so that they can be replayed without players.
+// Methods are called concurrently by the players and should not block
+type Recorder interface {
+	// RecordStatus is called for each polled status. update is nil if the status is not an update made by a user
+	RecordStatus(playerID uint, status basic.StatusEx, update *state.Update)
+	// RecordCmdStatus is called for the status received in response to the commands
+	RecordCmdStatus(playerID uint, status basic.StatusEx)
+	RecordCommand(playerID uint, command extended.SentCommand)
+	// RecordRateNudge is called when the drift correction starts a rate nudge and when it finishes it (no value)
+	RecordRateNudge(playerID uint, nudge typeutil.Optional[RateNudge])
+	RecordDecision(playerID uint, decision UpdateDecision)
+}
+
+// RateNudge is a temporary rate change made by the drift correction
+type RateNudge struct {
+	BaseRate   float64
+	NudgedRate float64
+}
+
+// UpdateDecision is the syncer decision whether to accept a user update of a player or to skip it
+// as caused by the sync commands
+type UpdateDecision struct {
+	// LastSyncedFromID and AcceptFollowerUpdatesAfter are the syncer state the decision is based on
+	LastSyncedFromID           uint
+	AcceptFollowerUpdatesAfter time.Time
+	UpdateMoment               timeutil.Range
+	Accepted                   bool
+}
+
+// CanAcceptUpdate returns false for an update of a follower (not the last sync source) if it has been
+// received before acceptFollowerUpdatesAfter
+func CanAcceptUpdate(
+	playerID uint,
+	lastSyncedFromID uint,
+	acceptFollowerUpdatesAfter time.Time,
+	updateMoment timeutil.Range,
+) bool {
+	if playerID == lastSyncedFromID || lastSyncedFromID == instance.IDNone {
+		return true
+	}
+	return !updateMoment.Center().Before(acceptFollowerUpdatesAfter)
+}
+
+// SetRecorder sets the recorder of the session. Should be called before Start
+func (s *Syncer) SetRecorder(recorder Recorder) {
+	s.recorder = recorder
+}
+
+type nopRecorder struct{}
+
+func (nopRecorder) RecordStatus(uint, basic.StatusEx, *state.Update)   {}
+func (nopRecorder) RecordCmdStatus(uint, basic.StatusEx)               {}
+func (nopRecorder) RecordCommand(uint, extended.SentCommand)           {}
+func (nopRecorder) RecordRateNudge(uint, typeutil.Optional[RateNudge]) {}
+func (nopRecorder) RecordDecision(uint, UpdateDecision)                {}
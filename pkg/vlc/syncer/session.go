@@ -0,0 +1,203 @@
+package syncer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	urlutil "github.com/cardinalby/vlc-sync-play/pkg/url"
+	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
+	typeutil "github.com/cardinalby/vlc-sync-play/pkg/util/type"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/timings"
+)
+
+// Session is the opened file with the playback time and the tracks of the players. It can be restored later
+type Session struct {
+	FileURI string
+	// FileSlot is the slot of the player the file is opened in. Other slots have the mapped files opened
+	FileSlot int
+	// PbTime is the playback time of the file
+	PbTime  time.Duration
+	Players []PlayerSession
+}
+
+// PlayerSession is the state of the player occupying the slot
+type PlayerSession struct {
+	Slot int
+	// AudioTrack is set if the audio track was selected by the syncer (VLC doesn't report selected tracks)
+	AudioTrack typeutil.Optional[int]
+	// SubtitleTrack is set if the subtitles were selected by the syncer. basic.SubtitleTrackOff means off
+	SubtitleTrack typeutil.Optional[int]
+	// SubtitleFile is a path of the subtitles file added by the syncer
+	SubtitleFile string
+	AudioDelay   time.Duration
+}
+
+// GetPlayerSession returns the session of the player in the slot
+func (s Session) GetPlayerSession(slot int) (PlayerSession, bool) {
+	for _, playerSession := range s.Players {
+		if playerSession.Slot == slot {
+			return playerSession, true
+		}
+	}
+	return PlayerSession{}, false
+}
+
+// GetSession returns the current session. Returns false if no file is opened
+func (s *Syncer) GetSession() (Session, bool) {
+	s.syncingMu.Lock()
+	leader := s.getLeader()
+	file := s.state.openedFile.GetValue()
+	s.syncingMu.Unlock()
+
+	// the player with the file opened by a user is preferred to avoid mapping the file back
+	src := leader
+	s.players.Iterate(func(pl *player) bool {
+		if pl.GetSlot() == file.slot {
+			src = pl
+			return false
+		}
+		return true
+	})
+	if src == nil {
+		return Session{}, false
+	}
+	status, ok := src.client.state.GetLastStatus()
+	positionGetter := src.client.state.GetExpectedPosition()
+	if !ok || status.LengthSec == 0 || status.State == basic.PlaybackStateStopped || positionGetter == nil {
+		return Session{}, false
+	}
+
+	session := Session{
+		FileURI:  status.FileURI,
+		FileSlot: src.GetSlot(),
+		PbTime:   time.Duration(positionGetter(s.clock.Now()) * float64(status.GetLength())),
+	}
+	s.players.Iterate(func(pl *player) bool {
+		plStatus, ok := pl.client.state.GetLastStatus()
+		if !ok || plStatus.LengthSec == 0 {
+			return true
+		}
+		tracks := pl.getSelectedTracks(plStatus.FileURI)
+		session.Players = append(session.Players, PlayerSession{
+			Slot:          pl.GetSlot(),
+			AudioTrack:    tracks.audioTrack,
+			SubtitleTrack: tracks.subtitleTrack,
+			SubtitleFile:  tracks.subtitleFile,
+			AudioDelay:    plStatus.AudioDelay,
+		})
+		return true
+	})
+	return session, true
+}
+
+// RestoreSession opens the session file in all players (launching missing ones), selects the tracks
+// of each player slot and seeks all players to the session playback time
+func (s *Syncer) RestoreSession(ctx context.Context, session Session) error {
+	s.syncingMu.Lock()
+	defer s.syncingMu.Unlock()
+
+	if s.players.Len() == 0 {
+		return ErrNoPlayers
+	}
+	s.logger.Info("-- Restoring session %s at %v", session.FileURI, session.PbTime)
+	s.driftController.cancelNudges()
+	s.state.lastSyncedFromID = externalSourceID
+	s.state.restoringSession.Set(session)
+	s.state.openedFile.SetValue(openedFile{
+		uri:  session.FileURI,
+		slot: session.FileSlot,
+	})
+	s.onFileOpened(ctx, nil)
+
+	go s.seekRestoredSession(ctx, session)
+	return nil
+}
+
+// seekRestoredSession waits until the players open the session files and seeks them to the session time
+func (s *Syncer) seekRestoredSession(ctx context.Context, session Session) {
+	defer func() {
+		s.syncingMu.Lock()
+		s.state.restoringSession.Reset()
+		s.syncingMu.Unlock()
+	}()
+
+	wg := sync.WaitGroup{}
+	s.players.Iterate(func(pl *player) bool {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.waitForFile(ctx, pl, s.mapFileURI(session.FileURI, session.FileSlot, pl.GetSlot()))
+		}()
+		return true
+	})
+	wg.Wait()
+	if ctx.Err() != nil {
+		return
+	}
+
+	s.syncingMu.Lock()
+	defer s.syncingMu.Unlock()
+
+	src := s.getLeader()
+	if src == nil {
+		return
+	}
+	status, ok := src.client.state.GetLastStatus()
+	if !ok || status.LengthSec == 0 {
+		return
+	}
+	pbTime := session.PbTime
+	if src.GetSlot() != session.FileSlot {
+		if mapping, ok := s.getFileMapping(session.FileURI, session.FileSlot, src.GetSlot()); ok {
+			pbTime = mapping.Transform.Apply(pbTime)
+		}
+	}
+	s.seekTo(ctx, status, pbTime)
+}
+
+// waitForFile waits until the polled status of the player has the file opened
+func (s *Syncer) waitForFile(ctx context.Context, pl *player, fileURI string) bool {
+	deadline := s.clock.Now().Add(timings.WaitForStreamsInfoDuration)
+	for {
+		status, ok := pl.client.state.GetLastStatus()
+		if ok && status.LengthSec > 0 && urlutil.EqualIgnoreSchema(status.FileURI, fileURI) {
+			return true
+		}
+		if s.clock.Now().After(deadline) {
+			return false
+		}
+		if err := timeutil.SleepCtx(ctx, s.clock, s.settings.GetPollingInterval().GetValue()); err != nil {
+			return false
+		}
+	}
+}
+
+// getRestoringPlayerSession returns the session of the player slot if the session file is being restored
+func (s *Syncer) getRestoringPlayerSession(slot int) (PlayerSession, bool) {
+	s.syncingMu.Lock()
+	defer s.syncingMu.Unlock()
+
+	if !s.state.restoringSession.HasValue {
+		return PlayerSession{}, false
+	}
+	return s.state.restoringSession.Value.GetPlayerSession(slot)
+}
+
+// applyPlayerSession overrides commands selecting tracks and audio delay with the ones from the session
+func applyPlayerSession(commands *extended.CmdGroup, playerSession PlayerSession) {
+	if playerSession.AudioTrack.HasValue {
+		commands.AudioTrack = playerSession.AudioTrack
+	}
+	switch {
+	case playerSession.SubtitleFile != "":
+		commands.SubtitleTrack.Reset()
+		commands.AddSubtitle.Set(playerSession.SubtitleFile)
+	case playerSession.SubtitleTrack.HasValue:
+		commands.AddSubtitle.Reset()
+		commands.SubtitleTrack = playerSession.SubtitleTrack
+	}
+	commands.AudioDelay.Set(playerSession.AudioDelay)
+}
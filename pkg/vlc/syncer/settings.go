@@ -3,8 +3,10 @@ package syncer
 import (
 	"time"
 
+	"github.com/cardinalby/vlc-sync-play/pkg/filemap"
 	"github.com/cardinalby/vlc-sync-play/pkg/util/rx"
 	"github.com/cardinalby/vlc-sync-play/pkg/vlc/instance"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/state"
 )
 
 type Settings interface {
@@ -13,11 +15,63 @@ type Settings interface {
 	GetPollingInterval() rx.Observable[time.Duration]
 	GetClickPause() rx.Observable[bool]
 	GetReSeekSrc() rx.Observable[bool]
+	GetDriftCorrection() rx.Observable[bool]
+	GetDriftSeekThreshold() rx.Observable[time.Duration]
+	// GetPositionEstimator returns the method of estimating players positions and detecting seeks
+	GetPositionEstimator() rx.Observable[state.PositionEstimator]
+	// GetLeaderID returns ID of the player that is the only one allowed to control others.
+	// instance.IDNone disables leader mode
+	GetLeaderID() rx.Observable[uint]
+	// GetInstancesSettings returns settings for each instance slot
+	GetInstancesSettings() rx.Observable[[]InstanceSettings]
+	// GetFileSets returns sets of files to open in different instance slots instead of the same file
+	GetFileSets() rx.Observable[[]filemap.FileSet]
+	// GetAttachTargets returns players started by someone else to attach to at start
+	GetAttachTargets() []instance.AttachTarget
+}
+
+// InstanceSettings are applied to the player occupying the corresponding instance slot
+type InstanceSettings struct {
+	// AudioLanguages are preferred audio track languages (codes or English names) in the order of preference
+	AudioLanguages []string
+	Subtitles      Subtitles
+	// AudioDelay compensates audio output latency (e.g. of Bluetooth earphones). Negative values advance audio
+	AudioDelay time.Duration
+	// FileSuffix makes the instance open a sibling file with the suffix instead of the file opened in another
+	// instance (e.g. "Movie.rus.mkv" for "rus" suffix when "Movie.eng.mkv" is opened)
+	FileSuffix string
+	// TimeTransform aligns the playback time of the sibling file to other instances files
+	TimeTransform filemap.TimeTransform
+	// Player is the type of the player launched in the slot, empty means VLC
+	Player instance.PlayerType
+}
+
+// Subtitles is a choice of subtitles for an instance. Empty value keeps VLC default subtitles
+type Subtitles struct {
+	// Off disables subtitles
+	Off bool
+	// Languages are preferred subtitles languages in the order of preference
+	Languages []string
+	// FilePath is a path of external subtitles file (.srt, .ass) to load
+	FilePath string
+}
+
+func (s Subtitles) IsEmpty() bool {
+	return !s.Off && len(s.Languages) == 0 && s.FilePath == ""
+}
+
+// GetInstanceSettings returns settings for the slot or empty settings if not set
+func GetInstanceSettings(instancesSettings []InstanceSettings, slot int) InstanceSettings {
+	if slot < 0 || slot >= len(instancesSettings) {
+		return InstanceSettings{}
+	}
+	return instancesSettings[slot]
 }
 
 func getPlayerSettings(s Settings) playerSettings {
 	return playerSettings{
-		pollingInterval: s.GetPollingInterval(),
+		pollingInterval:   s.GetPollingInterval(),
+		positionEstimator: s.GetPositionEstimator(),
 		stdErrEvents: rx.Map(s.GetClickPause(), func(value bool) instance.EventsToParse {
 			if value {
 				return instance.EventsToParse{instance.StderrEventMouse1Click: true}
@@ -4,19 +4,22 @@ import (
 	"time"
 
 	"github.com/cardinalby/vlc-sync-play/pkg/util/rx"
+	typeutil "github.com/cardinalby/vlc-sync-play/pkg/util/type"
 	"github.com/cardinalby/vlc-sync-play/pkg/vlc/instance"
 )
 
 type State struct {
-	fileURI                    rx.Value[string]
+	openedFile                 rx.Value[openedFile]
 	lastSyncedAt               time.Time
 	acceptFollowerUpdatesAfter time.Time
 	lastSyncedFromID           uint
+	// restoringSession is set while the session opened by RestoreSession is being restored
+	restoringSession typeutil.Optional[Session]
 }
 
 func NewState() State {
 	return State{
-		fileURI:          rx.NewValue(""),
+		openedFile:       rx.NewValue(openedFile{}),
 		lastSyncedFromID: instance.IDNone,
 	}
 }
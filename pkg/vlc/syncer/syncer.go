@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/util/rx"
+	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
 	typeutil "github.com/cardinalby/vlc-sync-play/pkg/util/type"
 	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
 	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
@@ -23,14 +25,19 @@ type Syncer struct {
 	settings                     Settings
 	followersSkipUpdatesDuration time.Duration
 	state                        State
+	driftController              *driftController
+	events                       *rx.Subject[Event]
 	isStarted                    atomic.Bool
 	instanceLauncher             instance.Launcher
+	clock                        timeutil.Clock
+	recorder                     Recorder
 	logger                       logging.Logger
 }
 
 func NewSyncer(
 	settings Settings,
 	instanceLauncher instance.Launcher,
+	clock timeutil.Clock,
 	logger logging.Logger,
 ) *Syncer {
 	return &Syncer{
@@ -40,14 +47,21 @@ func NewSyncer(
 			settings.GetPollingInterval().GetValue(),
 		),
 		state:            NewState(),
+		driftController:  newDriftController(clock),
+		events:           rx.NewSubject[Event](),
 		instanceLauncher: instanceLauncher,
+		clock:            clock,
+		recorder:         nopRecorder{},
 		logger:           logger,
 	}
 }
 
 func (s *Syncer) Start(ctx context.Context, initFileURI string) error {
-	if err := s.launchInstances(ctx, initFileURI, 1); err != nil {
-		return err
+	s.attachInstances(ctx, openedFile{uri: initFileURI})
+	if s.players.Len() < s.settings.GetInstancesNumber().GetValue() {
+		if err := s.launchInstances(ctx, openedFile{uri: initFileURI}, 1); err != nil {
+			return err
+		}
 	}
 
 	ctx, cancel := context.WithCancel(ctx)
@@ -56,12 +70,20 @@ func (s *Syncer) Start(ctx context.Context, initFileURI string) error {
 		s.launchMissingInstances(ctx, value)
 	}).Unsubscribe()
 
+	defer s.settings.GetLeaderID().Subscribe(s.onLeaderChanged).Unsubscribe()
+
+	defer s.settings.GetInstancesSettings().Subscribe(func(value []InstanceSettings) {
+		s.onInstancesSettingsChanged(ctx, value)
+	}).Unsubscribe()
+
 	defer s.settings.GetPollingInterval().Subscribe(func(value time.Duration) {
 		s.syncingMu.Lock()
 		defer s.syncingMu.Unlock()
 		s.followersSkipUpdatesDuration = timings.GetFollowerUpdatesIgnoreDuration(value)
 	})
 
+	go s.startDriftCorrection(ctx)
+
 	return s.players.WaitAndPoll(
 		ctx,
 		func(update playerUpdate) {
@@ -81,14 +103,24 @@ func (s *Syncer) Start(ctx context.Context, initFileURI string) error {
 func (s *Syncer) onEvent(ctx context.Context, event playerEvent) error {
 	switch event.event {
 	case instance.StderrEventMouse1Click:
+		if leader := s.getPinnedLeader(); leader != nil && leader != event.player {
+			s.logger.Info("P[%d]: ignoring click in a follower", event.player.GetID())
+			return nil
+		}
 		commands := event.player.client.state.GetPauseOrResumeCommand()
-		s.sendAllPlayersCommands(ctx, commands)
+		s.sendAllPlayersCommands(ctx, event.player, commands)
+		s.emitSyncedEvent(event.player, nil, commands)
 	}
 	return nil
 }
 
 func (s *Syncer) onFinished(pl *player) {
 	s.logger.Info("P[%d]: finished", pl.GetID())
+	s.driftController.forget(pl)
+	s.emitEvent(Event{
+		Type:     EventTypeInstanceFinished,
+		PlayerID: pl.GetID(),
+	})
 	s.syncingMu.Lock()
 	defer s.syncingMu.Unlock()
 	if s.state.lastSyncedFromID == pl.GetID() {
@@ -98,6 +130,7 @@ func (s *Syncer) onFinished(pl *player) {
 
 func (s *Syncer) sendAllPlayersCommands(
 	ctx context.Context,
+	src *player,
 	commands extended.CmdGroup,
 ) {
 	noSeekCommands := commands
@@ -108,13 +141,17 @@ func (s *Syncer) sendAllPlayersCommands(
 		waitGr.Add(1)
 		go func() {
 			defer waitGr.Done()
-			_, _ = pl.SendCmdGroup(ctx, commands, repetition.WithInterval(timings.CommandsRepeatInterval))
+			_, _ = s.sendPlayerCommands(
+				ctx, src, pl, noSeekCommands, repetition.WithInterval(timings.CommandsRepeatInterval),
+			)
 		}()
 		return true
 	})
 	waitGr.Wait()
 
-	s.syncPlayersPosition(ctx, commands.Seek.Value, nil)
+	if commands.Seek.HasValue {
+		s.syncPlayersPosition(ctx, src, commands.Seek.Value, nil)
+	}
 }
 
 func (s *Syncer) onUpdate(ctx context.Context, plUpdate *playerUpdate) error {
@@ -122,16 +159,35 @@ func (s *Syncer) onUpdate(ctx context.Context, plUpdate *playerUpdate) error {
 	defer s.syncingMu.Unlock()
 
 	if canAccept, getReason := s.checkCanAcceptUpdate(plUpdate); !canAccept {
-		s.logger.Info(getReason())
+		reason := getReason()
+		s.logger.Info(reason)
+		s.emitUpdateEvent(EventTypeUpdateSkipped, plUpdate, reason)
 		return nil
 	}
 
-	s.state.fileURI.SetValue(plUpdate.update.Status.FileURI)
+	if leader, isLockedOut := s.checkIsLockedOut(plUpdate); isLockedOut {
+		s.emitUpdateEvent(
+			EventTypeUpdateSkipped,
+			plUpdate,
+			fmt.Sprintf("reverted to the leader P[%d] state", leader.GetID()),
+		)
+		s.revertFollowerUpdate(ctx, plUpdate, leader)
+		return nil
+	}
+	s.emitUpdateEvent(EventTypeUpdateAccepted, plUpdate, "")
+
+	s.state.openedFile.SetValue(openedFile{
+		uri:  plUpdate.update.Status.FileURI,
+		slot: plUpdate.player.GetSlot(),
+	})
 	s.state.lastSyncedFromID = plUpdate.player.GetID()
 
 	if plUpdate.update.ChangedProps.HasFileURI() &&
 		plUpdate.update.Status.State != basic.PlaybackStateStopped {
-		s.onFileOpened(ctx, plUpdate.player.GetID())
+		s.onFileOpened(ctx, plUpdate.player)
+		s.emitSyncedEvent(plUpdate.player, &plUpdate.update, extended.CmdGroup{
+			OpenFile: typeutil.NewOptional(plUpdate.update.Status.FileURI),
+		})
 		return nil
 	}
 
@@ -143,11 +199,19 @@ func (s *Syncer) onUpdate(ctx context.Context, plUpdate *playerUpdate) error {
 
 func (s *Syncer) checkCanAcceptUpdate(plUpdate *playerUpdate) (canAccept bool, getReason func() string) {
 	plID := plUpdate.player.GetID()
-	if plID == s.state.lastSyncedFromID || s.state.lastSyncedFromID == instance.IDNone {
-		return true, nil
-	}
-
-	if plUpdate.update.Status.Moment.Center().Before(s.state.acceptFollowerUpdatesAfter) {
+	canAccept = CanAcceptUpdate(
+		plID,
+		s.state.lastSyncedFromID,
+		s.state.acceptFollowerUpdatesAfter,
+		plUpdate.update.Status.Moment,
+	)
+	s.recorder.RecordDecision(plID, UpdateDecision{
+		LastSyncedFromID:           s.state.lastSyncedFromID,
+		AcceptFollowerUpdatesAfter: s.state.acceptFollowerUpdatesAfter,
+		UpdateMoment:               plUpdate.update.Status.Moment,
+		Accepted:                   canAccept,
+	})
+	if !canAccept {
 		return false, func() string {
 			return fmt.Sprintf("Skipping [%d] update from %v old sync iteration: pos %v",
 				plUpdate.player.GetID(),
@@ -165,11 +229,11 @@ func (s *Syncer) launchMissingInstances(ctx context.Context, targetInstancesNumb
 		return
 	}
 
-	if fileURI := s.state.fileURI.GetValue(); fileURI != "" {
-		_ = s.launchInstances(ctx, fileURI, missing)
+	if file := s.state.openedFile.GetValue(); file.uri != "" {
+		_ = s.launchInstances(ctx, file, missing)
 	} else {
-		defer s.state.fileURI.Subscribe(func(fileURI string) {
-			_ = s.launchInstances(ctx, fileURI, missing)
+		defer s.state.openedFile.Subscribe(func(file openedFile) {
+			_ = s.launchInstances(ctx, file, missing)
 		}).Unsubscribe()
 	}
 	return
@@ -177,41 +241,71 @@ func (s *Syncer) launchMissingInstances(ctx context.Context, targetInstancesNumb
 
 func (s *Syncer) launchInstances(
 	ctx context.Context,
-	fileURI string,
+	file openedFile,
 	missingInstancesNumber int,
 ) error {
-	options := instance.LaunchOptions{
-		// First instance will be launched with video
-		NoVideo: s.players.Len() > 0 && s.settings.GetNoVideo().GetValue(),
-		FileURI: typeutil.Optional[string]{
-			HasValue: fileURI != "",
-			Value:    fileURI,
-		},
-	}
+	// First instance will be launched with video
+	noVideo := s.players.Len() > 0 && s.settings.GetNoVideo().GetValue()
 	errGr := errgroup.Group{}
 
 	for i := 0; i < missingInstancesNumber; i++ {
 		errGr.Go(func() error {
-			s.logger.Info("Launching new instance")
-
-			newInstance, err := s.instanceLauncher.Launch(ctx, options)
-			if err != nil {
-				return fmt.Errorf("failed to create new instance: %w", err)
-			}
-			s.players.Add(newPlayer(
-				newInstance,
-				getPlayerSettings(s.settings),
-				s.logger,
-			))
-			return nil
+			return s.addPlayer(ctx, file, func(options instance.LaunchOptions) (*instance.Instance, error) {
+				s.logger.Info("Launching new instance")
+				options.NoVideo = noVideo
+				newInstance, err := s.instanceLauncher.Launch(ctx, options)
+				if err != nil {
+					return nil, fmt.Errorf("failed to create new instance: %w", err)
+				}
+				return newInstance, nil
+			})
 		})
 	}
 	return errGr.Wait()
 }
 
-func (s *Syncer) onFileOpened(ctx context.Context, srcPlayerID uint) {
+// addPlayer creates a player for the instance occupying the lowest free slot. The instance
+// opens the file mapped to the slot
+func (s *Syncer) addPlayer(
+	ctx context.Context,
+	file openedFile,
+	createInstance func(options instance.LaunchOptions) (*instance.Instance, error),
+) error {
+	slot := s.players.ReserveSlot()
+	fileURI := s.mapFileURI(file.uri, file.slot, slot)
+	newInstance, err := createInstance(instance.LaunchOptions{
+		FileURI: typeutil.Optional[string]{
+			HasValue: fileURI != "",
+			Value:    fileURI,
+		},
+		Player: GetInstanceSettings(s.settings.GetInstancesSettings().GetValue(), slot).Player,
+	})
+	if err != nil {
+		s.players.ReleaseSlot(slot)
+		return err
+	}
+	pl := newPlayer(
+		newInstance,
+		slot,
+		getPlayerSettings(s.settings),
+		s.clock,
+		s.recorder,
+		s.logger,
+	)
+	s.players.Add(pl)
+	s.emitEvent(Event{
+		Type:     EventTypeInstanceLaunched,
+		PlayerID: pl.GetID(),
+	})
+	if fileURI != "" {
+		go s.applyInstanceSettings(ctx, pl, fileURI)
+	}
+	return nil
+}
+
+func (s *Syncer) onFileOpened(ctx context.Context, srcPlayer *player) {
 	// The source player may auto-seek and will send the next update with other properties
-	s.state.acceptFollowerUpdatesAfter = time.Now().Add(max(
+	s.state.acceptFollowerUpdatesAfter = s.clock.Now().Add(max(
 		s.followersSkipUpdatesDuration,
 		timings.WaitForAutoSeekAfterFileOpenedDuration,
 	))
@@ -229,16 +323,19 @@ func (s *Syncer) onFileOpened(ctx context.Context, srcPlayerID uint) {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
+		file := s.state.openedFile.GetValue()
 		s.players.Iterate(func(pl *player) bool {
-			if pl.GetID() != srcPlayerID {
+			fileURI := s.mapFileURI(file.uri, file.slot, pl.GetSlot())
+			if pl != srcPlayer {
 				_, _ = pl.SendCmdGroup(
 					ctx,
 					extended.CmdGroup{
-						OpenFile: typeutil.NewOptional(s.state.fileURI.GetValue()),
+						OpenFile: typeutil.NewOptional(fileURI),
 					},
 					repetition.WithInterval(timings.CommandsRepeatInterval),
 				)
 			}
+			go s.applyInstanceSettings(ctx, pl, fileURI)
 			return true
 		})
 	}()
@@ -251,6 +348,7 @@ func (s *Syncer) syncPlayers(
 	srcUpdate *playerUpdate,
 ) {
 	s.logger.Info("-- Syncing caused by %d update: %s", srcUpdate.player.GetID(), srcUpdate.update.String())
+	s.driftController.cancelNudges()
 	commands := srcUpdate.GetSyncCommands()
 	s.syncOtherPlayersNoSeek(ctx, srcUpdate, commands)
 	if commands.Seek.HasValue && s.players.Len() > 1 {
@@ -258,9 +356,10 @@ func (s *Syncer) syncPlayers(
 		if !s.settings.GetReSeekSrc().GetValue() {
 			skipPlayer = srcUpdate.player
 		}
-		s.syncPlayersPosition(ctx, commands.Seek.Value, skipPlayer)
+		s.syncPlayersPosition(ctx, srcUpdate.player, commands.Seek.Value, skipPlayer)
 	}
-	s.state.acceptFollowerUpdatesAfter = time.Now().Add(s.followersSkipUpdatesDuration)
+	s.state.acceptFollowerUpdatesAfter = s.clock.Now().Add(s.followersSkipUpdatesDuration)
+	s.emitSyncedEvent(srcUpdate.player, &srcUpdate.update, commands)
 }
 
 func (s *Syncer) syncOtherPlayersNoSeek(
@@ -283,7 +382,8 @@ func (s *Syncer) syncOtherPlayersNoSeek(
 		}
 
 		// Check if additional props sync required
-		dstUpdate, err := pl.client.state.GetUpdate(&srcUpdate.update.Status)
+		mappedSrcStatus := s.mapStatus(srcUpdate.update.Status, srcUpdate.player, pl)
+		dstUpdate, err := pl.client.state.GetUpdate(&mappedSrcStatus)
 		dstUpdate.ChangedProps.SetPosition(false)
 
 		if err == nil && !srcUpdate.update.ChangedProps.Includes(dstUpdate.ChangedProps) {
@@ -296,7 +396,9 @@ func (s *Syncer) syncOtherPlayersNoSeek(
 		waitGr.Add(1)
 		go func() {
 			defer waitGr.Done()
-			_, _ = pl.SendCmdGroup(ctx, dstCommands, repetition.WithInterval(timings.CommandsRepeatInterval))
+			_, _ = s.sendPlayerCommands(
+				ctx, srcUpdate.player, pl, dstCommands, repetition.WithInterval(timings.CommandsRepeatInterval),
+			)
 		}()
 		return true
 	})
@@ -305,6 +407,7 @@ func (s *Syncer) syncOtherPlayersNoSeek(
 
 func (s *Syncer) syncPlayersPosition(
 	ctx context.Context,
+	src *player,
 	positionGetter extended.ExpectedPositionGetter,
 	skipPlayer *player,
 ) {
@@ -325,7 +428,7 @@ func (s *Syncer) syncPlayersPosition(
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				if _, err := pl.SendCmdGroup(ctx, commands, repetition.Single()); err != nil {
+				if _, err := s.sendPlayerCommands(ctx, src, pl, commands, repetition.Single()); err != nil {
 					s.logger.Err("Failed to sync position: %s", err.Error())
 					if pl.IsRecoverableErr(err) {
 						hasRecoverableErr.Store(true)
@@ -0,0 +1,402 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cardinalby/vlc-sync-play/pkg/filemap"
+	"github.com/cardinalby/vlc-sync-play/pkg/util/logging"
+	"github.com/cardinalby/vlc-sync-play/pkg/util/rx"
+	timeutil "github.com/cardinalby/vlc-sync-play/pkg/util/time"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic/httpjson/fake"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/extended"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/timings"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/instance"
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/state"
+	"github.com/stretchr/testify/require"
+)
+
+const testFileURI = "file:///movies/movie.mkv"
+
+// syncTolerance is the max playback time difference of synced players
+const syncTolerance = 200 * time.Millisecond
+
+// syncTimeout is the real time to wait for the sync
+const syncTimeout = 5 * time.Second
+
+// userActionsInterval is the min (virtual) time between user actions in tests
+const userActionsInterval = 500 * time.Millisecond
+
+// virtualClockStep is advanced each virtualClockTick of real time, that makes tests run many times faster
+// than with real players
+const (
+	virtualClockStep = 5 * time.Millisecond
+	virtualClockTick = 100 * time.Microsecond
+)
+
+type testSettings struct {
+	instancesNumber    rx.Value[int]
+	pollingInterval    rx.Value[time.Duration]
+	driftCorrection    rx.Value[bool]
+	driftSeekThreshold rx.Value[time.Duration]
+	leaderID           rx.Value[uint]
+	instancesSettings  rx.Value[[]InstanceSettings]
+	positionEstimator  rx.Value[state.PositionEstimator]
+}
+
+func newTestSettings() *testSettings {
+	return &testSettings{
+		instancesNumber:    rx.NewValue(2),
+		pollingInterval:    rx.NewValue(50 * time.Millisecond),
+		driftCorrection:    rx.NewValue(false),
+		driftSeekThreshold: rx.NewValue(time.Second),
+		leaderID:           rx.NewValue[uint](instance.IDNone),
+		instancesSettings:  rx.NewValue[[]InstanceSettings](nil),
+		positionEstimator:  rx.NewValue(state.PositionEstimatorRange),
+	}
+}
+
+func (s *testSettings) GetInstancesNumber() rx.Observable[int] { return s.instancesNumber }
+func (s *testSettings) GetNoVideo() rx.Observable[bool]        { return rx.NewValue(false) }
+func (s *testSettings) GetPollingInterval() rx.Observable[time.Duration] {
+	return s.pollingInterval
+}
+func (s *testSettings) GetClickPause() rx.Observable[bool]      { return rx.NewValue(false) }
+func (s *testSettings) GetReSeekSrc() rx.Observable[bool]       { return rx.NewValue(true) }
+func (s *testSettings) GetDriftCorrection() rx.Observable[bool] { return s.driftCorrection }
+func (s *testSettings) GetDriftSeekThreshold() rx.Observable[time.Duration] {
+	return s.driftSeekThreshold
+}
+func (s *testSettings) GetPositionEstimator() rx.Observable[state.PositionEstimator] {
+	return s.positionEstimator
+}
+func (s *testSettings) GetLeaderID() rx.Observable[uint] { return s.leaderID }
+func (s *testSettings) GetInstancesSettings() rx.Observable[[]InstanceSettings] {
+	return s.instancesSettings
+}
+func (s *testSettings) GetFileSets() rx.Observable[[]filemap.FileSet] {
+	return rx.NewValue[[]filemap.FileSet](nil)
+}
+func (s *testSettings) GetAttachTargets() []instance.AttachTarget { return nil }
+
+var testFakeOptions = fake.Options{
+	Latency:   2 * time.Millisecond,
+	Jitter:    3 * time.Millisecond,
+	SeekDelay: 30 * time.Millisecond,
+	OpenDelay: 50 * time.Millisecond,
+}
+
+// testEnv is the syncer with fake players driven by a virtual clock
+type testEnv struct {
+	syncer   *Syncer
+	launcher *fake.Launcher
+	clock    *timeutil.VirtualClock
+}
+
+// startTestSyncer starts the syncer with fake players playing testFileURI
+func startTestSyncer(
+	t *testing.T,
+	settings *testSettings,
+	fakeOptions fake.Options,
+) *testEnv {
+	ctx, cancel := context.WithCancel(context.Background())
+	clock := timeutil.NewVirtualClock(time.Now())
+	fakeOptions.Clock = clock
+	launcher := fake.NewLauncher(fakeOptions, logging.NewNopLogger())
+	s := NewSyncer(settings, launcher, clock, logging.NewNopLogger())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = s.Start(ctx, testFileURI)
+	}()
+	clockDone := make(chan struct{})
+	go func() {
+		defer close(clockDone)
+		runVirtualClock(ctx, clock)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+		<-clockDone
+	})
+	env := &testEnv{syncer: s, launcher: launcher, clock: clock}
+
+	require.Eventually(t, func() bool {
+		servers := launcher.GetServers()
+		if len(servers) != settings.instancesNumber.GetValue() {
+			return false
+		}
+		for _, server := range servers {
+			if status := server.GetPlayer().GetStatus(); status.FileURI != testFileURI ||
+				status.State != basic.PlaybackStatePlaying {
+				return false
+			}
+		}
+		return len(s.GetPlayers()) == len(servers)
+	}, syncTimeout, time.Millisecond)
+	// let the syncer skip auto-seek after the file opened
+	env.wait(timings.WaitForAutoSeekAfterFileOpenedDuration + 500*time.Millisecond)
+	return env
+}
+
+func runVirtualClock(ctx context.Context, clock *timeutil.VirtualClock) {
+	ticker := time.NewTicker(virtualClockTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			clock.Advance(virtualClockStep)
+		}
+	}
+}
+
+// wait waits for d of the virtual time
+func (e *testEnv) wait(d time.Duration) {
+	until := e.clock.Now().Add(d)
+	for e.clock.Now().Before(until) {
+		time.Sleep(virtualClockTick)
+	}
+}
+
+// getPlayers returns the fake players in the launch order
+func (e *testEnv) getPlayers() []*fake.Player {
+	var players []*fake.Player
+	for _, server := range e.launcher.GetServers() {
+		players = append(players, server.GetPlayer())
+	}
+	return players
+}
+
+// requireSynced waits until dst is synced to src and the sync iteration is over,
+// so that the next user action is not mixed with it
+func (e *testEnv) requireSynced(t *testing.T, src, dst *fake.Player, state basic.PlaybackState) {
+	isSynced := func() bool {
+		srcStatus := src.GetStatus()
+		dstStatus := dst.GetStatus()
+		diff := srcStatus.PbTime - dstStatus.PbTime
+		return srcStatus.State == state && dstStatus.State == state &&
+			srcStatus.Rate == dstStatus.Rate &&
+			diff < syncTolerance && diff > -syncTolerance
+	}
+	require.Eventually(t, isSynced, syncTimeout, time.Millisecond)
+	e.wait(userActionsInterval)
+	require.True(t, isSynced())
+}
+
+func TestSyncerSyncsUserActions(t *testing.T) {
+	t.Parallel()
+	for _, estimator := range []state.PositionEstimator{state.PositionEstimatorRange, state.PositionEstimatorKalman} {
+		estimator := estimator
+		t.Run(string(estimator), func(t *testing.T) {
+			t.Parallel()
+			settings := newTestSettings()
+			settings.positionEstimator.SetValue(estimator)
+			env := startTestSyncer(t, settings, testFakeOptions)
+			players := env.getPlayers()
+			src, dst := players[0], players[1]
+
+			src.Pause()
+			env.requireSynced(t, src, dst, basic.PlaybackStatePaused)
+
+			src.Seek(10 * time.Minute)
+			env.requireSynced(t, src, dst, basic.PlaybackStatePaused)
+			// the paused source is re-seeked to the middle of the reported position error range
+			require.InDelta(t, float64(10*time.Minute), float64(src.GetStatus().PbTime), float64(time.Second))
+
+			src.Resume()
+			env.requireSynced(t, src, dst, basic.PlaybackStatePlaying)
+
+			// the other player can control as well
+			dst.Seek(20 * time.Minute)
+			env.requireSynced(t, dst, src, basic.PlaybackStatePlaying)
+			require.Greater(t, src.GetStatus().PbTime, 20*time.Minute-syncTolerance)
+		})
+	}
+}
+
+func TestSyncerSyncsRate(t *testing.T) {
+	t.Parallel()
+	env := startTestSyncer(t, newTestSettings(), testFakeOptions)
+	players := env.getPlayers()
+	src, dst := players[0], players[1]
+
+	src.SetRate(1.5)
+	env.requireSynced(t, src, dst, basic.PlaybackStatePlaying)
+	require.Equal(t, 1.5, dst.GetStatus().Rate)
+}
+
+func TestSyncerSyncsWithLatency(t *testing.T) {
+	t.Parallel()
+	fakeOptions := testFakeOptions
+	fakeOptions.Latency = 20 * time.Millisecond
+	fakeOptions.Jitter = 20 * time.Millisecond
+	fakeOptions.SeekDelay = 100 * time.Millisecond
+	env := startTestSyncer(t, newTestSettings(), fakeOptions)
+	players := env.getPlayers()
+	src, dst := players[0], players[1]
+
+	src.Seek(30 * time.Minute)
+	env.requireSynced(t, src, dst, basic.PlaybackStatePlaying)
+}
+
+func TestSyncerLaunchesMissingInstances(t *testing.T) {
+	t.Parallel()
+	settings := newTestSettings()
+	env := startTestSyncer(t, settings, testFakeOptions)
+
+	launched := make(chan uint, 1)
+	defer env.syncer.SubscribeEvents(func(event Event) {
+		if event.Type == EventTypeInstanceLaunched {
+			launched <- event.PlayerID
+		}
+	}).Unsubscribe()
+
+	settings.instancesNumber.SetValue(3)
+	select {
+	case <-launched:
+	case <-time.After(syncTimeout):
+		require.FailNow(t, "instance was not launched")
+	}
+	players := env.getPlayers()
+	require.Len(t, players, 3)
+	env.requireSynced(t, players[0], players[2], basic.PlaybackStatePlaying)
+}
+
+func TestSyncerSkipsStopOfFinishedInstance(t *testing.T) {
+	t.Parallel()
+	env := startTestSyncer(t, newTestSettings(), testFakeOptions)
+	servers := env.launcher.GetServers()
+	src, dst := servers[0].GetPlayer(), servers[1].GetPlayer()
+
+	// the player reports "stopped" before its process exits
+	src.Stop()
+	env.wait(timings.WaitForShutdownAfterStopDuration / 2)
+	require.NoError(t, servers[0].Close())
+	env.wait(timings.WaitForShutdownAfterStopDuration)
+	require.Equal(t, basic.PlaybackStatePlaying, dst.GetStatus().State)
+}
+
+func TestSyncerSyncsStop(t *testing.T) {
+	t.Parallel()
+	env := startTestSyncer(t, newTestSettings(), testFakeOptions)
+	players := env.getPlayers()
+	src, dst := players[0], players[1]
+
+	src.Stop()
+	env.wait(timings.WaitForShutdownAfterStopDuration)
+	require.Eventually(t, func() bool {
+		return dst.GetStatus().State == basic.PlaybackStateStopped
+	}, syncTimeout, time.Millisecond)
+}
+
+// driftOffsetJump is a change of the follower offset between the checks that can be caused only by a seek
+const driftOffsetJump = 30 * time.Millisecond
+
+func TestSyncerCorrectsDrift(t *testing.T) {
+	t.Parallel()
+	settings := newTestSettings()
+	settings.driftCorrection.SetValue(true)
+	settings.driftSeekThreshold.SetValue(60 * time.Millisecond)
+	env := startTestSyncer(t, settings, testFakeOptions)
+	players := env.getPlayers()
+	leader, follower := players[0], players[1]
+
+	synced := make(chan Event, 10)
+	defer env.syncer.SubscribeEvents(func(event Event) {
+		if event.Type == EventTypeSynced {
+			synced <- event
+		}
+	}).Unsubscribe()
+
+	getOffset := func() time.Duration {
+		return follower.GetStatus().PbTime - leader.GetStatus().PbTime
+	}
+
+	// the offset below the seek threshold is corrected by nudging the rate
+	follower.SetDrift(0.005)
+	require.Eventually(t, func() bool {
+		return follower.GetStatus().Rate < 1
+	}, syncTimeout, time.Millisecond)
+	follower.SetDrift(0)
+	require.Eventually(t, func() bool {
+		return follower.GetStatus().Rate == 1
+	}, syncTimeout, time.Millisecond)
+	require.Less(t, getOffset().Abs(), driftStartThreshold)
+
+	// the offset is not corrected while the follower is settling after the nudge
+	follower.SetDrift(0.05)
+	settleCheckUntil := env.clock.Now().Add(driftSettleDuration * 3 / 4)
+	lastOffset := getOffset()
+	for env.clock.Now().Before(settleCheckUntil) {
+		require.Equal(t, 1.0, follower.GetStatus().Rate)
+		offset := getOffset()
+		require.Greater(t, offset, lastOffset-driftOffsetJump)
+		lastOffset = max(lastOffset, offset)
+		time.Sleep(virtualClockTick)
+	}
+
+	// the offset exceeding the seek threshold makes the follower seek instead of nudging the rate
+	lastOffset = getOffset()
+	isNudged := false
+	require.Eventually(t, func() bool {
+		isNudged = isNudged || follower.GetStatus().Rate != 1
+		offset := getOffset()
+		isSeeked := (offset - lastOffset).Abs() > driftOffsetJump
+		lastOffset = offset
+		return isSeeked
+	}, syncTimeout, time.Millisecond)
+	follower.SetDrift(0)
+	require.False(t, isNudged)
+
+	// drift correction doesn't sync players as user actions do
+	require.Empty(t, synced)
+}
+
+func TestSyncerEmitsExternalCommands(t *testing.T) {
+	t.Parallel()
+	env := startTestSyncer(t, newTestSettings(), testFakeOptions)
+	players := env.getPlayers()
+
+	synced := make(chan Event, 10)
+	defer env.syncer.SubscribeEvents(func(event Event) {
+		if event.Type == EventTypeSynced {
+			synced <- event
+		}
+	}).Unsubscribe()
+
+	require.NoError(t, env.syncer.SeekTo(context.Background(), 10*time.Minute))
+	env.requireSynced(t, players[0], players[1], basic.PlaybackStatePlaying)
+	event := <-synced
+	require.Equal(t, CommandsOriginExternal, event.Origin)
+	require.Equal(t, uint(instance.IDNone), event.PlayerID)
+	require.True(t, event.Commands.Seek.HasValue)
+
+	commands := extended.CmdGroup{}
+	commands.State.Set(basic.PlaybackStatePaused)
+	env.syncer.SendPeerCommands(context.Background(), commands)
+	env.requireSynced(t, players[0], players[1], basic.PlaybackStatePaused)
+	event = <-synced
+	require.Equal(t, CommandsOriginPeer, event.Origin)
+	require.Equal(t, basic.PlaybackStatePaused, event.Commands.State.Value)
+	require.Empty(t, synced)
+
+	const peerFileURI = "file:///movies/peer.mkv"
+	commands = extended.CmdGroup{}
+	commands.OpenFile.Set(peerFileURI)
+	env.syncer.SendPeerCommands(context.Background(), commands)
+	event = <-synced
+	require.Equal(t, CommandsOriginPeer, event.Origin)
+	require.Equal(t, peerFileURI, event.Commands.OpenFile.Value)
+	require.Eventually(t, func() bool {
+		for _, pl := range players {
+			if pl.GetStatus().FileURI != peerFileURI {
+				return false
+			}
+		}
+		return true
+	}, syncTimeout, time.Millisecond)
+}
@@ -0,0 +1,70 @@
+package tracks
+
+import "strings"
+
+// language contains codes and an English name of a language. VLC reports either a code from the file
+// or a name it translated the code to
+type language struct {
+	codes []string
+	name  string
+}
+
+var languages = []language{
+	{codes: []string{"en", "eng"}, name: "english"},
+	{codes: []string{"ru", "rus"}, name: "russian"},
+	{codes: []string{"uk", "ukr"}, name: "ukrainian"},
+	{codes: []string{"be", "bel"}, name: "belarusian"},
+	{codes: []string{"de", "deu", "ger"}, name: "german"},
+	{codes: []string{"fr", "fra", "fre"}, name: "french"},
+	{codes: []string{"es", "spa"}, name: "spanish"},
+	{codes: []string{"it", "ita"}, name: "italian"},
+	{codes: []string{"pt", "por"}, name: "portuguese"},
+	{codes: []string{"pl", "pol"}, name: "polish"},
+	{codes: []string{"cs", "ces", "cze"}, name: "czech"},
+	{codes: []string{"nl", "nld", "dut"}, name: "dutch"},
+	{codes: []string{"sv", "swe"}, name: "swedish"},
+	{codes: []string{"no", "nor"}, name: "norwegian"},
+	{codes: []string{"da", "dan"}, name: "danish"},
+	{codes: []string{"fi", "fin"}, name: "finnish"},
+	{codes: []string{"tr", "tur"}, name: "turkish"},
+	{codes: []string{"el", "ell", "gre"}, name: "greek"},
+	{codes: []string{"he", "heb"}, name: "hebrew"},
+	{codes: []string{"ar", "ara"}, name: "arabic"},
+	{codes: []string{"hi", "hin"}, name: "hindi"},
+	{codes: []string{"ja", "jpn"}, name: "japanese"},
+	{codes: []string{"ko", "kor"}, name: "korean"},
+	{codes: []string{"zh", "zho", "chi"}, name: "chinese"},
+}
+
+// IsSameLanguage checks if a language preference (a code or a name) matches a stream language
+func IsSameLanguage(preference string, streamLanguage string) bool {
+	preference = strings.ToLower(strings.TrimSpace(preference))
+	streamLanguage = strings.ToLower(strings.TrimSpace(streamLanguage))
+	if preference == "" || streamLanguage == "" {
+		return false
+	}
+	if preference == streamLanguage {
+		return true
+	}
+	prefLang, ok := findLanguage(preference)
+	if !ok {
+		return false
+	}
+	streamLang, ok := findLanguage(streamLanguage)
+	return ok && prefLang == streamLang
+}
+
+func findLanguage(codeOrName string) (*language, bool) {
+	for i := range languages {
+		lang := &languages[i]
+		if lang.name == codeOrName || strings.HasPrefix(codeOrName, lang.name+" ") {
+			return lang, true
+		}
+		for _, code := range lang.codes {
+			if code == codeOrName {
+				return lang, true
+			}
+		}
+	}
+	return nil, false
+}
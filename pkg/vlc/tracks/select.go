@@ -0,0 +1,35 @@
+package tracks
+
+import (
+	"github.com/cardinalby/vlc-sync-play/pkg/vlc/client/basic"
+)
+
+// SelectByLanguage returns the first stream matching the preferred languages (in the order of preference).
+// If none of them matches, a stream is chosen by the slot index so that different instance slots get
+// different streams.
+// Returns false if there are no streams or no preferences
+func SelectByLanguage(
+	streams []basic.Stream,
+	preferredLanguages []string,
+	slot int,
+) (basic.Stream, bool) {
+	if len(preferredLanguages) == 0 || len(streams) == 0 {
+		return basic.Stream{}, false
+	}
+	if stream, ok := FindByLanguage(streams, preferredLanguages); ok {
+		return stream, true
+	}
+	return streams[slot%len(streams)], true
+}
+
+// FindByLanguage returns the first stream matching the preferred languages (in the order of preference)
+func FindByLanguage(streams []basic.Stream, preferredLanguages []string) (basic.Stream, bool) {
+	for _, preference := range preferredLanguages {
+		for _, stream := range streams {
+			if IsSameLanguage(preference, stream.Language) {
+				return stream, true
+			}
+		}
+	}
+	return basic.Stream{}, false
+}